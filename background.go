@@ -0,0 +1,39 @@
+package xlsx
+
+import (
+	"errors"
+	"strings"
+)
+
+// sheetBackground is the image that is tiled behind the cells of a
+// sheet.
+type sheetBackground struct {
+	data   []byte
+	format string
+}
+
+// backgroundContentTypes maps the image formats that can be used as a
+// sheet background to their content types.
+var backgroundContentTypes = map[string]string{
+	"png":  "image/png",
+	"jpeg": "image/jpeg",
+	"jpg":  "image/jpeg",
+	"gif":  "image/gif",
+	"bmp":  "image/bmp",
+}
+
+// SetBackground sets the image that is tiled behind the cells of the
+// sheet.  format is the image's file extension: "png", "jpeg", "jpg",
+// "gif" or "bmp".  Passing a nil image removes the background.
+func (s *Sheet) SetBackground(img []byte, format string) error {
+	if img == nil {
+		s.background = nil
+		return nil
+	}
+	format = strings.ToLower(strings.TrimPrefix(format, "."))
+	if _, ok := backgroundContentTypes[format]; !ok {
+		return errors.New("unsupported background image format: " + format)
+	}
+	s.background = &sheetBackground{data: img, format: format}
+	return nil
+}
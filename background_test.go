@@ -0,0 +1,48 @@
+package xlsx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestSheetBackground(t *testing.T) {
+	c := qt.New(t)
+
+	img := []byte("\x89PNG\r\n\x1a\nnot really a png")
+
+	c.Run("UnsupportedFormat", func(c *qt.C) {
+		sheet := &Sheet{}
+		c.Assert(sheet.SetBackground(img, "tiff"), qt.ErrorMatches, "unsupported background image format: tiff")
+		c.Assert(sheet.background, qt.IsNil)
+	})
+
+	c.Run("StreamRoundTrip", func(c *qt.C) {
+		buffer := bytes.NewBuffer(nil)
+		fileBuilder := NewStreamFileBuilder(buffer)
+		c.Assert(fileBuilder.AddStreamStyle(StreamStyleDefaultString), qt.IsNil)
+		c.Assert(fileBuilder.AddSheetS("Sheet1", []StreamStyle{StreamStyleDefaultString}), qt.IsNil)
+		c.Assert(fileBuilder.AddSheetS("Sheet2", []StreamStyle{StreamStyleDefaultString}), qt.IsNil)
+		c.Assert(fileBuilder.SetSheetBackground(2, img, "png"), qt.ErrorMatches, "sheet index out of range")
+		c.Assert(fileBuilder.SetSheetBackground(1, img, ".PNG"), qt.IsNil)
+		streamFile, err := fileBuilder.Build()
+		c.Assert(err, qt.IsNil)
+		c.Assert(streamFile.WriteS([]StreamCell{NewStringStreamCell("a")}), qt.IsNil)
+		c.Assert(streamFile.NextSheet(), qt.IsNil)
+		c.Assert(streamFile.WriteS([]StreamCell{NewStringStreamCell("b")}), qt.IsNil)
+		c.Assert(streamFile.Close(), qt.IsNil)
+
+		parts := unzipParts(c, buffer.Bytes())
+		c.Assert(parts["xl/media/background2.png"], qt.Equals, string(img))
+		c.Assert(parts["xl/worksheets/_rels/sheet2.xml.rels"], qt.Contains, `Target="../media/background2.png"`)
+		c.Assert(parts["xl/worksheets/sheet2.xml"], qt.Contains, `<picture r:id="rId1"></picture>`)
+		c.Assert(strings.Contains(parts["xl/worksheets/sheet1.xml"], "<picture"), qt.Equals, false)
+		c.Assert(parts["[Content_Types].xml"], qt.Contains, `<Default Extension="png" ContentType="image/png">`)
+
+		file, err := OpenBinary(buffer.Bytes())
+		c.Assert(err, qt.IsNil)
+		c.Assert(file.Sheets[1].Cell(0, 0).Value, qt.Equals, "b")
+	})
+}
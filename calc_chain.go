@@ -0,0 +1,89 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+)
+
+const calcChainPath = "xl/calcChain.xml"
+
+// CalcChainCell is a cell holding a formula, as listed in the
+// calculation chain of a workbook.
+type CalcChainCell struct {
+	// Sheet is the name of the sheet the cell is on.
+	Sheet string
+	// Ref is the reference of the cell, such as "A8".
+	Ref string
+}
+
+// xlsxCalcChain directly maps the calcChain element from the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxCalcChain struct {
+	XMLName xml.Name         `xml:"http://schemas.openxmlformats.org/spreadsheetml/2006/main calcChain"`
+	C       []xlsxCalcChainC `xml:"c"`
+}
+
+// xlsxCalcChainC directly maps the c element of the calcChain.  I is
+// the id of the sheet of the cell, and when it is left out the cell is
+// on the sheet of the cell before it.
+type xlsxCalcChainC struct {
+	R string `xml:"r,attr"`
+	I string `xml:"i,attr,omitempty"`
+}
+
+// CalcChain returns the cells holding formulas in the order the
+// calculation chain of the workbook says Excel last calculated them
+// in, which puts each cell after the cells it depends on.  It returns
+// nil if the file had no calculation chain, as is the case for the
+// files this package writes.
+func (f *File) CalcChain() []CalcChainCell {
+	if f.calcChain == nil {
+		return nil
+	}
+	chain := make([]CalcChainCell, len(f.calcChain))
+	copy(chain, f.calcChain)
+	return chain
+}
+
+// readCalcChainFromZipFile is an internal helper function that reads
+// the calculation chain of the workbook, naming the sheet of each cell
+// from the sheets of the workbook.  Cells on sheets the workbook does
+// not have are left out, with a warning.
+func readCalcChainFromZipFile(parts map[string]*zip.File, sheets []xlsxSheet, file *File) ([]CalcChainCell, error) {
+	part, ok := parts[calcChainPath]
+	if !ok {
+		return nil, nil
+	}
+	rc, err := part.Open()
+	if err != nil {
+		return nil, err
+	}
+	calcChain := new(xlsxCalcChain)
+	err = xml.NewDecoder(rc).Decode(calcChain)
+	rc.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	sheetNames := make(map[string]string, len(sheets))
+	for _, sheet := range sheets {
+		sheetNames[sheet.SheetId] = sheet.Name
+	}
+	chain := make([]CalcChainCell, 0, len(calcChain.C))
+	sheetId := ""
+	for _, c := range calcChain.C {
+		if c.I != "" {
+			sheetId = c.I
+		}
+		name, ok := sheetNames[sheetId]
+		if !ok {
+			file.addWarning(fmt.Sprintf("the calculation chain refers to %s on sheet %q, which does not exist", c.R, sheetId))
+			continue
+		}
+		chain = append(chain, CalcChainCell{Sheet: name, Ref: c.R})
+	}
+	return chain, nil
+}
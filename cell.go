@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -68,12 +69,29 @@ type Cell struct {
 	cellType       CellType
 	DataValidation *xlsxDataValidation
 	Hyperlink      Hyperlink
+	Comment        *Comment
+	// ThreadedComments holds the conversation attached to the cell,
+	// starting with the comment that opened it, followed by the
+	// replies in the order they were made.
+	ThreadedComments []ThreadedComment
+	// RichText holds the formatted runs of the cell's string when it
+	// was read as rich text.  Value holds the same text, unformatted.
+	RichText []RichTextRun
+	// absent is set on cells that stand in for a position of the
+	// sheet that holds no cell at all, or only a number with no value.
+	absent bool
+	// extLst holds the content of the extension list of the cell as
+	// it was read, which is written back as it is.
+	extLst string
 }
 
 type Hyperlink struct {
 	DisplayString string
 	Link          string
 	Tooltip       string
+	// Location is the place within the workbook, such as
+	// "'Sheet 2'!A1", that the link goes to when it has no Link.
+	Location string
 }
 
 // CellInterface defines the public API of the Cell.
@@ -103,6 +121,18 @@ func (c *Cell) SetString(s string) {
 	c.Value = s
 	c.formula = ""
 	c.cellType = CellTypeString
+	c.absent = false
+}
+
+// IsEmpty reports whether there is no cell at all at the cell's
+// position, as opposed to a cell holding an empty string.  That is the
+// case for the positions a sheet that was read has no cell for, or a
+// cell without a value, and for cells created by Sheet.Cell until they
+// are given a value or a formula.  Empty cells are not written when
+// the File is saved, unless they have a style, hyperlink or data
+// validation.
+func (c *Cell) IsEmpty() bool {
+	return c.absent && c.Value == "" && c.formula == ""
 }
 
 // String returns the value of a Cell as a string.  If you'd like to
@@ -127,6 +157,20 @@ func (c *Cell) IsTime() bool {
 	return c.parsedNumFmt.isTimeFormat
 }
 
+// IsDate returns true if the cell holds a number that its number
+// format shows as a date or a time, which is how spreadsheets store
+// them.  GetTime returns the value of such a cell as a time.Time.
+// Cells holding text are not dates, whatever their number format.
+func (c *Cell) IsDate() bool {
+	if c.cellType != CellTypeNumeric {
+		return false
+	}
+	if _, err := strconv.ParseFloat(c.Value, 64); err != nil {
+		return false
+	}
+	return c.IsTime()
+}
+
 //GetTime returns the value of a Cell as a time.Time
 func (c *Cell) GetTime(date1904 bool) (t time.Time, err error) {
 	f, err := c.Float()
@@ -136,6 +180,24 @@ func (c *Cell) GetTime(date1904 bool) (t time.Time, err error) {
 	return TimeFromExcelTime(f, date1904), nil
 }
 
+// Time returns the value of a Cell as a time.Time, converting the
+// serial number it holds in the date system of the workbook of the
+// cell, which counts from 1904 rather than 1900 when File.Date1904 is
+// set.  Cells that belong to no File use the 1900 system.
+func (c *Cell) Time() (time.Time, error) {
+	return c.GetTime(c.isDate1904())
+}
+
+// isDate1904 reports whether the dates of the cell are in the 1904
+// date system.  That of the File the cell belongs to is used, or else
+// that of the file it was read from.
+func (c *Cell) isDate1904() bool {
+	if c.Row != nil && c.Row.Sheet != nil && c.Row.Sheet.File != nil {
+		return c.Row.Sheet.File.Date1904
+	}
+	return c.date1904
+}
+
 /*
 	The following are samples of format samples.
 
@@ -197,7 +259,7 @@ func (c *Cell) SetDateTime(t time.Time) {
 func (c *Cell) SetDateWithOptions(t time.Time, options DateTimeOptions) {
 	_, offset := t.In(options.Location).Zone()
 	t = time.Unix(t.Unix()+int64(offset), 0)
-	c.SetDateTimeWithFormat(TimeToExcelTime(t.In(timeLocationUTC), c.date1904), options.ExcelTimeFormat)
+	c.SetDateTimeWithFormat(TimeToExcelTime(t.In(timeLocationUTC), c.isDate1904()), options.ExcelTimeFormat)
 }
 
 func (c *Cell) SetDateTimeWithFormat(n float64, format string) {
@@ -207,27 +269,54 @@ func (c *Cell) SetDateTimeWithFormat(n float64, format string) {
 	c.cellType = CellTypeNumeric
 }
 
-// Float returns the value of cell as a number.
+// Float returns the value of cell as a number.  The stored value is
+// parsed rather than the formatted one, and an error is returned if it
+// is not a number or the cell holds a boolean.
 func (c *Cell) Float() (float64, error) {
+	if err := c.checkNumeric(); err != nil {
+		return math.NaN(), err
+	}
 	f, err := strconv.ParseFloat(c.Value, 64)
 	if err != nil {
-		return math.NaN(), err
+		return math.NaN(), fmt.Errorf("the value %q of the cell is not a number", c.Value)
 	}
 	return f, nil
 }
 
+// checkNumeric returns an error if the cell holds a boolean, whose
+// stored value of 0 or 1 is not to be read as a number.
+func (c *Cell) checkNumeric() error {
+	if c.cellType == CellTypeBool {
+		return fmt.Errorf("the cell holds the boolean %t, not a number", c.Value == "1")
+	}
+	return nil
+}
+
 // SetInt64 sets a cell's value to a 64-bit integer.
 func (c *Cell) SetInt64(n int64) {
 	c.SetValue(n)
 }
 
-// Int64 returns the value of cell as 64-bit integer.
+// Int64 returns the value of cell as 64-bit integer.  Whole numbers
+// written in scientific notation, such as "1.23E+10", are accepted.
+// Like Float, it returns an error for values that are not numbers and
+// for booleans, and also for numbers that are not whole or do not fit.
 func (c *Cell) Int64() (int64, error) {
-	f, err := strconv.ParseInt(c.Value, 10, 64)
-	if err != nil {
+	if err := c.checkNumeric(); err != nil {
 		return -1, err
 	}
-	return f, nil
+	n, err := strconv.ParseInt(c.Value, 10, 64)
+	if err == nil {
+		return n, nil
+	}
+	f, ferr := strconv.ParseFloat(c.Value, 64)
+	if ferr != nil {
+		return -1, fmt.Errorf("the value %q of the cell is not a number", c.Value)
+	}
+	if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return -1, fmt.Errorf("the value %q of the cell is not a 64-bit integer", c.Value)
+	}
+	return int64(f), nil
 }
 
 // GeneralNumeric returns the value of the cell as a string. It is formatted very closely to the the XLSX spec for how
@@ -266,6 +355,42 @@ func (c *Cell) SetHyperlink(hyperlink string, displayText string, tooltip string
 	}
 }
 
+// SetComment attaches a comment, also known as a note, written by
+// author to the cell.  The comment is written to the sheet's comments
+// part when the File is saved.
+func (c *Cell) SetComment(author, text string) {
+	c.Comment = &Comment{Author: author, Text: text}
+}
+
+// GetComment returns the text of the comment attached to the cell,
+// and whether it has one.  A thread of comments is flattened to plain
+// text, one line per comment in the form "Author: text".  Spreadsheet
+// applications write a note along with each thread, for the benefit of
+// those that do not understand threads, so the thread takes precedence.
+func (c *Cell) GetComment() (string, bool) {
+	if len(c.ThreadedComments) > 0 {
+		lines := make([]string, len(c.ThreadedComments))
+		for i, comment := range c.ThreadedComments {
+			lines[i] = comment.Text
+			if comment.Author != "" {
+				lines[i] = comment.Author + ": " + comment.Text
+			}
+		}
+		return strings.Join(lines, "\n"), true
+	}
+	if c.Comment != nil {
+		if len(c.Comment.RichText) > 0 {
+			var text strings.Builder
+			for _, run := range c.Comment.RichText {
+				text.WriteString(run.Text)
+			}
+			return text.String(), true
+		}
+		return c.Comment.Text, true
+	}
+	return "", false
+}
+
 // SetInt sets a cell's value to an integer.
 func (c *Cell) SetValue(n interface{}) {
 	switch t := n.(type) {
@@ -368,6 +493,15 @@ func (c *Cell) SetStyle(style *Style) {
 	c.style = style
 }
 
+// ClearStyle removes the style and number format of a cell, so that it
+// is written with the default style of the file, or with the style of
+// its column if the column has one, as cells that were never styled
+// are.
+func (c *Cell) ClearStyle() {
+	c.style = nil
+	c.NumFmt = ""
+}
+
 // GetNumberFormat returns the number format string for a cell.
 func (c *Cell) GetNumberFormat() string {
 	return c.NumFmt
@@ -1,7 +1,9 @@
 package xlsx
 
 import (
+	"bytes"
 	"math"
+	"strings"
 	"testing"
 	"time"
 
@@ -357,6 +359,79 @@ func (s *CellSuite) TestGetTime(c *C) {
 	c.Assert(err, NotNil)
 }
 
+// TestTime tests that Time converts dates in the date system of the
+// workbook the cell was read from.
+func (s *CellSuite) TestTime(c *C) {
+	f, err := OpenFile("./testdocs/date1904.xlsx")
+	c.Assert(err, IsNil)
+	c.Assert(f.Date1904, Equals, true)
+	sheet := f.Sheets[0]
+	date, err := sheet.Cell(0, 0).Time()
+	c.Assert(err, IsNil)
+	c.Assert(date, Equals, time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC))
+	date, err = sheet.Cell(1, 0).Time()
+	c.Assert(err, IsNil)
+	c.Assert(date, Equals, time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC))
+	formatted, err := sheet.Cell(1, 0).FormattedValue()
+	c.Assert(err, IsNil)
+	c.Assert(formatted, Equals, "2024-01-02 12:00")
+	_, err = sheet.Cell(2, 0).Time()
+	c.Assert(err, NotNil)
+
+	// Cells of a File follow its date system, however they were made.
+	f = NewFile()
+	f.Date1904 = true
+	sheet, err = f.AddSheet("Sheet1")
+	c.Assert(err, IsNil)
+	want := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	sheet.Cell(0, 0).SetDateTime(want)
+	c.Assert(sheet.Cell(0, 0).Value, Equals, "43831.5")
+	date, err = sheet.Cell(0, 0).Time()
+	c.Assert(err, IsNil)
+	c.Assert(date, Equals, want)
+	var buf bytes.Buffer
+	c.Assert(f.Write(&buf), IsNil)
+	f, err = OpenBinary(buf.Bytes())
+	c.Assert(err, IsNil)
+	c.Assert(f.Date1904, Equals, true)
+	date, err = f.Sheets[0].Cell(0, 0).Time()
+	c.Assert(err, IsNil)
+	c.Assert(date, Equals, want)
+
+	// Cells that belong to no File use the 1900 date system.
+	cell := Cell{}
+	cell.SetFloat(43831.5)
+	date, err = cell.Time()
+	c.Assert(err, IsNil)
+	c.Assert(date, Equals, time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC))
+}
+
+func (s *CellSuite) TestIsDate(c *C) {
+	f := NewFile()
+	sheet, err := f.AddSheet("Sheet1")
+	c.Assert(err, IsNil)
+	sheet.Cell(0, 0).SetFloatWithFormat(43891.5, "yyyy-mm-dd hh:mm")
+	sheet.Cell(0, 1).SetFloat(43891.5)
+	sheet.Cell(0, 2).SetString("43891.5")
+	sheet.Cell(0, 2).NumFmt = "yyyy-mm-dd"
+
+	var buf bytes.Buffer
+	c.Assert(f.Write(&buf), IsNil)
+	f, err = OpenBinary(buf.Bytes())
+	c.Assert(err, IsNil)
+	sheet = f.Sheets[0]
+
+	cell := sheet.Cell(0, 0)
+	c.Assert(cell.Type(), Equals, CellTypeNumeric)
+	c.Assert(cell.IsDate(), Equals, true)
+	date, err := cell.GetTime(f.Date1904)
+	c.Assert(err, IsNil)
+	c.Assert(date, Equals, time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC))
+	c.Assert(sheet.Cell(0, 1).IsDate(), Equals, false)
+	c.Assert(sheet.Cell(0, 2).IsDate(), Equals, false)
+	c.Assert(sheet.Cell(0, 3).IsDate(), Equals, false)
+}
+
 // FormattedValue returns an error for formatting errors
 func (l *CellSuite) TestFormattedValueErrorsOnBadFormat(c *C) {
 	cell := Cell{Value: "Fudge Cake", cellType: CellTypeNumeric}
@@ -412,34 +487,42 @@ func (l *CellSuite) TestFormattedValue(c *C) {
 	cell.NumFmt = "0"
 	fvc.Equals(cell, "37948")
 
-	cell.NumFmt = "#,##0" // For the time being we're not doing
-	// this comma formatting, so it'll fall back to the related
-	// non-comma form.
-	fvc.Equals(cell, "37948")
+	cell.NumFmt = "#,##0"
+	fvc.Equals(cell, "37,948")
 
 	cell.NumFmt = "#,##0.00;(#,##0.00)"
-	fvc.Equals(cell, "37947.75")
+	fvc.Equals(cell, "37,947.75")
 
 	cell.NumFmt = "0.00"
 	fvc.Equals(cell, "37947.75")
 
-	cell.NumFmt = "#,##0.00" // For the time being we're not doing
-	// this comma formatting, so it'll fall back to the related
-	// non-comma form.
-	fvc.Equals(cell, "37947.75")
+	cell.NumFmt = "#,##0.00"
+	fvc.Equals(cell, "37,947.75")
 
 	cell.NumFmt = "#,##0 ;(#,##0)"
-	fvc.Equals(cell, "37948")
+	fvc.Equals(cell, "37,948")
 	negativeCell.NumFmt = "#,##0 ;(#,##0)"
-	fvc.Equals(negativeCell, "(37948)")
+	fvc.Equals(negativeCell, "(37,948)")
 
 	cell.NumFmt = "#,##0 ;[red](#,##0)"
-	fvc.Equals(cell, "37948")
+	fvc.Equals(cell, "37,948")
 	negativeCell.NumFmt = "#,##0 ;[red](#,##0)"
-	fvc.Equals(negativeCell, "(37948)")
+	fvc.Equals(negativeCell, "(37,948)")
 
 	negativeCell.NumFmt = "#,##0.00;(#,##0.00)"
-	fvc.Equals(negativeCell, "(37947.75)")
+	fvc.Equals(negativeCell, "(37,947.75)")
+
+	// Trailing commas scale the number down by a thousand each.
+	millionCell := Cell{Value: "1234567", cellType: CellTypeNumeric}
+	millionCell.NumFmt = "#,##0,"
+	fvc.Equals(millionCell, "1,235")
+	millionCell.NumFmt = "0.0,,"
+	fvc.Equals(millionCell, "1.2")
+	millionCell.NumFmt = `#,##0.0,,"M"`
+	fvc.Equals(millionCell, "1.2M")
+	negativeMillionCell := Cell{Value: "-1234567", cellType: CellTypeNumeric}
+	negativeMillionCell.NumFmt = "#,##0,"
+	fvc.Equals(negativeMillionCell, "-1,235")
 
 	cell.NumFmt = "0%"
 	fvc.Equals(cell, "3794775%")
@@ -705,6 +788,70 @@ func (s *CellSuite) TestStringBool(c *C) {
 	c.Assert(cell.Bool(), Equals, true)
 }
 
+// TestScientificNotation tests reading numbers stored in scientific
+// notation, as some applications write them.
+func (s *CellSuite) TestScientificNotation(c *C) {
+	cell := Cell{Value: "1.23E+10", cellType: CellTypeNumeric}
+	f, err := cell.Float()
+	c.Assert(err, IsNil)
+	c.Assert(f, Equals, 1.23e10)
+	i, err := cell.Int()
+	c.Assert(err, IsNil)
+	c.Assert(i, Equals, 12300000000)
+	i64, err := cell.Int64()
+	c.Assert(err, IsNil)
+	c.Assert(i64, Equals, int64(12300000000))
+	formatted, err := cell.FormattedValue()
+	c.Assert(err, IsNil)
+	c.Assert(formatted, Equals, "12300000000")
+	cell.NumFmt = "#,##0.00"
+	formatted, err = cell.FormattedValue()
+	c.Assert(err, IsNil)
+	c.Assert(formatted, Equals, "12,300,000,000.00")
+
+	cell = Cell{Value: "-4.5e-3", cellType: CellTypeNumeric}
+	f, err = cell.Float()
+	c.Assert(err, IsNil)
+	c.Assert(f, Equals, -0.0045)
+	formatted, err = cell.FormattedValue()
+	c.Assert(err, IsNil)
+	c.Assert(formatted, Equals, "-0.0045")
+	// Only whole numbers are integers.
+	_, err = cell.Int64()
+	c.Assert(err, NotNil)
+}
+
+// TestNumericErrors tests that Float and Int64 say why a value cannot
+// be read as a number.
+func (s *CellSuite) TestNumericErrors(c *C) {
+	cell := Cell{}
+	cell.SetString("abc")
+	f, err := cell.Float()
+	c.Assert(err, ErrorMatches, `the value "abc" of the cell is not a number`)
+	c.Assert(math.IsNaN(f), Equals, true)
+	_, err = cell.Int64()
+	c.Assert(err, ErrorMatches, `the value "abc" of the cell is not a number`)
+
+	cell.SetBool(true)
+	_, err = cell.Float()
+	c.Assert(err, ErrorMatches, "the cell holds the boolean true, not a number")
+	_, err = cell.Int64()
+	c.Assert(err, ErrorMatches, "the cell holds the boolean true, not a number")
+
+	cell = Cell{Value: "1.5", cellType: CellTypeNumeric}
+	_, err = cell.Int64()
+	c.Assert(err, ErrorMatches, `the value "1.5" of the cell is not a 64-bit integer`)
+	cell.Value = "1e20"
+	_, err = cell.Int64()
+	c.Assert(err, ErrorMatches, `the value "1e20" of the cell is not a 64-bit integer`)
+
+	// The stored value is read, not the formatted one.
+	cell = Cell{Value: "1234.5", NumFmt: "#,##0", cellType: CellTypeNumeric}
+	f, err = cell.Float()
+	c.Assert(err, IsNil)
+	c.Assert(f, Equals, 1234.5)
+}
+
 // TestSetValue tests whether SetValue handle properly for different type values.
 func (s *CellSuite) TestSetValue(c *C) {
 	cell := Cell{}
@@ -843,3 +990,36 @@ func (s *CellSuite) TestFallbackTo(c *C) {
 		c.Assert(testCase.cellType.fallbackTo(testCase.cellData, testCase.fallback), Equals, testCase.expectedReturn)
 	}
 }
+
+// Test that a cell holding an empty string can be told apart from a
+// position with no cell at all, both before and after a round trip.
+func (s *CellSuite) TestIsEmpty(c *C) {
+	f := NewFile()
+	sheet, err := f.AddSheet("Sheet1")
+	c.Assert(err, IsNil)
+	sheet.Cell(0, 0).SetString("")
+	sheet.Cell(0, 2).SetString("value")
+	c.Assert(sheet.Cell(0, 0).IsEmpty(), Equals, false)
+	c.Assert(sheet.Cell(0, 1).IsEmpty(), Equals, true)
+	c.Assert(sheet.Cell(0, 2).IsEmpty(), Equals, false)
+
+	parts, err := f.MarshallParts()
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(parts["xl/worksheets/sheet1.xml"], `r="A1"`), Equals, true)
+	c.Assert(strings.Contains(parts["xl/worksheets/sheet1.xml"], `r="B1"`), Equals, false)
+
+	var buf bytes.Buffer
+	c.Assert(f.Write(&buf), IsNil)
+	f, err = OpenBinary(buf.Bytes())
+	c.Assert(err, IsNil)
+	sheet = f.Sheets[0]
+	c.Assert(sheet.Cell(0, 0).IsEmpty(), Equals, false)
+	c.Assert(sheet.Cell(0, 0).Value, Equals, "")
+	c.Assert(sheet.Cell(0, 1).IsEmpty(), Equals, true)
+	c.Assert(sheet.Cell(0, 1).Value, Equals, "")
+	c.Assert(sheet.Cell(0, 2).IsEmpty(), Equals, false)
+	c.Assert(sheet.Cell(3, 3).IsEmpty(), Equals, true)
+
+	sheet.Cell(0, 1).SetString("")
+	c.Assert(sheet.Cell(0, 1).IsEmpty(), Equals, false)
+}
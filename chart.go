@@ -0,0 +1,209 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+const relationshipsNameSpace = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+
+// Chart describes a chart drawn on a Sheet, as it was read from the
+// file.  Only the definition of the chart is exposed, its appearance
+// is not.
+type Chart struct {
+	// Type is the kind of chart, e.g. "bar", "line", "pie" or
+	// "scatter".  When several kinds are combined in one chart,
+	// Type is the first of them.
+	Type   string
+	Title  string
+	Series []ChartSeries
+}
+
+// ChartSeries is a single series of data plotted in a Chart.  The
+// references are formulas such as "Sheet1!$B$2:$B$5", and the values
+// are those Excel cached the last time it drew the chart.
+type ChartSeries struct {
+	Name          string
+	NameRef       string
+	CategoriesRef string
+	Categories    []string
+	ValuesRef     string
+	Values        []string
+}
+
+// Charts returns the charts drawn on the Sheet.
+func (s *Sheet) Charts() []Chart {
+	return s.charts
+}
+
+// cachedValues returns the points of a strCache or numCache, in index
+// order.  There are as many values as the cache says it has points,
+// which is no more than a column of a sheet can hold; without a count
+// there are as many as it lists.  Points with indexes beyond them are
+// left out.
+func cachedValues(cache *xlsxChartDataCache) []string {
+	if cache == nil {
+		return nil
+	}
+	size := len(cache.Pt)
+	if cache.PtCount != nil {
+		size = cache.PtCount.Val
+	}
+	if size < 0 {
+		size = 0
+	} else if size > Excel2006MaxRowCount {
+		size = Excel2006MaxRowCount
+	}
+	values := make([]string, size)
+	for _, pt := range cache.Pt {
+		if pt.Idx >= 0 && pt.Idx < size {
+			values[pt.Idx] = pt.V
+		}
+	}
+	return values
+}
+
+// readDataRef returns the formula and cached values of a cat, val,
+// xVal or yVal element.
+func readDataRef(ref *xlsxChartDataRef) (string, []string) {
+	switch {
+	case ref == nil:
+		return "", nil
+	case ref.NumRef != nil:
+		return ref.NumRef.F, cachedValues(ref.NumRef.NumCache)
+	case ref.StrRef != nil:
+		return ref.StrRef.F, cachedValues(ref.StrRef.StrCache)
+	}
+	return "", nil
+}
+
+// readTx returns the text and formula of a tx element.
+func readTx(tx *xlsxChartTx) (string, string) {
+	switch {
+	case tx == nil:
+		return "", ""
+	case tx.StrRef != nil:
+		return strings.Join(cachedValues(tx.StrRef.StrCache), ""), tx.StrRef.F
+	case tx.Rich != nil:
+		var lines []string
+		for _, p := range tx.Rich.P {
+			var b strings.Builder
+			for _, r := range p.R {
+				b.WriteString(r.T)
+			}
+			lines = append(lines, b.String())
+		}
+		return strings.Join(lines, "\n"), ""
+	}
+	return tx.V, ""
+}
+
+func makeChart(chartSpace *xlsxChartSpace) Chart {
+	chart := Chart{}
+	if chartSpace.Chart.Title != nil {
+		chart.Title, _ = readTx(&chartSpace.Chart.Title.Tx)
+	}
+	for _, group := range chartSpace.Chart.PlotArea.Groups {
+		if !strings.HasSuffix(group.XMLName.Local, "Chart") {
+			// Axes, layout and shape properties.
+			continue
+		}
+		if chart.Type == "" {
+			chart.Type = strings.TrimSuffix(group.XMLName.Local, "Chart")
+		}
+		for _, ser := range group.Ser {
+			series := ChartSeries{}
+			series.Name, series.NameRef = readTx(ser.Tx)
+			cat, val := ser.Cat, ser.Val
+			if cat == nil && val == nil {
+				cat, val = ser.XVal, ser.YVal
+			}
+			series.CategoriesRef, series.Categories = readDataRef(cat)
+			series.ValuesRef, series.Values = readDataRef(val)
+			chart.Series = append(chart.Series, series)
+		}
+	}
+	return chart
+}
+
+// readDrawingChartIds returns the relationship ids of the charts
+// placed in a drawing part, in the order they appear.
+func readDrawingChartIds(f *zip.File) ([]string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	var ids []string
+	decoder := xml.NewDecoder(rc)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return ids, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local == "chart" {
+			for _, attr := range start.Attr {
+				if attr.Name.Space == relationshipsNameSpace && attr.Name.Local == "id" {
+					ids = append(ids, attr.Value)
+				}
+			}
+		}
+	}
+}
+
+// readChartsFromZipFile is an internal helper function that follows
+// the drawing relationships of a worksheet to the chart parts placed
+// on it, and reads their definitions.
+func readChartsFromZipFile(sheet *Sheet, worksheetRels *xlsxWorksheetRels, parts map[string]*zip.File) error {
+	for _, rel := range worksheetRels.Relationships {
+		if rel.Type != RelationshipTypeDrawing {
+			continue
+		}
+		drawingName := resolveWorksheetRelTarget(rel.Target)
+		drawing, ok := parts[drawingName]
+		if !ok {
+			continue
+		}
+		drawingRelsFile, ok := parts[relsPathForPart(drawingName)]
+		if !ok {
+			continue
+		}
+		drawingRels, err := readPartRelationsFromZipFile(drawingRelsFile)
+		if err != nil {
+			return err
+		}
+		ids, err := readDrawingChartIds(drawing)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			for _, drawingRel := range drawingRels.Relationships {
+				if drawingRel.Id != id || drawingRel.Type != RelationshipTypeChart {
+					continue
+				}
+				f, ok := parts[resolveRelTarget(drawingName, drawingRel.Target)]
+				if !ok {
+					break
+				}
+				rc, err := f.Open()
+				if err != nil {
+					return err
+				}
+				chartSpace := new(xlsxChartSpace)
+				err = xml.NewDecoder(rc).Decode(chartSpace)
+				rc.Close()
+				if err != nil {
+					return err
+				}
+				sheet.charts = append(sheet.charts, makeChart(chartSpace))
+				break
+			}
+		}
+	}
+	return nil
+}
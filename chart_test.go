@@ -0,0 +1,86 @@
+package xlsx
+
+import (
+	"encoding/xml"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+const testDrawingXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<xdr:wsDr xmlns:xdr="http://schemas.openxmlformats.org/drawingml/2006/spreadsheetDrawing" xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"><xdr:twoCellAnchor><xdr:from><xdr:col>3</xdr:col><xdr:colOff>0</xdr:colOff><xdr:row>1</xdr:row><xdr:rowOff>0</xdr:rowOff></xdr:from><xdr:to><xdr:col>10</xdr:col><xdr:colOff>0</xdr:colOff><xdr:row>15</xdr:row><xdr:rowOff>0</xdr:rowOff></xdr:to><xdr:graphicFrame macro=""><xdr:nvGraphicFramePr><xdr:cNvPr id="2" name="Chart 1"/><xdr:cNvGraphicFramePr/></xdr:nvGraphicFramePr><xdr:xfrm><a:off x="0" y="0"/><a:ext cx="0" cy="0"/></xdr:xfrm><a:graphic><a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/chart"><c:chart xmlns:c="http://schemas.openxmlformats.org/drawingml/2006/chart" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" r:id="rId1"/></a:graphicData></a:graphic></xdr:graphicFrame><xdr:clientData/></xdr:twoCellAnchor></xdr:wsDr>`
+
+const testBarChartXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<c:chartSpace xmlns:c="http://schemas.openxmlformats.org/drawingml/2006/chart" xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><c:chart><c:title><c:tx><c:rich><a:bodyPr/><a:p><a:r><a:t>Sales </a:t></a:r><a:r><a:t>by region</a:t></a:r></a:p></c:rich></c:tx></c:title><c:plotArea><c:layout/><c:barChart><c:barDir val="col"/><c:grouping val="clustered"/><c:ser><c:idx val="0"/><c:order val="0"/><c:tx><c:strRef><c:f>Sheet1!$B$1</c:f><c:strCache><c:ptCount val="1"/><c:pt idx="0"><c:v>2019</c:v></c:pt></c:strCache></c:strRef></c:tx><c:cat><c:strRef><c:f>Sheet1!$A$2:$A$3</c:f><c:strCache><c:ptCount val="2"/><c:pt idx="0"><c:v>North</c:v></c:pt><c:pt idx="1"><c:v>South</c:v></c:pt></c:strCache></c:strRef></c:cat><c:val><c:numRef><c:f>Sheet1!$B$2:$B$3</c:f><c:numCache><c:formatCode>General</c:formatCode><c:ptCount val="2"/><c:pt idx="0"><c:v>10</c:v></c:pt><c:pt idx="1"><c:v>20</c:v></c:pt></c:numCache></c:numRef></c:val></c:ser><c:ser><c:idx val="1"/><c:order val="1"/><c:tx><c:strRef><c:f>Sheet1!$C$1</c:f></c:strRef></c:tx><c:cat><c:strRef><c:f>Sheet1!$A$2:$A$3</c:f></c:strRef></c:cat><c:val><c:numRef><c:f>Sheet1!$C$2:$C$3</c:f></c:numRef></c:val></c:ser><c:axId val="1"/><c:axId val="2"/></c:barChart><c:catAx><c:axId val="1"/></c:catAx><c:valAx><c:axId val="2"/></c:valAx></c:plotArea></c:chart></c:chartSpace>`
+
+func TestCharts(t *testing.T) {
+	c := qt.New(t)
+
+	c.Run("NoCharts", func(c *qt.C) {
+		f := NewFile()
+		_, err := f.AddSheet("Sheet1")
+		c.Assert(err, qt.IsNil)
+		parts, err := f.MarshallParts()
+		c.Assert(err, qt.IsNil)
+
+		f, err = OpenBinary(zipParts(c, parts))
+		c.Assert(err, qt.IsNil)
+		c.Assert(f.Sheets[0].Charts(), qt.HasLen, 0)
+	})
+
+	c.Run("ReadBarChart", func(c *qt.C) {
+		f := NewFile()
+		_, err := f.AddSheet("Sheet1")
+		c.Assert(err, qt.IsNil)
+		parts, err := f.MarshallParts()
+		c.Assert(err, qt.IsNil)
+
+		parts["xl/worksheets/_rels/sheet1.xml.rels"] = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/drawing" Target="../drawings/drawing1.xml"/></Relationships>`
+		parts["xl/drawings/drawing1.xml"] = testDrawingXML
+		parts["xl/drawings/_rels/drawing1.xml.rels"] = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/chart" Target="../charts/chart1.xml"/></Relationships>`
+		parts["xl/charts/chart1.xml"] = testBarChartXML
+
+		f, err = OpenBinary(zipParts(c, parts))
+		c.Assert(err, qt.IsNil)
+		charts := f.Sheets[0].Charts()
+		c.Assert(charts, qt.DeepEquals, []Chart{{
+			Type:  "bar",
+			Title: "Sales by region",
+			Series: []ChartSeries{
+				{
+					Name:          "2019",
+					NameRef:       "Sheet1!$B$1",
+					CategoriesRef: "Sheet1!$A$2:$A$3",
+					Categories:    []string{"North", "South"},
+					ValuesRef:     "Sheet1!$B$2:$B$3",
+					Values:        []string{"10", "20"},
+				},
+				{
+					NameRef:       "Sheet1!$C$1",
+					CategoriesRef: "Sheet1!$A$2:$A$3",
+					ValuesRef:     "Sheet1!$C$2:$C$3",
+				},
+			},
+		}})
+	})
+}
+
+func TestCachedValues(t *testing.T) {
+	c := qt.New(t)
+
+	read := func(cacheXML string) []string {
+		cache := new(xlsxChartDataCache)
+		c.Assert(xml.Unmarshal([]byte(`<c:numCache xmlns:c="http://schemas.openxmlformats.org/drawingml/2006/chart">`+cacheXML+`</c:numCache>`), cache), qt.IsNil)
+		return cachedValues(cache)
+	}
+	// Points left out of a cache are blank.
+	c.Assert(read(`<c:ptCount val="3"/><c:pt idx="2"><c:v>3</c:v></c:pt><c:pt idx="0"><c:v>1</c:v></c:pt>`), qt.DeepEquals, []string{"1", "", "3"})
+	// Indexes beyond the count of points are ignored.
+	c.Assert(read(`<c:ptCount val="2"/><c:pt idx="0"><c:v>1</c:v></c:pt><c:pt idx="100000000"><c:v>2</c:v></c:pt><c:pt idx="-1"><c:v>3</c:v></c:pt>`), qt.DeepEquals, []string{"1", ""})
+	c.Assert(read(`<c:pt idx="1"><c:v>1</c:v></c:pt><c:pt idx="100000000"><c:v>2</c:v></c:pt>`), qt.DeepEquals, []string{"", "1"})
+	// The count is no more than a column of a sheet can hold.
+	c.Assert(read(`<c:ptCount val="100000000"/><c:pt idx="0"><c:v>1</c:v></c:pt>`), qt.HasLen, Excel2006MaxRowCount)
+	c.Assert(cachedValues(nil), qt.IsNil)
+}
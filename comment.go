@@ -0,0 +1,398 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Comment is a note attached to a Cell, as shown by Excel in a pop-up
+// box when hovering over the cell.
+type Comment struct {
+	Author string
+	Text   string
+	// RichText holds the formatted runs of the comment, if it has
+	// any formatting.  When set, it is written in place of Text.
+	RichText []RichTextRun
+	// Anchor is the position and size of the box the comment is shown
+	// in, or nil for the default box to the right of the cell.
+	Anchor *CommentAnchor
+	// FillColor is the background color of the box, as an ARGB hex
+	// string such as "FFCCE5FF", or empty for the default pale yellow.
+	// The alpha is ignored.
+	FillColor string
+	// Font is the formatting of Text.  It is not used when RichText is
+	// set, and a comment that is read back has its formatting in
+	// RichText.
+	Font *RichTextFont
+}
+
+// defaultCommentFillColor is the fill color of comment boxes in VML.
+const defaultCommentFillColor = "#ffffe1"
+
+// commentFillColorToVML returns the color of the box of a comment in
+// the "#rrggbb" form of VML.
+func commentFillColorToVML(color string) (string, error) {
+	if color == "" {
+		return defaultCommentFillColor, nil
+	}
+	rgb := color
+	if len(rgb) == 8 {
+		rgb = rgb[2:]
+	}
+	if _, err := strconv.ParseUint(rgb, 16, 32); err != nil || len(rgb) != 6 {
+		return "", fmt.Errorf("invalid comment fill color %q", color)
+	}
+	return "#" + strings.ToLower(rgb), nil
+}
+
+// commentFillColorFromVML returns the FillColor of a comment from the
+// fillcolor of its box in VML.  The default color and colors that
+// aren't in the "#rrggbb" form, such as the system colors Excel may
+// write, are read as empty.
+func commentFillColorFromVML(color string) string {
+	color = strings.TrimSpace(color)
+	if len(color) != 7 || color[0] != '#' || strings.EqualFold(color, defaultCommentFillColor) {
+		return ""
+	}
+	if _, err := strconv.ParseUint(color[1:], 16, 32); err != nil {
+		return ""
+	}
+	return "FF" + strings.ToUpper(color[1:])
+}
+
+// CommentAnchor is the position of the box a Comment is shown in, given
+// by the cells its top left and bottom right corners are in and the
+// offsets in pixels of the corners from the top left of those cells.
+// Rows and columns count from 0.
+type CommentAnchor struct {
+	LeftColumn   int
+	LeftOffset   int
+	TopRow       int
+	TopOffset    int
+	RightColumn  int
+	RightOffset  int
+	BottomRow    int
+	BottomOffset int
+}
+
+// defaultCommentAnchor returns the anchor Excel gives the box of a new
+// comment on the cell at col and row.
+func defaultCommentAnchor(col, row int) *CommentAnchor {
+	return &CommentAnchor{
+		LeftColumn:   col + 1,
+		LeftOffset:   15,
+		TopRow:       row,
+		TopOffset:    2,
+		RightColumn:  col + 3,
+		RightOffset:  15,
+		BottomRow:    row + 3,
+		BottomOffset: 16,
+	}
+}
+
+// String returns the anchor in the form of the x:Anchor element of a
+// VML drawing.
+func (a *CommentAnchor) String() string {
+	return fmt.Sprintf("%d, %d, %d, %d, %d, %d, %d, %d",
+		a.LeftColumn, a.LeftOffset, a.TopRow, a.TopOffset,
+		a.RightColumn, a.RightOffset, a.BottomRow, a.BottomOffset)
+}
+
+// parseCommentAnchor parses the text of the x:Anchor element of a VML
+// drawing.
+func parseCommentAnchor(text string) (*CommentAnchor, error) {
+	fields := strings.Split(text, ",")
+	if len(fields) != 8 {
+		return nil, fmt.Errorf("invalid comment anchor %q", text)
+	}
+	values := make([]int, len(fields))
+	for i, field := range fields {
+		v, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, fmt.Errorf("invalid comment anchor %q", text)
+		}
+		values[i] = v
+	}
+	return &CommentAnchor{
+		LeftColumn:   values[0],
+		LeftOffset:   values[1],
+		TopRow:       values[2],
+		TopOffset:    values[3],
+		RightColumn:  values[4],
+		RightOffset:  values[5],
+		BottomRow:    values[6],
+		BottomOffset: values[7],
+	}, nil
+}
+
+// makeXLSXComments builds the comments part for the Sheet from the
+// comments attached to its cells.  If no cell has a comment, nil is
+// returned.
+func (s *Sheet) makeXLSXComments() *xlsxComments {
+	var comments *xlsxComments
+	authorIds := make(map[string]int)
+	for r, row := range s.Rows {
+		if row == nil {
+			continue
+		}
+		for c, cell := range row.Cells {
+			if cell == nil || cell.Comment == nil {
+				continue
+			}
+			if comments == nil {
+				comments = &xlsxComments{}
+			}
+			authorId, ok := authorIds[cell.Comment.Author]
+			if !ok {
+				authorId = len(comments.Authors.Author)
+				authorIds[cell.Comment.Author] = authorId
+				comments.Authors.Author = append(comments.Authors.Author, cell.Comment.Author)
+			}
+			text := xlsxCommentText{R: richTextToXLSX([]RichTextRun{{Font: cell.Comment.Font, Text: cell.Comment.Text}})}
+			if len(cell.Comment.RichText) > 0 {
+				text.R = richTextToXLSX(cell.Comment.RichText)
+			}
+			comments.CommentList.Comment = append(comments.CommentList.Comment, xlsxComment{
+				Ref:      GetCellIDStringFromCoords(c, r),
+				AuthorId: authorId,
+				Text:     text,
+			})
+		}
+	}
+	return comments
+}
+
+// vmlShapesPerBlock is the number of shapes a block of VML shape ids
+// holds.  Each block is 1024 ids long, the first of which is not used.
+const vmlShapesPerBlock = 1023
+
+// makeVMLDrawing returns the legacy VML drawing that Excel uses to
+// display the boxes of the given comments of the sheet.  The shape ids
+// are taken from as many blocks as the comments need, starting at
+// firstBlock, so that they are unique across the workbook; the block
+// after the last one used is returned along with the drawing.
+func makeVMLDrawing(sheet *Sheet, comments *xlsxComments, firstBlock int) (string, int, error) {
+	count := len(comments.CommentList.Comment)
+	nextBlock := firstBlock + (count+vmlShapesPerBlock-1)/vmlShapesPerBlock
+	blocks := make([]string, 0, nextBlock-firstBlock)
+	for block := firstBlock; block < nextBlock; block++ {
+		blocks = append(blocks, strconv.Itoa(block))
+	}
+	var b strings.Builder
+	b.WriteString(`<xml xmlns:v="urn:schemas-microsoft-com:vml" xmlns:o="urn:schemas-microsoft-com:office:office" xmlns:x="urn:schemas-microsoft-com:office:excel">`)
+	fmt.Fprintf(&b, `<o:shapelayout v:ext="edit"><o:idmap v:ext="edit" data="%s"/></o:shapelayout>`, strings.Join(blocks, ","))
+	b.WriteString(`<v:shapetype id="_x0000_t202" coordsize="21600,21600" o:spt="202" path="m,l,21600r21600,l21600,xe"><v:stroke joinstyle="miter"/><v:path gradientshapeok="t" o:connecttype="rect"/></v:shapetype>`)
+	for i, comment := range comments.CommentList.Comment {
+		col, row, err := GetCoordsFromCellIDString(comment.Ref)
+		if err != nil {
+			return "", 0, err
+		}
+		cellComment := sheet.Cell(row, col).Comment
+		anchor := cellComment.Anchor
+		if anchor == nil {
+			anchor = defaultCommentAnchor(col, row)
+		}
+		fill, err := commentFillColorToVML(cellComment.FillColor)
+		if err != nil {
+			return "", 0, err
+		}
+		shapeId := (firstBlock+i/vmlShapesPerBlock)*1024 + i%vmlShapesPerBlock + 1
+		fmt.Fprintf(&b, `<v:shape id="_x0000_s%d" type="#_x0000_t202" style="position:absolute;margin-left:59.25pt;margin-top:1.5pt;width:108pt;height:59.25pt;z-index:%d;visibility:hidden" fillcolor="%s" o:insetmode="auto">`, shapeId, i+1, fill)
+		fmt.Fprintf(&b, `<v:fill color2="%s"/><v:shadow on="t" color="black" obscured="t"/><v:path o:connecttype="none"/><v:textbox style="mso-direction-alt:auto"><div style="text-align:left"></div></v:textbox>`, fill)
+		fmt.Fprintf(&b, `<x:ClientData ObjectType="Note"><x:MoveWithCells/><x:SizeWithCells/><x:Anchor>%s</x:Anchor><x:AutoFill>False</x:AutoFill><x:Row>%d</x:Row><x:Column>%d</x:Column></x:ClientData>`, anchor, row, col)
+		b.WriteString(`</v:shape>`)
+	}
+	b.WriteString(`</xml>`)
+	return b.String(), nextBlock, nil
+}
+
+// commentText returns the plain text of a comment, joining together
+// any formatted runs it is made of.
+func commentText(text xlsxCommentText) string {
+	if len(text.R) == 0 {
+		return text.T
+	}
+	var b strings.Builder
+	for _, r := range text.R {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+// commentRichText returns the formatted runs of a comment, or nil if
+// none of them has any formatting.
+func commentRichText(text xlsxCommentText) []RichTextRun {
+	for _, r := range text.R {
+		if r.RPr != nil {
+			return richTextFromXLSX(text.R)
+		}
+	}
+	return nil
+}
+
+// readCommentsFromZipFile is an internal helper function that reads
+// the comments part referred to by the worksheet relationships and
+// attaches each comment to the corresponding Cell of the Sheet.
+func readCommentsFromZipFile(sheet *Sheet, worksheetRels *xlsxWorksheetRels, parts map[string]*zip.File) error {
+	for _, rel := range worksheetRels.Relationships {
+		if rel.Type != RelationshipTypeComments {
+			continue
+		}
+		f, ok := parts[resolveWorksheetRelTarget(rel.Target)]
+		if !ok {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		comments := new(xlsxComments)
+		err = xml.NewDecoder(rc).Decode(comments)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+		for _, comment := range comments.CommentList.Comment {
+			x, y, err := GetCoordsFromCellIDString(comment.Ref)
+			if err != nil {
+				return err
+			}
+			author := ""
+			if comment.AuthorId >= 0 && comment.AuthorId < len(comments.Authors.Author) {
+				author = comments.Authors.Author[comment.AuthorId]
+			}
+			sheet.Cell(y, x).Comment = &Comment{
+				Author:   author,
+				Text:     commentText(comment.Text),
+				RichText: commentRichText(comment.Text),
+			}
+		}
+	}
+	for _, rel := range worksheetRels.Relationships {
+		if rel.Type != RelationshipTypeVMLDrawing {
+			continue
+		}
+		f, ok := parts[resolveWorksheetRelTarget(rel.Target)]
+		if !ok {
+			continue
+		}
+		if err := readCommentBoxesFromVML(sheet, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readCommentBoxesFromVML sets the anchors and fill colors of the
+// comments of the sheet from the boxes of the notes in the VML drawing
+// f.  VML is
+// written by Excel as loose HTML style markup, with unclosed elements
+// such as <br>, so it is not decoded strictly.
+func readCommentBoxesFromVML(sheet *Sheet, f *zip.File) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	decoder := xml.NewDecoder(rc)
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	var note bool
+	var anchor, row, col, text, fill string
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			text = ""
+			if t.Name.Local == "shape" {
+				fill = ""
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "fillcolor" {
+						fill = attr.Value
+					}
+				}
+			}
+			if t.Name.Local == "ClientData" {
+				note, anchor, row, col = false, "", "", ""
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "ObjectType" && attr.Value == "Note" {
+						note = true
+					}
+				}
+			}
+		case xml.CharData:
+			text += string(t)
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "Anchor":
+				anchor = text
+			case "Row":
+				row = text
+			case "Column":
+				col = text
+			case "ClientData":
+				if !note {
+					continue
+				}
+				y, err := strconv.Atoi(strings.TrimSpace(row))
+				if err != nil {
+					continue
+				}
+				x, err := strconv.Atoi(strings.TrimSpace(col))
+				if err != nil {
+					continue
+				}
+				if y < 0 || y >= len(sheet.Rows) || sheet.Rows[y] == nil || x < 0 || x >= len(sheet.Rows[y].Cells) {
+					continue
+				}
+				cell := sheet.Rows[y].Cells[x]
+				if cell == nil || cell.Comment == nil {
+					continue
+				}
+				cell.Comment.FillColor = commentFillColorFromVML(fill)
+				if anchor == "" {
+					continue
+				}
+				a, err := parseCommentAnchor(anchor)
+				if err != nil {
+					return err
+				}
+				// The default box is left as nil, so that it
+				// moves with the comment if that is moved.
+				if *a != *defaultCommentAnchor(x, y) {
+					cell.Comment.Anchor = a
+				}
+			}
+		}
+	}
+}
+
+// resolveWorksheetRelTarget returns the name of the part within the
+// zip file that a relationship target of a worksheet refers to.
+func resolveWorksheetRelTarget(target string) string {
+	return resolveRelTarget("xl/worksheets/sheet.xml", target)
+}
+
+// resolveRelTarget returns the name of the part within the zip file
+// that a relationship target of the part partName refers to.
+// Targets are relative to the directory holding the part, unless they
+// start with a "/".
+func resolveRelTarget(partName, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return target[1:]
+	}
+	return path.Join(path.Dir(partName), target)
+}
@@ -0,0 +1,282 @@
+package xlsx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestComment(t *testing.T) {
+	c := qt.New(t)
+
+	c.Run("MakeXLSXComments", func(c *qt.C) {
+		f := NewFile()
+		sheet, err := f.AddSheet("Sheet1")
+		c.Assert(err, qt.IsNil)
+		c.Assert(sheet.makeXLSXComments(), qt.IsNil)
+
+		sheet.Cell(0, 0).SetComment("Alice", "first")
+		sheet.Cell(2, 1).SetComment("Bob", "second")
+		sheet.Cell(3, 3).SetComment("Alice", "third")
+
+		comments := sheet.makeXLSXComments()
+		c.Assert(comments, qt.Not(qt.IsNil))
+		c.Assert(comments.Authors.Author, qt.DeepEquals, []string{"Alice", "Bob"})
+		c.Assert(comments.CommentList.Comment, qt.HasLen, 3)
+		c.Assert(comments.CommentList.Comment[0].Ref, qt.Equals, "A1")
+		c.Assert(comments.CommentList.Comment[0].AuthorId, qt.Equals, 0)
+		c.Assert(commentText(comments.CommentList.Comment[0].Text), qt.Equals, "first")
+		c.Assert(comments.CommentList.Comment[1].Ref, qt.Equals, "B3")
+		c.Assert(comments.CommentList.Comment[1].AuthorId, qt.Equals, 1)
+		c.Assert(comments.CommentList.Comment[2].Ref, qt.Equals, "D4")
+		c.Assert(comments.CommentList.Comment[2].AuthorId, qt.Equals, 0)
+	})
+
+	c.Run("MarshallParts", func(c *qt.C) {
+		f := NewFile()
+		_, err := f.AddSheet("Plain")
+		c.Assert(err, qt.IsNil)
+		sheet, err := f.AddSheet("Commented")
+		c.Assert(err, qt.IsNil)
+		sheet.Cell(1, 1).SetComment("Alice", "Check this")
+
+		parts, err := f.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		c.Assert(parts["xl/worksheets/sheet1.xml"], qt.Not(qt.Contains), "legacyDrawing")
+		c.Assert(parts["xl/worksheets/sheet2.xml"], qt.Contains, `<legacyDrawing r:id="rId2"></legacyDrawing>`)
+		c.Assert(parts["xl/worksheets/_rels/sheet2.xml.rels"], qt.Contains, `<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/comments" Target="../comments2.xml"`)
+		c.Assert(parts["xl/worksheets/_rels/sheet2.xml.rels"], qt.Contains, `<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/vmlDrawing" Target="../drawings/vmlDrawing2.vml"`)
+		c.Assert(parts["xl/comments2.xml"], qt.Contains, `<authors><author>Alice</author></authors>`)
+		c.Assert(parts["xl/comments2.xml"], qt.Contains, `<comment ref="B2" authorId="0"><text><r><t>Check this</t></r></text></comment>`)
+		c.Assert(parts["xl/drawings/vmlDrawing2.vml"], qt.Contains, `<x:Row>1</x:Row><x:Column>1</x:Column>`)
+		c.Assert(parts["[Content_Types].xml"], qt.Contains, `<Override PartName="/xl/comments2.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.comments+xml"></Override>`)
+		c.Assert(strings.Count(parts["[Content_Types].xml"], `Extension="vml"`), qt.Equals, 1)
+	})
+
+	c.Run("ShapeIds", func(c *qt.C) {
+		f := NewFile()
+		many, err := f.AddSheet("Many")
+		c.Assert(err, qt.IsNil)
+		for row := 0; row < 1024; row++ {
+			many.Cell(row, 0).SetComment("Alice", "note")
+		}
+		few, err := f.AddSheet("Few")
+		c.Assert(err, qt.IsNil)
+		few.Cell(0, 0).SetComment("Alice", "note")
+
+		parts, err := f.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		// The first sheet needs two blocks of ids, so the second sheet
+		// takes the third.
+		vml := parts["xl/drawings/vmlDrawing1.vml"]
+		c.Assert(vml, qt.Contains, `<o:idmap v:ext="edit" data="1,2"/>`)
+		c.Assert(vml, qt.Contains, `<v:shape id="_x0000_s1025" `)
+		c.Assert(vml, qt.Contains, `<v:shape id="_x0000_s2047" `)
+		c.Assert(vml, qt.Contains, `<v:shape id="_x0000_s2049" `)
+		c.Assert(vml, qt.Not(qt.Contains), `<v:shape id="_x0000_s2048" `)
+		vml = parts["xl/drawings/vmlDrawing2.vml"]
+		c.Assert(vml, qt.Contains, `<o:idmap v:ext="edit" data="3"/>`)
+		c.Assert(vml, qt.Contains, `<v:shape id="_x0000_s3073" `)
+	})
+
+	c.Run("RoundTrip", func(c *qt.C) {
+		f := NewFile()
+		sheet, err := f.AddSheet("Sheet1")
+		c.Assert(err, qt.IsNil)
+		sheet.Cell(0, 0).SetHyperlink("https://example.com", "Example", "")
+		cell := sheet.Cell(0, 1)
+		cell.SetString("Total")
+		cell.SetComment("Alice", "Includes tax")
+		// Comments may also be attached to cells with no value.
+		sheet.Cell(4, 3).SetComment("Bob", "Empty")
+
+		var buf bytes.Buffer
+		c.Assert(f.Write(&buf), qt.IsNil)
+
+		f, err = OpenBinary(buf.Bytes())
+		c.Assert(err, qt.IsNil)
+		sheet = f.Sheet["Sheet1"]
+		c.Assert(sheet.Cell(0, 0).Hyperlink.Link, qt.Equals, "https://example.com")
+		c.Assert(sheet.Cell(0, 0).Comment, qt.IsNil)
+		c.Assert(sheet.Cell(0, 1).Value, qt.Equals, "Total")
+		c.Assert(sheet.Cell(0, 1).Comment, qt.DeepEquals, &Comment{Author: "Alice", Text: "Includes tax"})
+		c.Assert(sheet.Cell(4, 3).Comment, qt.DeepEquals, &Comment{Author: "Bob", Text: "Empty"})
+		text, ok := sheet.Cell(0, 1).GetComment()
+		c.Assert(ok, qt.Equals, true)
+		c.Assert(text, qt.Equals, "Includes tax")
+	})
+
+	c.Run("SharedPart", func(c *qt.C) {
+		f := NewFile()
+		sheet, err := f.AddSheet("Sheet1")
+		c.Assert(err, qt.IsNil)
+		sheet.Cell(0, 0).SetComment("Alice", "First")
+		sheet.Cell(5, 2).SetComment("Bob", "Second")
+
+		parts, err := f.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		c.Assert(parts["xl/comments1.xml"], qt.Contains, `<comment ref="A1" authorId="0">`)
+		c.Assert(parts["xl/comments1.xml"], qt.Contains, `<comment ref="C6" authorId="1">`)
+		c.Assert(strings.Count(parts["xl/worksheets/_rels/sheet1.xml.rels"], "relationships/comments"), qt.Equals, 1)
+		c.Assert(strings.Count(parts["[Content_Types].xml"], "/xl/comments"), qt.Equals, 1)
+
+		var buf bytes.Buffer
+		c.Assert(f.Write(&buf), qt.IsNil)
+		f, err = OpenBinary(buf.Bytes())
+		c.Assert(err, qt.IsNil)
+		sheet = f.Sheet["Sheet1"]
+		for _, test := range []struct {
+			row, col     int
+			text, author string
+		}{{0, 0, "First", "Alice"}, {5, 2, "Second", "Bob"}} {
+			text, ok := sheet.Cell(test.row, test.col).GetComment()
+			c.Assert(ok, qt.Equals, true)
+			c.Assert(text, qt.Equals, test.text)
+			c.Assert(sheet.Cell(test.row, test.col).Comment.Author, qt.Equals, test.author)
+		}
+	})
+
+	c.Run("RichText", func(c *qt.C) {
+		f := NewFile()
+		sheet, err := f.AddSheet("Sheet1")
+		c.Assert(err, qt.IsNil)
+		sheet.Cell(0, 0).Comment = &Comment{
+			Author: "Alice",
+			RichText: []RichTextRun{
+				{Text: "Check the "},
+				{Font: &RichTextFont{Bold: true}, Text: "total"},
+				{Text: " first"},
+			},
+		}
+
+		parts, err := f.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		c.Assert(parts["xl/comments1.xml"], qt.Contains, `<text><r><t>Check the </t></r><r><rPr><b></b></rPr><t>total</t></r><r><t> first</t></r></text>`)
+
+		var buf bytes.Buffer
+		c.Assert(f.Write(&buf), qt.IsNil)
+		f, err = OpenBinary(buf.Bytes())
+		c.Assert(err, qt.IsNil)
+		comment := f.Sheets[0].Cell(0, 0).Comment
+		c.Assert(comment, qt.Not(qt.IsNil))
+		c.Assert(comment.Text, qt.Equals, "Check the total first")
+		c.Assert(comment.RichText, qt.HasLen, 3)
+		c.Assert(comment.RichText[0].Font, qt.IsNil)
+		c.Assert(comment.RichText[1].Text, qt.Equals, "total")
+		c.Assert(comment.RichText[1].Font, qt.DeepEquals, &RichTextFont{Bold: true})
+		c.Assert(comment.RichText[2].Text, qt.Equals, " first")
+	})
+
+	c.Run("Anchor", func(c *qt.C) {
+		f := NewFile()
+		sheet, err := f.AddSheet("Sheet1")
+		c.Assert(err, qt.IsNil)
+		anchor := &CommentAnchor{
+			LeftColumn:   4,
+			LeftOffset:   10,
+			TopRow:       1,
+			TopOffset:    5,
+			RightColumn:  8,
+			RightOffset:  0,
+			BottomRow:    12,
+			BottomOffset: 7,
+		}
+		sheet.Cell(2, 1).Comment = &Comment{Author: "Alice", Text: "Positioned", Anchor: anchor}
+		sheet.Cell(3, 1).SetComment("Alice", "Default")
+
+		parts, err := f.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		c.Assert(parts["xl/drawings/vmlDrawing1.vml"], qt.Contains, `<x:Anchor>4, 10, 1, 5, 8, 0, 12, 7</x:Anchor><x:AutoFill>False</x:AutoFill><x:Row>2</x:Row><x:Column>1</x:Column>`)
+		c.Assert(parts["xl/drawings/vmlDrawing1.vml"], qt.Contains, `<x:Anchor>2, 15, 3, 2, 4, 15, 6, 16</x:Anchor><x:AutoFill>False</x:AutoFill><x:Row>3</x:Row><x:Column>1</x:Column>`)
+
+		f, err = OpenBinary(zipParts(c, parts))
+		c.Assert(err, qt.IsNil)
+		sheet = f.Sheets[0]
+		c.Assert(sheet.Cell(2, 1).Comment.Anchor, qt.DeepEquals, anchor)
+		c.Assert(sheet.Cell(3, 1).Comment.Anchor, qt.IsNil)
+	})
+
+	c.Run("FillColorAndFont", func(c *qt.C) {
+		f := NewFile()
+		sheet, err := f.AddSheet("Sheet1")
+		c.Assert(err, qt.IsNil)
+		font := &RichTextFont{Name: "Calibri", Size: 10, Bold: true, Color: "FF1F3864"}
+		sheet.Cell(0, 0).Comment = &Comment{Author: "Alice", Text: "Branded", FillColor: "FFCCE5FF", Font: font}
+		sheet.Cell(1, 0).SetComment("Alice", "Default")
+
+		parts, err := f.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		vml := parts["xl/drawings/vmlDrawing1.vml"]
+		c.Assert(vml, qt.Contains, `fillcolor="#cce5ff"`)
+		c.Assert(vml, qt.Contains, `<v:fill color2="#cce5ff"/>`)
+		c.Assert(vml, qt.Contains, `fillcolor="#ffffe1"`)
+
+		f, err = OpenBinary(zipParts(c, parts))
+		c.Assert(err, qt.IsNil)
+		sheet = f.Sheets[0]
+		comment := sheet.Cell(0, 0).Comment
+		c.Assert(comment.FillColor, qt.Equals, "FFCCE5FF")
+		c.Assert(comment.Text, qt.Equals, "Branded")
+		c.Assert(comment.RichText, qt.DeepEquals, []RichTextRun{{Font: font, Text: "Branded"}})
+		c.Assert(sheet.Cell(1, 0).Comment.FillColor, qt.Equals, "")
+
+		sheet.Cell(0, 0).Comment.FillColor = "blue"
+		_, err = f.MarshallParts()
+		c.Assert(err, qt.ErrorMatches, `invalid comment fill color "blue"`)
+	})
+
+	c.Run("ReadExcelVML", func(c *qt.C) {
+		f := NewFile()
+		sheet, err := f.AddSheet("Sheet1")
+		c.Assert(err, qt.IsNil)
+		sheet.Cell(0, 0).SetComment("Alice", "Note")
+		parts, err := f.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		// Excel writes VML with unclosed <br> elements.
+		parts["xl/drawings/vmlDrawing1.vml"] = `<xml xmlns:v="urn:schemas-microsoft-com:vml"
+ xmlns:o="urn:schemas-microsoft-com:office:office"
+ xmlns:x="urn:schemas-microsoft-com:office:excel">
+ <o:shapelayout v:ext="edit">
+  <o:idmap v:ext="edit" data="1"/>
+ </o:shapelayout>
+ <v:shape id="_x0000_s1025" type="#_x0000_t202" style='position:absolute;
+  margin-left:120pt;margin-top:30pt;width:200pt;height:90pt;z-index:1;
+  visibility:hidden' fillcolor="#ffffe1" o:insetmode="auto">
+  <v:textbox style='mso-direction-alt:auto'>
+   <div style='text-align:left'></div>
+  </v:textbox>
+  <x:ClientData ObjectType="Note">
+   <x:MoveWithCells/>
+   <x:SizeWithCells/>
+   <x:Anchor>
+    3, 0, 2, 0, 6, 31, 9, 4</x:Anchor>
+   <x:AutoFill>False</x:AutoFill>
+   <x:Row>0</x:Row>
+   <x:Column>0</x:Column>
+  </x:ClientData>
+ </v:shape>
+ <v:shape id="_x0000_s1026" type="#_x0000_t201">
+  <x:ClientData ObjectType="Drop">
+   <x:Anchor>
+    0, 0, 5, 0, 1, 0, 6, 0</x:Anchor>
+   <x:Row>5</x:Row>
+   <x:Column>0</x:Column>
+  </x:ClientData>
+ </v:shape>
+ <br>
+</xml>`
+
+		f, err = OpenBinary(zipParts(c, parts))
+		c.Assert(err, qt.IsNil)
+		c.Assert(f.Sheets[0].Cell(0, 0).Comment.Anchor, qt.DeepEquals, &CommentAnchor{
+			LeftColumn:   3,
+			TopRow:       2,
+			RightColumn:  6,
+			RightOffset:  31,
+			BottomRow:    9,
+			BottomOffset: 4,
+		})
+	})
+}
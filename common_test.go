@@ -1,8 +1,74 @@
 package xlsx
 
 import (
-	. "gopkg.in/check.v1"
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
 	"testing"
+
+	qt "github.com/frankban/quicktest"
+	. "gopkg.in/check.v1"
 )
 
 func Test(t *testing.T) { TestingT(t) }
+
+// unzipParts reads all the parts of an in memory XLSX zip file.
+func unzipParts(c *qt.C, data []byte) map[string]string {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	c.Assert(err, qt.IsNil)
+	parts := make(map[string]string)
+	for _, zf := range zr.File {
+		rc, err := zf.Open()
+		c.Assert(err, qt.IsNil)
+		content, err := ioutil.ReadAll(rc)
+		c.Assert(err, qt.IsNil)
+		c.Assert(rc.Close(), qt.IsNil)
+		parts[zf.Name] = string(content)
+	}
+	return parts
+}
+
+// zipParts writes the given parts into an in memory XLSX zip file.
+func zipParts(c *qt.C, parts map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		c.Assert(err, qt.IsNil)
+		_, err = w.Write([]byte(content))
+		c.Assert(err, qt.IsNil)
+	}
+	c.Assert(zw.Close(), qt.IsNil)
+	return buf.Bytes()
+}
+
+// streamRows streams a file with a single sheet, Sheet1, that holds
+// rows, and returns the parts of the file along with the file read
+// back.  The styles of the cells are added to the builder, and setup,
+// unless it is nil, is called on the builder once the sheet has been
+// added.
+func streamRows(c *qt.C, setup func(*StreamFileBuilder), rows ...[]StreamCell) (map[string]string, *File) {
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	for _, row := range rows {
+		for _, cell := range row {
+			if !cell.autoStyle && cell.cellStyle != StreamStyleFromColumn {
+				c.Assert(fileBuilder.AddStreamStyle(cell.cellStyle), qt.IsNil)
+			}
+		}
+	}
+	c.Assert(fileBuilder.AddSheet("Sheet1", nil), qt.IsNil)
+	if setup != nil {
+		setup(fileBuilder)
+	}
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	for _, row := range rows {
+		c.Assert(streamFile.WriteS(row), qt.IsNil)
+	}
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	return unzipParts(c, buffer.Bytes()), file
+}
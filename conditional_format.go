@@ -0,0 +1,56 @@
+package xlsx
+
+const colorScaleRuleType = "colorScale"
+
+// ColorScale is a conditional format that shades each cell of the
+// range Ref, such as "A1:D10", by its value, blending from MinColor
+// for the lowest value in the range to MaxColor for the highest.  The
+// colors are ARGB hex strings, such as "FFF8696B".
+type ColorScale struct {
+	Ref      string
+	MinColor string
+	MaxColor string
+}
+
+// AddColorScale adds a two color scale over the range ref of the
+// sheet, see ColorScale.
+func (s *Sheet) AddColorScale(ref, minColor, maxColor string) {
+	s.ColorScales = append(s.ColorScales, ColorScale{Ref: ref, MinColor: minColor, MaxColor: maxColor})
+}
+
+// makeConditionalFormatting adds the conditional formats of the sheet
+// to its XML representation.
+func (s *Sheet) makeConditionalFormatting(worksheet *xlsxWorksheet) {
+	for i, colorScale := range s.ColorScales {
+		worksheet.ConditionalFormatting = append(worksheet.ConditionalFormatting, xlsxConditionalFormatting{
+			SQRef: colorScale.Ref,
+			CfRule: []xlsxCfRule{{
+				Type:     colorScaleRuleType,
+				Priority: i + 1,
+				ColorScale: &xlsxColorScale{
+					Cfvo:  []xlsxCfvo{{Type: "min"}, {Type: "max"}},
+					Color: []xlsxColor{{RGB: colorScale.MinColor}, {RGB: colorScale.MaxColor}},
+				},
+			}},
+		})
+	}
+}
+
+// readColorScales returns the two color scales among the conditional
+// formats of a worksheet.  Other conditional formats are not read.
+func readColorScales(conditionalFormatting []xlsxConditionalFormatting) []ColorScale {
+	var colorScales []ColorScale
+	for _, cf := range conditionalFormatting {
+		for _, rule := range cf.CfRule {
+			if rule.Type != colorScaleRuleType || rule.ColorScale == nil || len(rule.ColorScale.Color) != 2 {
+				continue
+			}
+			colorScales = append(colorScales, ColorScale{
+				Ref:      cf.SQRef,
+				MinColor: rule.ColorScale.Color[0].RGB,
+				MaxColor: rule.ColorScale.Color[1].RGB,
+			})
+		}
+	}
+	return colorScales
+}
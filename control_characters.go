@@ -0,0 +1,84 @@
+package xlsx
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ControlCharacterMode is how strings written by a StreamFile deal with
+// the control characters, such as 0x01, that XML does not allow.
+type ControlCharacterMode int
+
+const (
+	// StripControlCharacters removes the characters, which is the
+	// default.
+	StripControlCharacters ControlCharacterMode = iota
+	// EscapeControlCharacters writes the characters as escapes of the
+	// form _x0001_, which spreadsheet applications turn back into the
+	// characters.  Underscores that would read as the start of such an
+	// escape are escaped themselves.
+	EscapeControlCharacters
+	// ErrorOnControlCharacters fails the write of a string holding one
+	// of the characters.
+	ErrorOnControlCharacters
+)
+
+// isXMLChar reports whether r may appear in an XML 1.0 document.
+func isXMLChar(r rune) bool {
+	return r == '\t' || r == '\n' || r == '\r' ||
+		r >= 0x20 && r <= 0xD7FF ||
+		r >= 0xE000 && r <= 0xFFFD ||
+		r >= 0x10000 && r <= utf8.MaxRune
+}
+
+// hasEscapeSequence reports whether s holds text of the form _xHHHH_,
+// which would be taken for an escaped character.
+func hasEscapeSequence(s string) bool {
+	for i := strings.Index(s, "_x"); i != -1; i = strings.Index(s, "_x") {
+		if isEscapeSequence(s[i:]) {
+			return true
+		}
+		s = s[i+2:]
+	}
+	return false
+}
+
+// isEscapeSequence reports whether s starts with text of the form
+// _xHHHH_.
+func isEscapeSequence(s string) bool {
+	if len(s) < 7 || s[0] != '_' || s[1] != 'x' || s[6] != '_' {
+		return false
+	}
+	for _, b := range []byte(s[2:6]) {
+		if !('0' <= b && b <= '9' || 'a' <= b && b <= 'f' || 'A' <= b && b <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// sanitizeControlCharacters returns s with the characters XML does not
+// allow dealt with according to mode.
+func sanitizeControlCharacters(s string, mode ControlCharacterMode) (string, error) {
+	if strings.IndexFunc(s, func(r rune) bool { return !isXMLChar(r) }) == -1 &&
+		(mode != EscapeControlCharacters || !hasEscapeSequence(s)) {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		// Invalid UTF-8 decodes to U+FFFD, which is left for the XML
+		// encoder to deal with.
+		r, width := utf8.DecodeRuneInString(s[i:])
+		i += width
+		switch {
+		case isXMLChar(r) && !(r == '_' && mode == EscapeControlCharacters && isEscapeSequence(s[i-width:])):
+			b.WriteString(s[i-width : i])
+		case mode == ErrorOnControlCharacters:
+			return "", fmt.Errorf("the string holds the character %U, which cannot be written to XML", r)
+		case mode == EscapeControlCharacters:
+			fmt.Fprintf(&b, "_x%04X_", r)
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,146 @@
+package xlsx
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"strconv"
+)
+
+// csvSampleRows is the number of rows of a CSV file that StreamCSVToXLSX
+// looks at to tell which of its columns hold numbers.
+const csvSampleRows = 100
+
+// CSVImportOptions controls how StreamCSVToXLSX reads a CSV file.
+type CSVImportOptions struct {
+	// Delimiter separates the fields of a row, ',' if it is zero.
+	Delimiter rune
+	// Header is set when the first row holds the names of the
+	// columns, which are written in bold.
+	Header bool
+	// SheetName is the name of the sheet the rows are written to,
+	// "Sheet1" if it is empty.
+	SheetName string
+}
+
+// StreamCSVToXLSX reads the rows of the CSV file r and writes them to
+// w as an XLSX file with a single sheet, one row at a time, so that
+// files of any size can be converted in constant memory.  Every row
+// must have as many fields as the first.
+//
+// The columns whose values all read as numbers in the first rows of
+// the file are written as numeric cells; values further down that do
+// not read as numbers are written as text all the same.  Empty fields
+// leave their cells empty.
+func StreamCSVToXLSX(r io.Reader, w io.Writer, opts CSVImportOptions) error {
+	reader := csv.NewReader(r)
+	if opts.Delimiter != 0 {
+		reader.Comma = opts.Delimiter
+	}
+	var header []string
+	if opts.Header {
+		record, err := reader.Read()
+		if err != nil && err != io.EOF {
+			return err
+		}
+		header = record
+	}
+	var sample [][]string
+	for len(sample) < csvSampleRows {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		sample = append(sample, record)
+	}
+
+	columnCount := len(header)
+	if len(sample) > 0 {
+		columnCount = len(sample[0])
+	}
+	numeric := make([]bool, columnCount)
+	for col := range numeric {
+		for _, record := range sample {
+			if record[col] == "" {
+				continue
+			}
+			if !isCSVNumber(record[col]) {
+				numeric[col] = false
+				break
+			}
+			numeric[col] = true
+		}
+	}
+
+	sheetName := opts.SheetName
+	if sheetName == "" {
+		sheetName = "Sheet1"
+	}
+	builder := NewStreamFileBuilder(w)
+	if err := builder.AddStreamStyleList([]StreamStyle{StreamStyleDefaultString, StreamStyleBoldString, generalNumberStyle}); err != nil {
+		return err
+	}
+	columnStyles := make([]StreamStyle, columnCount)
+	for i := range columnStyles {
+		columnStyles[i] = StreamStyleDefaultString
+	}
+	if err := builder.AddSheetS(sheetName, columnStyles); err != nil {
+		return err
+	}
+	streamFile, err := builder.Build()
+	if err != nil {
+		return err
+	}
+
+	cells := make([]StreamCell, columnCount)
+	if header != nil {
+		for i, name := range header {
+			cells[i] = NewStyledStringStreamCell(name, StreamStyleBoldString)
+		}
+		if err := streamFile.WriteS(cells); err != nil {
+			return err
+		}
+	}
+	write := func(record []string) error {
+		for i, value := range record {
+			switch {
+			case value == "":
+				cells[i] = SkipStreamCell()
+			case numeric[i] && isCSVNumber(value):
+				cells[i] = NewStreamCell(value, generalNumberStyle, CellTypeNumeric)
+			default:
+				cells[i] = NewStringStreamCell(value)
+			}
+		}
+		return streamFile.WriteS(cells)
+	}
+	for _, record := range sample {
+		if err := write(record); err != nil {
+			return err
+		}
+	}
+	sample = nil
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := write(record); err != nil {
+			return err
+		}
+	}
+	return streamFile.Close()
+}
+
+// isCSVNumber returns true if the field of a CSV file value reads as a
+// finite number.
+func isCSVNumber(value string) bool {
+	f, err := strconv.ParseFloat(value, 64)
+	return err == nil && !math.IsInf(f, 0) && !math.IsNaN(f)
+}
@@ -0,0 +1,80 @@
+package xlsx
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestStreamCSVToXLSX(t *testing.T) {
+	c := qt.New(t)
+
+	c.Run("Header", func(c *qt.C) {
+		input := "name;amount;code\napple;1.5;007\npear;;A12\n\"a;b\";-3;x\n"
+		var buf bytes.Buffer
+		err := StreamCSVToXLSX(strings.NewReader(input), &buf, CSVImportOptions{Delimiter: ';', Header: true, SheetName: "Fruit"})
+		c.Assert(err, qt.IsNil)
+
+		file, err := OpenBinary(buf.Bytes())
+		c.Assert(err, qt.IsNil)
+		sheet := file.Sheet["Fruit"]
+		c.Assert(sheet, qt.Not(qt.IsNil))
+		c.Assert(sheet.MaxRow, qt.Equals, 4)
+		for col, name := range []string{"name", "amount", "code"} {
+			c.Assert(sheet.Cell(0, col).Value, qt.Equals, name)
+			c.Assert(sheet.Cell(0, col).GetStyle().Font.Bold, qt.Equals, true)
+		}
+		c.Assert(sheet.Cell(1, 0).Type(), qt.Equals, CellTypeInline)
+		c.Assert(sheet.Cell(1, 1).Type(), qt.Equals, CellTypeNumeric)
+		c.Assert(sheet.Cell(1, 1).Value, qt.Equals, "1.5")
+		c.Assert(sheet.Cell(1, 1).GetStyle().Font.Bold, qt.Equals, false)
+		c.Assert(sheet.Cell(2, 1).IsEmpty(), qt.Equals, true)
+		c.Assert(sheet.Cell(3, 0).Value, qt.Equals, "a;b")
+		c.Assert(sheet.Cell(3, 1).Value, qt.Equals, "-3")
+		// A column holding anything but numbers is kept as text.
+		c.Assert(sheet.Cell(1, 2).Type(), qt.Equals, CellTypeInline)
+		c.Assert(sheet.Cell(1, 2).Value, qt.Equals, "007")
+	})
+
+	c.Run("BeyondSample", func(c *qt.C) {
+		var input strings.Builder
+		rows := csvSampleRows + 50
+		for i := 0; i < rows; i++ {
+			value := fmt.Sprint(i)
+			if i == csvSampleRows+10 {
+				value = "n/a"
+			}
+			fmt.Fprintf(&input, "row %d,%s\n", i, value)
+		}
+		var buf bytes.Buffer
+		c.Assert(StreamCSVToXLSX(strings.NewReader(input.String()), &buf, CSVImportOptions{}), qt.IsNil)
+
+		file, err := OpenBinary(buf.Bytes())
+		c.Assert(err, qt.IsNil)
+		sheet := file.Sheet["Sheet1"]
+		c.Assert(sheet.MaxRow, qt.Equals, rows)
+		c.Assert(sheet.Cell(0, 0).GetStyle().Font.Bold, qt.Equals, false)
+		c.Assert(sheet.Cell(rows-1, 1).Type(), qt.Equals, CellTypeNumeric)
+		c.Assert(sheet.Cell(rows-1, 1).Value, qt.Equals, fmt.Sprint(rows-1))
+		c.Assert(sheet.Cell(csvSampleRows+10, 1).Type(), qt.Equals, CellTypeInline)
+		c.Assert(sheet.Cell(csvSampleRows+10, 1).Value, qt.Equals, "n/a")
+	})
+
+	c.Run("Empty", func(c *qt.C) {
+		var buf bytes.Buffer
+		c.Assert(StreamCSVToXLSX(strings.NewReader(""), &buf, CSVImportOptions{Header: true}), qt.IsNil)
+		file, err := OpenBinary(buf.Bytes())
+		c.Assert(err, qt.IsNil)
+		c.Assert(file.Sheets, qt.HasLen, 1)
+		c.Assert(file.Sheets[0].MaxRow, qt.Equals, 0)
+	})
+
+	c.Run("RaggedRows", func(c *qt.C) {
+		var buf bytes.Buffer
+		err := StreamCSVToXLSX(strings.NewReader("a,b\n1,2,3\n"), &buf, CSVImportOptions{})
+		c.Assert(err, qt.ErrorMatches, ".*wrong number of fields")
+	})
+}
@@ -2,6 +2,8 @@ package xlsx
 
 import (
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
 )
 
@@ -164,6 +166,46 @@ func (dd *xlsxDataValidation) SetRange(f1, f2 int, t DataValidationType, o DataV
 	return nil
 }
 
+// NewWholeNumberValidation returns a data validation that only allows
+// whole numbers comparing with min and max as given by the operator.
+// Operators other than between and notBetween compare with min alone,
+// and max is ignored.  The validation applies to no cells until it is
+// given to Cell.SetDataValidation or StreamFileBuilder.AddDataValidation.
+func NewWholeNumberValidation(o DataValidationOperator, min, max int) (*xlsxDataValidation, error) {
+	return newNumberValidation(DataValidationTypeWhole, o, strconv.Itoa(min), strconv.Itoa(max), min > max)
+}
+
+// NewDecimalValidation is like NewWholeNumberValidation, but allows
+// any number, including those with a fractional part.
+func NewDecimalValidation(o DataValidationOperator, min, max float64) (*xlsxDataValidation, error) {
+	if math.IsNaN(min) || math.IsInf(min, 0) || math.IsNaN(max) || math.IsInf(max, 0) {
+		return nil, fmt.Errorf("the bounds of a decimal validation must be finite numbers")
+	}
+	return newNumberValidation(DataValidationTypeDecimal, o,
+		strconv.FormatFloat(min, 'f', -1, 64), strconv.FormatFloat(max, 'f', -1, 64), min > max)
+}
+
+func newNumberValidation(t DataValidationType, o DataValidationOperator, formula1, formula2 string, swap bool) (*xlsxDataValidation, error) {
+	operator := convDataValidationOperatior(o)
+	if operator == "" {
+		return nil, fmt.Errorf("unknown data validation operator %d", o)
+	}
+	dd := &xlsxDataValidation{
+		AllowBlank: true,
+		Type:       convDataValidationType(t),
+		Operator:   operator,
+		Formula1:   formula1,
+	}
+	if o == DataValidationOperatorBetween || o == DataValidationOperatorNotBetween {
+		if swap {
+			formula1, formula2 = formula2, formula1
+		}
+		dd.Formula1 = formula1
+		dd.Formula2 = formula2
+	}
+	return dd, nil
+}
+
 // convDataValidationType get excel data validation type
 func convDataValidationType(t DataValidationType) string {
 	typeMap := map[DataValidationType]string{
@@ -3,6 +3,7 @@ package xlsx
 import (
 	"bytes"
 	"fmt"
+	"math"
 	"testing"
 
 	qt "github.com/frankban/quicktest"
@@ -264,3 +265,76 @@ func TestDataValidation2(t *testing.T) {
 	c.Assert(dd.Formula1, qt.Equals, expectedFormula)
 	c.Assert(dd.Type, qt.Equals, "list")
 }
+
+func TestNumberValidation(t *testing.T) {
+	c := qt.New(t)
+
+	c.Run("Formulas", func(c *qt.C) {
+		dd, err := NewWholeNumberValidation(DataValidationOperatorBetween, 10, 1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(dd.Type, qt.Equals, "whole")
+		c.Assert(dd.Operator, qt.Equals, "between")
+		c.Assert(dd.Formula1, qt.Equals, "1")
+		c.Assert(dd.Formula2, qt.Equals, "10")
+
+		dd, err = NewDecimalValidation(DataValidationOperatorGreaterThan, 0.5, 100)
+		c.Assert(err, qt.IsNil)
+		c.Assert(dd.Type, qt.Equals, "decimal")
+		c.Assert(dd.Operator, qt.Equals, "greaterThan")
+		c.Assert(dd.Formula1, qt.Equals, "0.5")
+		c.Assert(dd.Formula2, qt.Equals, "")
+
+		_, err = NewWholeNumberValidation(DataValidationOperator(0), 1, 2)
+		c.Assert(err, qt.ErrorMatches, "unknown data validation operator 0")
+		_, err = NewDecimalValidation(DataValidationOperatorLessThan, math.Inf(1), 0)
+		c.Assert(err, qt.ErrorMatches, "the bounds of a decimal validation must be finite numbers")
+	})
+
+	c.Run("File", func(c *qt.C) {
+		file := NewFile()
+		sheet, err := file.AddSheet("Sheet1")
+		c.Assert(err, qt.IsNil)
+		dd, err := NewWholeNumberValidation(DataValidationOperatorBetween, 1, 10)
+		c.Assert(err, qt.IsNil)
+		title, msg := "Out of range", "Enter a number from 1 to 10"
+		dd.SetError(StyleStop, &title, &msg)
+		dd.SetInput(&title, &msg)
+		sheet.Cell(1, 1).SetInt(5)
+		sheet.Cell(1, 1).SetDataValidation(dd)
+		dd, err = NewDecimalValidation(DataValidationOperatorLessThanOrEqual, 2.5, 0)
+		c.Assert(err, qt.IsNil)
+		sheet.Cell(1, 2).SetFloat(1.5)
+		sheet.Cell(1, 2).SetDataValidation(dd)
+
+		parts, err := file.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		sheetXML := parts["xl/worksheets/sheet1.xml"]
+		c.Assert(sheetXML, qt.Contains, `<dataValidation allowBlank="true" showInputMessage="true" showErrorMessage="true" errorStyle="stop" errorTitle="Out of range" operator="between" error="Enter a number from 1 to 10" promptTitle="Out of range" prompt="Enter a number from 1 to 10" type="whole" sqref="B2"><formula1>1</formula1><formula2>10</formula2></dataValidation>`)
+		c.Assert(sheetXML, qt.Contains, `<dataValidation allowBlank="true" operator="lessThanOrEqual" type="decimal" sqref="C2"><formula1>2.5</formula1></dataValidation>`)
+
+		file, err = OpenBinary(zipParts(c, parts))
+		c.Assert(err, qt.IsNil)
+		validations := file.Sheets[0].DataValidations
+		c.Assert(validations, qt.HasLen, 2)
+		c.Assert(validations[0].Formula2, qt.Equals, "10")
+		c.Assert(*validations[0].Error, qt.Equals, msg)
+		c.Assert(validations[1].Formula2, qt.Equals, "")
+	})
+
+	c.Run("Stream", func(c *qt.C) {
+		buffer := bytes.NewBuffer(nil)
+		fileBuilder := NewStreamFileBuilder(buffer)
+		c.Assert(fileBuilder.AddStreamStyle(StreamStyleDefaultInteger), qt.IsNil)
+		c.Assert(fileBuilder.AddSheetS("Sheet1", []StreamStyle{StreamStyleDefaultInteger}), qt.IsNil)
+		dd, err := NewWholeNumberValidation(DataValidationOperatorGreaterThanOrEqual, 0, 0)
+		c.Assert(err, qt.IsNil)
+		c.Assert(fileBuilder.AddDataValidation(0, "A2:A1000", dd), qt.IsNil)
+		streamFile, err := fileBuilder.Build()
+		c.Assert(err, qt.IsNil)
+		c.Assert(streamFile.WriteS([]StreamCell{NewIntegerStreamCell(3)}), qt.IsNil)
+		c.Assert(streamFile.Close(), qt.IsNil)
+
+		sheetXML := unzipParts(c, buffer.Bytes())["xl/worksheets/sheet1.xml"]
+		c.Assert(sheetXML, qt.Contains, `<dataValidation allowBlank="true" operator="greaterThanOrEqual" type="whole" sqref="A2:A1000"><formula1>0</formula1></dataValidation>`)
+	})
+}
@@ -0,0 +1,19 @@
+package xlsx
+
+// readExtLst returns the content of an extension list, or an empty
+// string if there is none.
+func readExtLst(extLst *xlsxExtLst) string {
+	if extLst == nil {
+		return ""
+	}
+	return extLst.Content
+}
+
+// makeExtLst returns the extension list with the given content, or nil
+// if the content is empty, so that no extLst element is written.
+func makeExtLst(content string) *xlsxExtLst {
+	if content == "" {
+		return nil
+	}
+	return &xlsxExtLst{Content: content}
+}
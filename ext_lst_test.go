@@ -0,0 +1,43 @@
+package xlsx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestExtLst(t *testing.T) {
+	c := qt.New(t)
+
+	const (
+		cellExtLst  = `<ext uri="{1A2B3C4D-0000-0000-0000-000000000000}" xmlns:xyz="http://example.com/xyz"><xyz:note a="1">kept &amp; intact</xyz:note></ext>`
+		sheetExtLst = `<ext uri="{78C0D931-6437-407d-A8EE-F0AAD7539E65}" xmlns:x14="http://schemas.microsoft.com/office/spreadsheetml/2009/9/main"><x14:conditionalFormattings><x14:conditionalFormatting xmlns:xm="http://schemas.microsoft.com/office/excel/2006/main"><xm:sqref>A1:A3</xm:sqref></x14:conditionalFormatting></x14:conditionalFormattings></ext>`
+	)
+
+	file := NewFile()
+	sheet, err := file.AddSheet("Sheet1")
+	c.Assert(err, qt.IsNil)
+	sheet.Cell(0, 0).SetString("value")
+	sheet.Cell(1, 0).SetInt(2)
+	var buf bytes.Buffer
+	c.Assert(file.Write(&buf), qt.IsNil)
+	parts := unzipParts(c, buf.Bytes())
+	sheetXML := parts["xl/worksheets/sheet1.xml"]
+	c.Assert(sheetXML, qt.Contains, `<c r="A2"><v>2</v></c>`)
+	sheetXML = strings.Replace(sheetXML, `<c r="A2"><v>2</v></c>`, `<c r="A2"><v>2</v><extLst>`+cellExtLst+`</extLst></c>`, 1)
+	sheetXML = strings.Replace(sheetXML, `</worksheet>`, `<extLst>`+sheetExtLst+`</extLst></worksheet>`, 1)
+	parts["xl/worksheets/sheet1.xml"] = sheetXML
+
+	file, err = OpenBinary(zipParts(c, parts))
+	c.Assert(err, qt.IsNil)
+	c.Assert(file.Sheets[0].Cell(1, 0).Value, qt.Equals, "2")
+	buf.Reset()
+	c.Assert(file.Write(&buf), qt.IsNil)
+
+	sheetXML = unzipParts(c, buf.Bytes())["xl/worksheets/sheet1.xml"]
+	c.Assert(sheetXML, qt.Contains, `<v>2</v><extLst>`+cellExtLst+`</extLst></c>`)
+	c.Assert(sheetXML, qt.Contains, `<extLst>`+sheetExtLst+`</extLst></worksheet>`)
+	c.Assert(strings.Count(sheetXML, "<extLst>"), qt.Equals, 2)
+}
@@ -0,0 +1,144 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	externalLinkPathPrefix = "xl/externalLinks/externalLink"
+	externalLinkPathSuffix = ".xml"
+)
+
+// ExternalLink describes a reference from this workbook to another
+// workbook, as used by cross-workbook formulas.  Formulas refer to
+// external links by their one based position, so that "[1]Sheet1!A1"
+// refers to a cell in the workbook described by the first
+// ExternalLink.
+type ExternalLink struct {
+	// Target is the path or URL of the referenced workbook, as
+	// it was recorded when the link was created.
+	Target string
+	// Sheets holds the values Excel cached from each sheet of the
+	// referenced workbook.
+	Sheets []ExternalSheet
+}
+
+// ExternalSheet holds the cached values of a sheet in an externally
+// linked workbook.
+type ExternalSheet struct {
+	Name string
+	// Values maps cell references, e.g. "A1", to the cached value
+	// of that cell.
+	Values map[string]string
+}
+
+// ExternalLinks returns the external workbook links that were read
+// along with the File, in the order that formulas refer to them.
+func (f *File) ExternalLinks() []ExternalLink {
+	return f.externalLinks
+}
+
+// relsPathForPart returns the name of the file holding the
+// relationships of the part with the given name, e.g. the
+// relationships of "xl/worksheets/sheet1.xml" are stored in
+// "xl/worksheets/_rels/sheet1.xml.rels".
+func relsPathForPart(partName string) string {
+	dir, base := path.Split(partName)
+	return dir + "_rels/" + base + ".rels"
+}
+
+// readPartRelationsFromZipFile is an internal helper function to
+// extract the relationships stored in a .rels file within the XLSX
+// zip file.
+func readPartRelationsFromZipFile(f *zip.File) (*xlsxWorksheetRels, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	rels := new(xlsxWorksheetRels)
+	err = xml.NewDecoder(rc).Decode(rels)
+	if err != nil {
+		return nil, err
+	}
+	return rels, nil
+}
+
+// externalLinkIndex returns the number N of a part named
+// xl/externalLinks/externalLinkN.xml, or -1 if the name is not that
+// of an external link part.
+func externalLinkIndex(partName string) int {
+	if !strings.HasPrefix(partName, externalLinkPathPrefix) || !strings.HasSuffix(partName, externalLinkPathSuffix) {
+		return -1
+	}
+	index, err := strconv.Atoi(partName[len(externalLinkPathPrefix) : len(partName)-len(externalLinkPathSuffix)])
+	if err != nil {
+		return -1
+	}
+	return index
+}
+
+// readExternalLinksFromZipFile is an internal helper function that
+// reads every xl/externalLinks/externalLinkN.xml part in the XLSX zip
+// file, along with the relationship that names the linked workbook.
+func readExternalLinksFromZipFile(parts map[string]*zip.File) ([]ExternalLink, error) {
+	var names []string
+	for name := range parts {
+		if externalLinkIndex(name) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return externalLinkIndex(names[i]) < externalLinkIndex(names[j])
+	})
+
+	links := make([]ExternalLink, 0, len(names))
+	for _, name := range names {
+		rc, err := parts[name].Open()
+		if err != nil {
+			return nil, err
+		}
+		xLink := new(xlsxExternalLink)
+		err = xml.NewDecoder(rc).Decode(xLink)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		link := ExternalLink{}
+		if relsFile, ok := parts[relsPathForPart(name)]; ok {
+			rels, err := readPartRelationsFromZipFile(relsFile)
+			if err != nil {
+				return nil, err
+			}
+			for _, rel := range rels.Relationships {
+				if rel.Id == xLink.ExternalBook.RelationshipId {
+					link.Target = rel.Target
+					break
+				}
+			}
+		}
+
+		for _, sheetName := range xLink.ExternalBook.SheetNames {
+			link.Sheets = append(link.Sheets, ExternalSheet{Name: sheetName.Val, Values: map[string]string{}})
+		}
+		for _, sheetData := range xLink.ExternalBook.SheetDataSet {
+			if sheetData.SheetId < 0 || sheetData.SheetId >= len(link.Sheets) {
+				continue
+			}
+			values := link.Sheets[sheetData.SheetId].Values
+			for _, row := range sheetData.Row {
+				for _, cell := range row.Cell {
+					values[cell.R] = cell.V
+				}
+			}
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
@@ -0,0 +1,67 @@
+package xlsx
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestExternalLinks(t *testing.T) {
+	c := qt.New(t)
+
+	c.Run("RelsPathForPart", func(c *qt.C) {
+		c.Assert(relsPathForPart("xl/externalLinks/externalLink1.xml"), qt.Equals, "xl/externalLinks/_rels/externalLink1.xml.rels")
+	})
+
+	c.Run("ExternalLinkIndex", func(c *qt.C) {
+		c.Assert(externalLinkIndex("xl/externalLinks/externalLink12.xml"), qt.Equals, 12)
+		c.Assert(externalLinkIndex("xl/externalLinks/_rels/externalLink1.xml.rels"), qt.Equals, -1)
+		c.Assert(externalLinkIndex("xl/worksheets/sheet1.xml"), qt.Equals, -1)
+	})
+
+	c.Run("NoExternalLinks", func(c *qt.C) {
+		f := NewFile()
+		_, err := f.AddSheet("Sheet1")
+		c.Assert(err, qt.IsNil)
+		parts, err := f.MarshallParts()
+		c.Assert(err, qt.IsNil)
+
+		f, err = OpenBinary(zipParts(c, parts))
+		c.Assert(err, qt.IsNil)
+		c.Assert(f.ExternalLinks(), qt.HasLen, 0)
+	})
+
+	c.Run("ReadExternalLinks", func(c *qt.C) {
+		f := NewFile()
+		_, err := f.AddSheet("Sheet1")
+		c.Assert(err, qt.IsNil)
+		parts, err := f.MarshallParts()
+		c.Assert(err, qt.IsNil)
+
+		parts["xl/externalLinks/externalLink2.xml"] = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<externalLink xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><externalBook r:id="rId1"><sheetNames><sheetName val="Other"/></sheetNames></externalBook></externalLink>`
+		parts["xl/externalLinks/_rels/externalLink2.xml.rels"] = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/externalLinkPath" Target="Book3.xlsx" TargetMode="External"/></Relationships>`
+		parts["xl/externalLinks/externalLink1.xml"] = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<externalLink xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><externalBook r:id="rId1"><sheetNames><sheetName val="Prices"/><sheetName val="Totals"/></sheetNames><sheetDataSet><sheetData sheetId="0"><row r="1"><cell r="A1" t="s"><v>Apple</v></cell><cell r="B1"><v>1.5</v></cell></row></sheetData><sheetData sheetId="1"><row r="2"><cell r="C2"><v>42</v></cell></row></sheetData></sheetDataSet></externalBook></externalLink>`
+		parts["xl/externalLinks/_rels/externalLink1.xml.rels"] = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/externalLinkPath" Target="file:///C:/Book2.xlsx" TargetMode="External"/></Relationships>`
+
+		f, err = OpenBinary(zipParts(c, parts))
+		c.Assert(err, qt.IsNil)
+		links := f.ExternalLinks()
+		c.Assert(links, qt.HasLen, 2)
+
+		c.Assert(links[0].Target, qt.Equals, "file:///C:/Book2.xlsx")
+		c.Assert(links[0].Sheets, qt.HasLen, 2)
+		c.Assert(links[0].Sheets[0].Name, qt.Equals, "Prices")
+		c.Assert(links[0].Sheets[0].Values, qt.DeepEquals, map[string]string{"A1": "Apple", "B1": "1.5"})
+		c.Assert(links[0].Sheets[1].Name, qt.Equals, "Totals")
+		c.Assert(links[0].Sheets[1].Values, qt.DeepEquals, map[string]string{"C2": "42"})
+
+		c.Assert(links[1].Target, qt.Equals, "Book3.xlsx")
+		c.Assert(links[1].Sheets, qt.HasLen, 1)
+		c.Assert(links[1].Sheets[0].Name, qt.Equals, "Other")
+		c.Assert(links[1].Sheets[0].Values, qt.HasLen, 0)
+	})
+}
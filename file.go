@@ -8,8 +8,11 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"unicode"
 	"unicode/utf8"
 )
 
@@ -18,6 +21,7 @@ import (
 type File struct {
 	worksheets     map[string]*zip.File
 	worksheetRels  map[string]*zip.File
+	parts          map[string]*zip.File
 	referenceTable *RefTable
 	Date1904       bool
 	styles         *xlsxStyleSheet
@@ -25,10 +29,56 @@ type File struct {
 	Sheet          map[string]*Sheet
 	theme          *theme
 	DefinedNames   []*xlsxDefinedName
+	externalLinks  []ExternalLink
+	vbaProject     []byte
+	// persons holds the display names of the people who wrote the
+	// threaded comments, keyed by their ids.
+	persons map[string]string
+	// codeName is the name VBA code uses for the workbook.
+	codeName string
+	// pivotCacheSources are the ranges that pivot caches are written
+	// for.
+	pivotCacheSources []pivotCacheSource
+	// warnings describe the problems found in the file as it was
+	// read that did not stop it from being read.
+	warnings []string
+	// worksheetsByName are the parts of the worksheets that were
+	// read, keyed by the names of their sheets.
+	worksheetsByName map[string]*zip.File
+	// path is the name of the file the File was opened from, which
+	// StreamRows opens again as the file is closed once it is read.
+	path string
+	// zipClosed is set when the zip the File was read from has been
+	// closed, so that its parts can no longer be read.
+	zipClosed bool
+	// rowReaders are the iterators of StreamRows that are still
+	// reading their worksheets.
+	rowReadersLock sync.Mutex
+	rowReaders     map[*rowReader]struct{}
+	// calcChain is the calculation chain of the workbook as it was
+	// read.
+	calcChain []CalcChainCell
+	// WorkbookView sets the size of the window the workbook is shown
+	// in and the share of it given to the sheet tabs.  Without it the
+	// defaults are used.
+	WorkbookView *WorkbookView
 }
 
 const NoRowLimit int = -1
 
+// WorkbookView is how the window of a workbook is laid out when it is
+// opened.  Fields left at zero take their default values.
+type WorkbookView struct {
+	// TabRatio is the share of the width below the sheet, in
+	// thousandths, given to the sheet tabs rather than the horizontal
+	// scroll bar.
+	TabRatio int
+	// WindowWidth and WindowHeight are the size of the window, in
+	// twips.
+	WindowWidth  int
+	WindowHeight int
+}
+
 // Create a new File
 func NewFile() *File {
 	return &File{
@@ -52,7 +102,12 @@ func OpenFileWithRowLimit(fileName string, rowLimit int) (file *File, err error)
 	if err != nil {
 		return nil, err
 	}
-	return ReadZipWithRowLimit(z, rowLimit)
+	file, err = ReadZipWithRowLimit(z, rowLimit)
+	if err != nil {
+		return nil, err
+	}
+	file.path = fileName
+	return file, nil
 }
 
 // OpenBinary() take bytes of an XLSX file and returns a populated
@@ -84,6 +139,42 @@ func OpenReaderAtWithRowLimit(r io.ReaderAt, size int64, rowLimit int) (*File, e
 	return ReadZipReaderWithRowLimit(file, rowLimit)
 }
 
+// OpenReaderAtSheets() take io.ReaderAt of an XLSX file and returns a
+// populated xlsx.File struct for it, in which only the sheets named in
+// sheetNames have been parsed.  The remaining sheets are still present
+// in the File, but carry nothing more than their names and
+// visibility.  Shared strings are always read.
+func OpenReaderAtSheets(r io.ReaderAt, size int64, sheetNames []string) (*File, error) {
+	file, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	if sheetNames == nil {
+		sheetNames = []string{}
+	}
+	return readZipReader(file, NoRowLimit, sheetNames, 1)
+}
+
+// Options controls how OpenReaderAtWithOptions reads a file.
+type Options struct {
+	// Concurrency is the number of sheets that are parsed at the
+	// same time.  Values below 2 parse them one after another.
+	Concurrency int
+}
+
+// OpenReaderAtWithOptions takes an io.ReaderAt of an XLSX file and
+// returns a populated xlsx.File struct for it, read as the options
+// say.  Parsing the sheets concurrently speeds up opening files with
+// many large sheets; the sheets are still in their order in the
+// workbook in File.Sheets.
+func OpenReaderAtWithOptions(r io.ReaderAt, size int64, opts Options) (*File, error) {
+	file, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return readZipReader(file, NoRowLimit, nil, opts.Concurrency)
+}
+
 // A convenient wrapper around File.ToSlice, FileToSlice will
 // return the raw data contained in an Excel XLSX file as three
 // dimensional slice.  The first index represents the sheet number,
@@ -181,6 +272,108 @@ func (f *File) AddSheet(sheetName string) (*Sheet, error) {
 	return sheet, nil
 }
 
+// Warnings returns the problems found in the file as it was read that
+// did not stop it from being read, such as counts in the file that do
+// not match what it holds.  They suggest that the file may be corrupt.
+func (f *File) Warnings() []string {
+	return f.warnings
+}
+
+// addWarning records a problem found in reading the file.
+func (f *File) addWarning(warning string) {
+	f.warnings = append(f.warnings, warning)
+}
+
+// GetDefinedName returns what the defined name, such as a named
+// range, refers to, such as "Sheet1!$A$1:$B$10".  Names are matched
+// without regard to case, as Excel does.  A name defined for the whole
+// workbook is preferred over one defined for a single sheet.
+func (f *File) GetDefinedName(name string) (string, bool) {
+	var local *xlsxDefinedName
+	for _, dn := range f.DefinedNames {
+		if !strings.EqualFold(dn.Name, name) {
+			continue
+		}
+		if dn.LocalSheetID == nil {
+			return dn.Data, true
+		}
+		if local == nil {
+			local = dn
+		}
+	}
+	if local == nil {
+		return "", false
+	}
+	return local.Data, true
+}
+
+// AddDefinedName defines name, such as a named range, to refer to
+// refersTo, such as "Sheet1!$A$1:$B$10".  A scope of -1 defines the
+// name for the whole workbook, and any other scope for the sheet at
+// that (zero based) index alone.  Names follow the rules Excel has
+// for them: they start with a letter, an underscore or a backslash,
+// hold no spaces, and cannot be taken for a cell reference such as
+// "A1" or "R1C1".
+func (f *File) AddDefinedName(name, refersTo string, scope int) error {
+	if err := checkDefinedName(name); err != nil {
+		return err
+	}
+	if refersTo == "" {
+		return fmt.Errorf("the defined name %q refers to nothing", name)
+	}
+	var localSheetID *int
+	if scope != -1 {
+		if scope < 0 || scope >= len(f.Sheets) {
+			return errors.New("sheet index out of range")
+		}
+		localSheetID = &scope
+	}
+	for _, dn := range f.DefinedNames {
+		if !strings.EqualFold(dn.Name, name) {
+			continue
+		}
+		if (dn.LocalSheetID == nil && localSheetID == nil) ||
+			(dn.LocalSheetID != nil && localSheetID != nil && *dn.LocalSheetID == *localSheetID) {
+			return fmt.Errorf("the name %q is already defined", name)
+		}
+	}
+	f.DefinedNames = append(f.DefinedNames, &xlsxDefinedName{
+		Name:         name,
+		Data:         refersTo,
+		LocalSheetID: localSheetID,
+	})
+	return nil
+}
+
+// cellReferenceName matches the names that Excel takes for a cell
+// reference, in either the A1 or the R1C1 style, rather than for a
+// defined name.
+var cellReferenceName = regexp.MustCompile(`^(?i:[a-z]{1,3}[0-9]+|r[0-9]*c?[0-9]*|c[0-9]*)$`)
+
+// checkDefinedName returns an error if name cannot be used as a
+// defined name.
+func checkDefinedName(name string) error {
+	if name == "" {
+		return errors.New("a defined name cannot be empty")
+	}
+	if utf8.RuneCountInString(name) > 255 {
+		return fmt.Errorf("the defined name %q is longer than 255 characters", name)
+	}
+	for i, r := range name {
+		if unicode.IsLetter(r) || r == '_' || r == '\\' {
+			continue
+		}
+		if i > 0 && (unicode.IsDigit(r) || r == '.' || r == '?') {
+			continue
+		}
+		return fmt.Errorf("the defined name %q cannot hold %q there", name, r)
+	}
+	if cellReferenceName.MatchString(name) {
+		return fmt.Errorf("the defined name %q looks like a cell reference", name)
+	}
+	return nil
+}
+
 // Appends an existing Sheet, with the provided name, to a File
 func (f *File) AppendSheet(sheet Sheet, sheetName string) (*Sheet, error) {
 	if _, exists := f.Sheet[sheetName]; exists {
@@ -195,24 +388,37 @@ func (f *File) AppendSheet(sheet Sheet, sheetName string) (*Sheet, error) {
 }
 
 func (f *File) makeWorkbook() xlsxWorkbook {
+	tabRatio, windowWidth, windowHeight := 204, 16384, 8192
+	if view := f.WorkbookView; view != nil {
+		if view.TabRatio != 0 {
+			tabRatio = view.TabRatio
+		}
+		if view.WindowWidth != 0 {
+			windowWidth = view.WindowWidth
+		}
+		if view.WindowHeight != 0 {
+			windowHeight = view.WindowHeight
+		}
+	}
 	return xlsxWorkbook{
 		FileVersion: xlsxFileVersion{AppName: "Go XLSX"},
-		WorkbookPr:  xlsxWorkbookPr{ShowObjects: "all"},
+		WorkbookPr:  xlsxWorkbookPr{ShowObjects: "all", CodeName: f.codeName, Date1904: f.Date1904},
 		BookViews: xlsxBookViews{
 			WorkBookView: []xlsxWorkBookView{
 				{
 					ShowHorizontalScroll: true,
 					ShowSheetTabs:        true,
 					ShowVerticalScroll:   true,
-					TabRatio:             204,
-					WindowHeight:         8192,
-					WindowWidth:          16384,
+					TabRatio:             tabRatio,
+					WindowHeight:         windowHeight,
+					WindowWidth:          windowWidth,
 					XWindow:              "0",
 					YWindow:              "0",
 				},
 			},
 		},
-		Sheets: xlsxSheets{Sheet: make([]xlsxSheet, len(f.Sheets))},
+		Sheets:       xlsxSheets{Sheet: make([]xlsxSheet, len(f.Sheets))},
+		DefinedNames: f.makeDefinedNames(),
 		CalcPr: xlsxCalcPr{
 			IterateCount: 100,
 			RefMode:      "A1",
@@ -222,6 +428,20 @@ func (f *File) makeWorkbook() xlsxWorkbook {
 	}
 }
 
+// makeDefinedNames returns the definedNames element of the workbook.
+// Names defined for sheets that are no longer in the File are left
+// out.
+func (f *File) makeDefinedNames() xlsxDefinedNames {
+	var definedNames xlsxDefinedNames
+	for _, dn := range f.DefinedNames {
+		if dn.LocalSheetID != nil && (*dn.LocalSheetID < 0 || *dn.LocalSheetID >= len(f.Sheets)) {
+			continue
+		}
+		definedNames.DefinedName = append(definedNames.DefinedName, *dn)
+	}
+	return definedNames
+}
+
 // Some tools that read XLSX files have very strict requirements about
 // the structure of the input XML.  In particular both Numbers on the Mac
 // and SAS dislike inline XML namespace declarations, or namespace
@@ -248,6 +468,14 @@ func addRelationshipNameSpaceToWorksheet(worksheetMarshal string) string {
 	oldHyperlink := `<hyperlink id=`
 	newHyperlink := `<hyperlink r:id=`
 	newSheetMarshall = strings.Replace(newSheetMarshall, oldHyperlink, newHyperlink, -1)
+
+	oldLegacyDrawing := `<legacyDrawing id=`
+	newLegacyDrawing := `<legacyDrawing r:id=`
+	newSheetMarshall = strings.Replace(newSheetMarshall, oldLegacyDrawing, newLegacyDrawing, -1)
+
+	oldPicture := `<picture id=`
+	newPicture := `<picture r:id=`
+	newSheetMarshall = strings.Replace(newSheetMarshall, oldPicture, newPicture, -1)
 	return newSheetMarshall
 }
 
@@ -273,6 +501,9 @@ func (f *File) MarshallParts() (map[string]string, error) {
 	parts = make(map[string]string)
 	workbook = f.makeWorkbook()
 	sheetIndex := 1
+	// shapeBlock is the next block of VML shape ids that no sheet has
+	// taken yet.
+	shapeBlock := 1
 
 	if f.styles == nil {
 		f.styles = newXlsxStyleSheet(f.theme)
@@ -282,6 +513,13 @@ func (f *File) MarshallParts() (map[string]string, error) {
 		err := errors.New("Workbook must contains atleast one worksheet")
 		return nil, err
 	}
+	visible := false
+	for _, sheet := range f.Sheets {
+		visible = visible || !sheet.Hidden
+	}
+	if !visible {
+		return nil, errors.New("at least one sheet of the workbook has to be visible")
+	}
 	for _, sheet := range f.Sheets {
 		xSheetRels := sheet.makeXLSXSheetRelations()
 		xSheet := sheet.makeXLSXSheet(refTable, f.styles, xSheetRels)
@@ -300,7 +538,55 @@ func (f *File) MarshallParts() (map[string]string, error) {
 			Name:    sheet.Name,
 			SheetId: sheetId,
 			Id:      rId,
-			State:   "visible"}
+			State:   string(sheet.State())}
+
+		if xComments := sheet.makeXLSXComments(); xComments != nil {
+			commentsPath := fmt.Sprintf("comments%d.xml", sheetIndex)
+			vmlPath := fmt.Sprintf("drawings/vmlDrawing%d.vml", sheetIndex)
+			if xSheetRels == nil {
+				xSheetRels = &xlsxWorksheetRels{XMLName: xml.Name{Local: "Relationships"}}
+			}
+			xSheetRels.Relationships = append(xSheetRels.Relationships, xlsxWorksheetRelation{
+				Id:     fmt.Sprintf("rId%d", len(xSheetRels.Relationships)+1),
+				Type:   RelationshipTypeComments,
+				Target: "../" + commentsPath})
+			vmlRelId := fmt.Sprintf("rId%d", len(xSheetRels.Relationships)+1)
+			xSheetRels.Relationships = append(xSheetRels.Relationships, xlsxWorksheetRelation{
+				Id:     vmlRelId,
+				Type:   RelationshipTypeVMLDrawing,
+				Target: "../" + vmlPath})
+			xSheet.LegacyDrawing = &xlsxLegacyDrawing{RelationshipId: vmlRelId}
+
+			parts["xl/"+commentsPath], err = marshal(xComments)
+			if err != nil {
+				return parts, err
+			}
+			parts["xl/"+vmlPath], shapeBlock, err = makeVMLDrawing(sheet, xComments, shapeBlock)
+			if err != nil {
+				return parts, err
+			}
+			types.Overrides = append(
+				types.Overrides,
+				xlsxOverride{
+					PartName:    "/xl/" + commentsPath,
+					ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.comments+xml"})
+			types.addDefault("vml", "application/vnd.openxmlformats-officedocument.vmlDrawing")
+		}
+
+		if sheet.background != nil {
+			imagePath := fmt.Sprintf("media/background%d.%s", sheetIndex, sheet.background.format)
+			if xSheetRels == nil {
+				xSheetRels = &xlsxWorksheetRels{XMLName: xml.Name{Local: "Relationships"}}
+			}
+			imageRelId := fmt.Sprintf("rId%d", len(xSheetRels.Relationships)+1)
+			xSheetRels.Relationships = append(xSheetRels.Relationships, xlsxWorksheetRelation{
+				Id:     imageRelId,
+				Type:   RelationshipTypeImage,
+				Target: "../" + imagePath})
+			xSheet.Picture = &xlsxPicture{RelationshipId: imageRelId}
+			parts["xl/"+imagePath] = string(sheet.background.data)
+			types.addDefault(sheet.background.format, backgroundContentTypes[sheet.background.format])
+		}
 
 		worksheetMarshal, err := marshal(xSheet)
 		if err != nil {
@@ -317,16 +603,6 @@ func (f *File) MarshallParts() (map[string]string, error) {
 		sheetIndex++
 	}
 
-	workbookMarshal, err := marshal(workbook)
-	if err != nil {
-		return parts, err
-	}
-	workbookMarshal = replaceRelationshipsNameSpace(workbookMarshal)
-	parts["xl/workbook.xml"] = workbookMarshal
-	if err != nil {
-		return parts, err
-	}
-
 	parts["_rels/.rels"] = TEMPLATE__RELS_DOT_RELS
 	parts["docProps/app.xml"] = TEMPLATE_DOCPROPS_APP
 	// TODO - do this properly, modification and revision information
@@ -340,6 +616,49 @@ func (f *File) MarshallParts() (map[string]string, error) {
 	}
 
 	xWRel := workbookRels.MakeXLSXWorkbookRels()
+	if f.vbaProject != nil {
+		xWRel.Relationships = append(xWRel.Relationships, xlsxWorkbookRelation{
+			Id:     fmt.Sprintf("rId%d", len(xWRel.Relationships)+1),
+			Target: "vbaProject.bin",
+			Type:   vbaProjectRelationshipType})
+		parts[vbaProjectPath] = string(f.vbaProject)
+		types.addDefault("bin", vbaProjectContentType)
+		for i := range types.Overrides {
+			if types.Overrides[i].PartName == "/xl/workbook.xml" {
+				types.Overrides[i].ContentType = macroEnabledWorkbookContentType
+			}
+		}
+	}
+	for i, source := range f.pivotCacheSources {
+		relId := fmt.Sprintf("rId%d", len(xWRel.Relationships)+1)
+		path := pivotCachePath(i + 1)
+		xWRel.Relationships = append(xWRel.Relationships, xlsxWorkbookRelation{
+			Id:     relId,
+			Target: strings.TrimPrefix(path, "xl/"),
+			Type:   pivotCacheDefinitionRelationshipType})
+		if workbook.PivotCaches == nil {
+			workbook.PivotCaches = &xlsxPivotCaches{}
+		}
+		workbook.PivotCaches.PivotCache = append(workbook.PivotCaches.PivotCache, xlsxPivotCache{CacheId: i + 1, Id: relId})
+		parts[path], err = marshal(source.makeXLSXPivotCacheDefinition(source.headings()))
+		if err != nil {
+			return parts, err
+		}
+		types.Overrides = append(types.Overrides, xlsxOverride{
+			PartName:    "/" + path,
+			ContentType: pivotCacheDefinitionContentType,
+		})
+	}
+
+	workbookMarshal, err := marshal(workbook)
+	if err != nil {
+		return parts, err
+	}
+	workbookMarshal = replaceRelationshipsNameSpace(workbookMarshal)
+	parts["xl/workbook.xml"] = workbookMarshal
+	if err != nil {
+		return parts, err
+	}
 
 	parts["xl/_rels/workbook.xml.rels"], err = marshal(xWRel)
 	if err != nil {
@@ -1,11 +1,16 @@
 package xlsx
 
 import (
+	"bytes"
 	"encoding/xml"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	qt "github.com/frankban/quicktest"
 	. "gopkg.in/check.v1"
@@ -125,6 +130,43 @@ func (l *FileSuite) TestPartialReadsWithFewerRowsThanRequested(c *C) {
 	}
 }
 
+func (l *FileSuite) TestOpenReaderAtSheetsOnlyParsesNamedSheets(c *C) {
+	f, err := os.Open("testdocs/testfile.xlsx")
+	c.Assert(err, IsNil)
+	defer f.Close()
+	info, err := f.Stat()
+	c.Assert(err, IsNil)
+
+	file, err := OpenReaderAtSheets(f, info.Size(), []string{"Tabelle1"})
+	c.Assert(err, IsNil)
+	c.Assert(file.Sheets, HasLen, 3)
+	c.Assert(file.Sheets[0].Name, Equals, "Tabelle1")
+	c.Assert(file.Sheets[0].Rows, HasLen, 2)
+	c.Assert(file.Sheets[1].Name, Equals, "Tabelle2")
+	c.Assert(file.Sheets[1].Rows, HasLen, 0)
+	c.Assert(file.Sheets[2].Name, Equals, "Tabelle3")
+	c.Assert(file.Sheets[2].Rows, HasLen, 0)
+	c.Assert(file.Sheet["Tabelle2"], Equals, file.Sheets[1])
+
+	// Shared strings are still read for the parsed sheet.
+	c.Assert(file.Sheets[0].Rows[0].Cells[0].Value, Equals, "Foo")
+}
+
+func (l *FileSuite) TestOpenReaderAtSheetsWithNoNames(c *C) {
+	f, err := os.Open("testdocs/testfile.xlsx")
+	c.Assert(err, IsNil)
+	defer f.Close()
+	info, err := f.Stat()
+	c.Assert(err, IsNil)
+
+	file, err := OpenReaderAtSheets(f, info.Size(), nil)
+	c.Assert(err, IsNil)
+	c.Assert(file.Sheets, HasLen, 3)
+	for _, sheet := range file.Sheets {
+		c.Assert(sheet.Rows, HasLen, 0)
+	}
+}
+
 func (l *FileSuite) TestOpenFileWithoutStyleAndSharedStrings(c *C) {
 	var xlsxFile *File
 	var error error
@@ -1082,3 +1124,171 @@ func (s *SliceReaderSuite) TestFileWithEmptyCols(c *C) {
 		c.Assert(val, Equals, "C1")
 	}
 }
+
+func TestGetDefinedName(t *testing.T) {
+	c := qt.New(t)
+
+	f := NewFile()
+	_, err := f.AddSheet("Sheet1")
+	c.Assert(err, qt.IsNil)
+	_, err = f.AddSheet("Sheet2")
+	c.Assert(err, qt.IsNil)
+	parts, err := f.MarshallParts()
+	c.Assert(err, qt.IsNil)
+	c.Assert(parts["xl/workbook.xml"], qt.Contains, "<definedNames></definedNames>")
+	parts["xl/workbook.xml"] = strings.Replace(parts["xl/workbook.xml"], "<definedNames></definedNames>", `<definedNames>`+
+		`<definedName name="_xlnm._FilterDatabase" localSheetId="0" hidden="1">Sheet1!$A$1:$C$20</definedName>`+
+		`<definedName name="Rate" localSheetId="1">Sheet2!$B$1</definedName>`+
+		`<definedName name="Rate">Sheet1!$B$1</definedName>`+
+		`<definedName name="Regions">Sheet1!$A$2:$A$20</definedName>`+
+		`<definedName name="Tax" localSheetId="1">Sheet2!$C$1</definedName>`+
+		`<definedName name="Macro" function="1" vbProcedure="1" functionGroupId="14">Macro1!$A$1</definedName>`+
+		`</definedNames>`, 1)
+
+	f, err = OpenBinary(zipParts(c, parts))
+	c.Assert(err, qt.IsNil)
+	c.Assert(f.DefinedNames, qt.HasLen, 6)
+	c.Assert(f.DefinedNames[0].Hidden, qt.Equals, true)
+	c.Assert(*f.DefinedNames[0].LocalSheetID, qt.Equals, 0)
+	c.Assert(f.DefinedNames[3].LocalSheetID, qt.IsNil)
+	c.Assert(f.DefinedNames[5].Function, qt.Equals, true)
+
+	for _, test := range []struct {
+		name     string
+		refersTo string
+		ok       bool
+	}{
+		{"Regions", "Sheet1!$A$2:$A$20", true},
+		{"regions", "Sheet1!$A$2:$A$20", true},
+		{"Rate", "Sheet1!$B$1", true},
+		{"Tax", "Sheet2!$C$1", true},
+		{"_xlnm._FilterDatabase", "Sheet1!$A$1:$C$20", true},
+		{"Macro", "Macro1!$A$1", true},
+		{"Missing", "", false},
+	} {
+		refersTo, ok := f.GetDefinedName(test.name)
+		c.Assert(ok, qt.Equals, test.ok, qt.Commentf(test.name))
+		c.Assert(refersTo, qt.Equals, test.refersTo, qt.Commentf(test.name))
+	}
+}
+
+func TestAddDefinedName(t *testing.T) {
+	c := qt.New(t)
+
+	f := NewFile()
+	_, err := f.AddSheet("Sheet1")
+	c.Assert(err, qt.IsNil)
+	_, err = f.AddSheet("Sheet2")
+	c.Assert(err, qt.IsNil)
+
+	for _, test := range []struct {
+		name string
+		err  string
+	}{
+		{"", "a defined name cannot be empty"},
+		{"Sales Total", `the defined name "Sales Total" cannot hold ' ' there`},
+		{"1stQuarter", `the defined name "1stQuarter" cannot hold '1' there`},
+		{"A1", `the defined name "A1" looks like a cell reference`},
+		{"xfd1048576", `the defined name "xfd1048576" looks like a cell reference`},
+		{"R1C1", `the defined name "R1C1" looks like a cell reference`},
+		{"r", `the defined name "r" looks like a cell reference`},
+		{"C", `the defined name "C" looks like a cell reference`},
+		{strings.Repeat("a", 256), `the defined name "a+" is longer than 255 characters`},
+	} {
+		c.Assert(f.AddDefinedName(test.name, "Sheet1!$A$1", -1), qt.ErrorMatches, test.err)
+	}
+	c.Assert(f.AddDefinedName("Rate", "", -1), qt.ErrorMatches, `the defined name "Rate" refers to nothing`)
+	c.Assert(f.AddDefinedName("Rate", "Sheet1!$A$1", 2), qt.ErrorMatches, "sheet index out of range")
+	c.Assert(f.AddDefinedName("Rate", "Sheet1!$A$1", -2), qt.ErrorMatches, "sheet index out of range")
+
+	c.Assert(f.AddDefinedName("Rate", "Sheet1!$A$1", -1), qt.IsNil)
+	c.Assert(f.AddDefinedName("RATE", "Sheet1!$A$2", -1), qt.ErrorMatches, `the name "RATE" is already defined`)
+	c.Assert(f.AddDefinedName("Rate", "Sheet2!$A$1", 1), qt.IsNil)
+	c.Assert(f.AddDefinedName("Rate", "Sheet2!$A$2", 1), qt.ErrorMatches, `the name "Rate" is already defined`)
+	c.Assert(f.AddDefinedName("_Sales.Q1", "Sheet1!$B$2:$B$10", 0), qt.IsNil)
+	c.Assert(f.AddDefinedName(`\Total`, "SUM(Sheet1!$B$2:$B$10)", -1), qt.IsNil)
+	c.Assert(f.AddDefinedName("Réseau", "Sheet1!$C$1", -1), qt.IsNil)
+
+	parts, err := f.MarshallParts()
+	c.Assert(err, qt.IsNil)
+	c.Assert(parts["xl/workbook.xml"], qt.Contains, `<definedNames><definedName name="Rate">Sheet1!$A$1</definedName><definedName name="Rate" localSheetId="1">Sheet2!$A$1</definedName><definedName name="_Sales.Q1" localSheetId="0">Sheet1!$B$2:$B$10</definedName>`)
+
+	f, err = OpenBinary(zipParts(c, parts))
+	c.Assert(err, qt.IsNil)
+	c.Assert(f.DefinedNames, qt.HasLen, 5)
+	refersTo, ok := f.GetDefinedName("rate")
+	c.Assert(ok, qt.Equals, true)
+	c.Assert(refersTo, qt.Equals, "Sheet1!$A$1")
+	refersTo, ok = f.GetDefinedName(`\Total`)
+	c.Assert(ok, qt.Equals, true)
+	c.Assert(refersTo, qt.Equals, "SUM(Sheet1!$B$2:$B$10)")
+}
+
+func TestOpenReaderAtWithOptions(t *testing.T) {
+	c := qt.New(t)
+
+	f := NewFile()
+	for i := 0; i < 6; i++ {
+		sheet, err := f.AddSheet(fmt.Sprintf("Sheet %d", i+1))
+		c.Assert(err, qt.IsNil)
+		for row := 0; row < 50; row++ {
+			sheet.Cell(row, 0).SetString(fmt.Sprintf("row %d of sheet %d", row, i+1))
+			sheet.Cell(row, 1).SetFloatWithFormat(float64(row*i)/3, "0.00")
+			sheet.Cell(row, 2).SetDate(time.Date(2020, 1, row+1, 0, 0, 0, 0, time.UTC))
+		}
+		sheet.Cell(0, 3).SetComment("Ann", "note")
+	}
+	var buf bytes.Buffer
+	c.Assert(f.Write(&buf), qt.IsNil)
+	r := bytes.NewReader(buf.Bytes())
+
+	want, err := OpenReaderAt(r, r.Size())
+	c.Assert(err, qt.IsNil)
+	wantSlice, err := want.ToSlice()
+	c.Assert(err, qt.IsNil)
+	for _, concurrency := range []int{0, 1, 4, 10} {
+		got, err := OpenReaderAtWithOptions(r, r.Size(), Options{Concurrency: concurrency})
+		c.Assert(err, qt.IsNil)
+		c.Assert(got.Sheets, qt.HasLen, 6)
+		for i, sheet := range got.Sheets {
+			c.Assert(sheet.Name, qt.Equals, fmt.Sprintf("Sheet %d", i+1))
+			c.Assert(got.Sheet[sheet.Name], qt.Equals, sheet)
+			c.Assert(sheet.Cell(0, 3).Comment.Text, qt.Equals, "note")
+		}
+		gotSlice, err := got.ToSlice()
+		c.Assert(err, qt.IsNil)
+		c.Assert(gotSlice, qt.DeepEquals, wantSlice)
+	}
+}
+
+func TestCalcChain(t *testing.T) {
+	c := qt.New(t)
+
+	file, err := OpenFile("./testdocs/testcelltypes.xlsx")
+	c.Assert(err, qt.IsNil)
+	c.Assert(file.CalcChain(), qt.DeepEquals, []CalcChainCell{
+		{Sheet: "Sheet1", Ref: "A8"},
+		{Sheet: "Sheet1", Ref: "A7"},
+	})
+
+	// A cell without a sheet id is on the sheet of the cell before it.
+	data, err := ioutil.ReadFile("./testdocs/testcelltypes.xlsx")
+	c.Assert(err, qt.IsNil)
+	parts := unzipParts(c, data)
+	parts["xl/calcChain.xml"] = `<calcChain xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<c r="B2" i="1"/><c r="B1"/><c r="C1" i="9"/><c r="C2"/><c r="A1" i="1"/></calcChain>`
+	file, err = OpenBinary(zipParts(c, parts))
+	c.Assert(err, qt.IsNil)
+	c.Assert(file.CalcChain(), qt.DeepEquals, []CalcChainCell{
+		{Sheet: "Sheet1", Ref: "B2"},
+		{Sheet: "Sheet1", Ref: "B1"},
+		{Sheet: "Sheet1", Ref: "A1"},
+	})
+	c.Assert(file.Warnings(), qt.HasLen, 2)
+
+	// Files without a calculation chain have none.
+	file = NewFile()
+	_, err = file.AddSheet("Sheet1")
+	c.Assert(err, qt.IsNil)
+	c.Assert(file.CalcChain(), qt.IsNil)
+}
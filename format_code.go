@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"regexp"
 	"strconv"
 	"strings"
 )
@@ -29,8 +30,19 @@ type formatOptions struct {
 	reducedFormatString string
 	prefix              string
 	suffix              string
+	// fractionDigits is the number of digits of the denominator of
+	// a fraction format, such as 2 for "# ??/??", or 0 if the format
+	// does not show a fraction.
+	fractionDigits int
+	// mixedFraction is set for fraction formats that show the whole
+	// part of the number apart from the fraction, as in 1 1/2.
+	mixedFraction bool
 }
 
+// fractionFormat matches the fraction formats that are supported, such
+// as "# ?/?" and "??/??".
+var fractionFormat = regexp.MustCompile(`^([#0]+ +)?\?+/(\?+)$`)
+
 // FormatValue returns a value, and possibly an error condition
 // from a Cell.  If it is possible to apply a format to the cell
 // value, it will do so, if not then an error will be returned, along
@@ -121,7 +133,7 @@ func (fullFormat *parsedNumberFormat) formatNumericCell(cell *Cell) (string, err
 	}
 
 	if fullFormat.isTimeFormat {
-		return fullFormat.parseTime(rawValue, cell.date1904)
+		return fullFormat.parseTime(rawValue, cell.isDate1904())
 	}
 	var numberFormat *formatOptions
 	floatVal, floatErr := strconv.ParseFloat(rawValue, 64)
@@ -153,16 +165,34 @@ func (fullFormat *parsedNumberFormat) formatNumericCell(cell *Cell) (string, err
 		floatVal = 100 * floatVal
 	}
 
+	if numberFormat.fractionDigits > 0 {
+		return formatFraction(floatVal, numberFormat.fractionDigits, numberFormat.mixedFraction), nil
+	}
+
+	// Commas at the end of the number format scale the number down by a thousand each, so "#,##0," shows
+	// 1234567 as "1,235". A comma between digit placeholders instead asks for thousands separators, which Go fmt
+	// cannot add, so they are stripped here and put back in once the number has been formatted.
+	reducedFormat := numberFormat.reducedFormatString
+	if reducedFormat != builtInNumFmt[builtInNumFmtIndex_GENERAL] && reducedFormat != builtInNumFmt[builtInNumFmtIndex_STRING] {
+		scaledFormat := strings.TrimRight(reducedFormat, ",")
+		for i := len(scaledFormat); i < len(reducedFormat); i++ {
+			floatVal = floatVal / 1000
+		}
+		reducedFormat = scaledFormat
+	}
+	showThousands := strings.Contains(reducedFormat, ",")
+	if showThousands {
+		reducedFormat = strings.Replace(reducedFormat, "#,##", "", 1)
+	}
+
 	// Only the most common format strings are supported here.
 	// Eventually this switch needs to be replaced with a more general solution.
-	// Some of these "supported" formats should have thousand separators, but don't get them since Go fmt
-	// doesn't have a way to request thousands separators.
 	// The only things that should be supported here are in the array formattingCharacters,
 	// everything else has been stripped out before and will be placed in the prefix or suffix.
 	// The formatting characters can have non-formatting characters mixed in with them and those should be maintained.
 	// However, at this time we fail to parse those formatting codes and they get replaced with "General"
 	var formattedNum string
-	switch numberFormat.reducedFormatString {
+	switch reducedFormat {
 	case builtInNumFmt[builtInNumFmtIndex_GENERAL]: // General is literally "general"
 		// prefix, showPercent, and suffix cannot apply to the general format
 		// The logic for showing numbers when the format is "general" is much more complicated than the rest of these.
@@ -173,27 +203,94 @@ func (fullFormat *parsedNumberFormat) formatNumericCell(cell *Cell) (string, err
 		return generalFormatted, nil
 	case builtInNumFmt[builtInNumFmtIndex_STRING]: // String is "@"
 		formattedNum = cell.Value
-	case builtInNumFmt[builtInNumFmtIndex_INT], "#,##0": // Int is "0"
+	case builtInNumFmt[builtInNumFmtIndex_INT]: // Int is "0"
 		// Previously this case would cast to int and print with %d, but that will not round the value correctly.
 		formattedNum = fmt.Sprintf("%.0f", floatVal)
-	case "0.0", "#,##0.0":
+	case "0.0":
 		formattedNum = fmt.Sprintf("%.1f", floatVal)
-	case builtInNumFmt[builtInNumFmtIndex_FLOAT], "#,##0.00": // Float is "0.00"
+	case builtInNumFmt[builtInNumFmtIndex_FLOAT]: // Float is "0.00"
 		formattedNum = fmt.Sprintf("%.2f", floatVal)
-	case "0.000", "#,##0.000":
+	case "0.000":
 		formattedNum = fmt.Sprintf("%.3f", floatVal)
-	case "0.0000", "#,##0.0000":
+	case "0.0000":
 		formattedNum = fmt.Sprintf("%.4f", floatVal)
 	case "0.00e+00", "##0.0e+0":
 		formattedNum = fmt.Sprintf("%e", floatVal)
+		showThousands = false
 	case "":
 		// Do nothing.
 	default:
-		return rawValue, nil
+		// A section made of only "?" placeholders, such as the zero section of the accounting formats, shows no
+		// digits, just the space they would take up.
+		if strings.Trim(reducedFormat, "?") != "" {
+			return rawValue, nil
+		}
+		formattedNum = strings.Repeat(" ", len(reducedFormat))
+	}
+	if showThousands {
+		formattedNum = addThousandsSeparators(formattedNum)
 	}
 	return numberFormat.prefix + formattedNum + numberFormat.suffix, nil
 }
 
+// formatFraction shows value as the fraction closest to it whose
+// denominator has at most digits digits.  With mixed set the whole part
+// is shown apart, as in "1 1/2", rather than in the numerator, as in
+// "3/2".  The padding that the "?" placeholders ask for is left out.
+func formatFraction(value float64, digits int, mixed bool) string {
+	sign := ""
+	if value < 0 {
+		sign, value = "-", -value
+	}
+	whole := math.Floor(value)
+	part := value - whole
+	maxDenominator := int(math.Pow(10, float64(digits))) - 1
+	numerator, denominator := 0, 1
+	bestDiff := part
+	for d := 1; d <= maxDenominator && bestDiff > 0; d++ {
+		n := int(math.Round(part * float64(d)))
+		if diff := math.Abs(part - float64(n)/float64(d)); diff < bestDiff {
+			numerator, denominator, bestDiff = n, d, diff
+		}
+	}
+	if numerator == denominator {
+		whole++
+		numerator = 0
+	}
+	if !mixed {
+		return sign + strconv.FormatFloat(whole*float64(denominator)+float64(numerator), 'f', 0, 64) +
+			"/" + strconv.Itoa(denominator)
+	}
+	switch {
+	case numerator == 0:
+		return sign + strconv.FormatFloat(whole, 'f', 0, 64)
+	case whole == 0:
+		return sign + strconv.Itoa(numerator) + "/" + strconv.Itoa(denominator)
+	}
+	return sign + strconv.FormatFloat(whole, 'f', 0, 64) + " " + strconv.Itoa(numerator) + "/" + strconv.Itoa(denominator)
+}
+
+// addThousandsSeparators inserts a comma between every group of three
+// digits in the integer part of a formatted number.
+func addThousandsSeparators(formattedNum string) string {
+	sign := ""
+	if strings.HasPrefix(formattedNum, "-") {
+		sign, formattedNum = "-", formattedNum[1:]
+	}
+	intPart, fracPart := formattedNum, ""
+	if i := strings.Index(formattedNum, "."); i != -1 {
+		intPart, fracPart = formattedNum[:i], formattedNum[i:]
+	}
+	var b strings.Builder
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	return sign + b.String() + fracPart
+}
+
 func generalNumericScientific(value string, allowScientific bool) (string, error) {
 	if strings.TrimSpace(value) == "" {
 		return "", nil
@@ -369,6 +466,15 @@ func parseNumberFormatSection(fullFormat string) (*formatOptions, error) {
 		}, nil
 	}
 
+	if match := fractionFormat.FindStringSubmatch(reducedFormat); match != nil {
+		return &formatOptions{
+			fullFormatString:    fullFormat,
+			reducedFormatString: reducedFormat,
+			fractionDigits:      len(match[2]),
+			mixedFraction:       match[1] != "",
+		}, nil
+	}
+
 	prefix, reducedFormat, showPercent1, err := parseLiterals(reducedFormat)
 	if err != nil {
 		return nil, err
@@ -224,6 +224,54 @@ func (l *CellSuite) TestFormatStringSupport(c *C) {
 			formattedValueOutput: "Behold; asdf",
 			cellType:             CellTypeString,
 		},
+		{
+			formatString:         `# ?/?`,
+			value:                "0.5",
+			formattedValueOutput: "1/2",
+			cellType:             CellTypeNumeric,
+		},
+		{
+			formatString:         `# ?/?`,
+			value:                "1.5",
+			formattedValueOutput: "1 1/2",
+			cellType:             CellTypeNumeric,
+		},
+		{
+			formatString:         `# ??/??`,
+			value:                "0.375",
+			formattedValueOutput: "3/8",
+			cellType:             CellTypeNumeric,
+		},
+		{
+			formatString:         `# ??/??`,
+			value:                "3.14159",
+			formattedValueOutput: "3 14/99",
+			cellType:             CellTypeNumeric,
+		},
+		{
+			formatString:         `# ???/???`,
+			value:                "3.14159",
+			formattedValueOutput: "3 16/113",
+			cellType:             CellTypeNumeric,
+		},
+		{
+			formatString:         `??/??`,
+			value:                "1.5",
+			formattedValueOutput: "3/2",
+			cellType:             CellTypeNumeric,
+		},
+		{
+			formatString:         `# ?/?`,
+			value:                "-0.25",
+			formattedValueOutput: "-1/4",
+			cellType:             CellTypeNumeric,
+		},
+		{
+			formatString:         `# ?/?`,
+			value:                "2",
+			formattedValueOutput: "2",
+			cellType:             CellTypeNumeric,
+		},
 	}
 	for _, testCase := range testCases {
 		cell := &Cell{
@@ -240,3 +288,55 @@ func (l *CellSuite) TestFormatStringSupport(c *C) {
 		}
 	}
 }
+
+func (l *CellSuite) TestBuiltInNumberFormats(c *C) {
+	testCases := []struct {
+		numFmtId int
+		value    string
+		expected string
+	}{
+		{5, "1234.56", "$1,235"},
+		{5, "-1234.56", "($1,235)"},
+		{8, "-1234.56", "($1,234.56)"},
+		{37, "1234.56", "1,235"},
+		{37, "-1234.56", "(1,235)"},
+		{38, "-1234.56", "(1,235)"},
+		{39, "1234.56", "1,234.56"},
+		{39, "-1234.56", "(1,234.56)"},
+		{40, "-1234.56", "(1,234.56)"},
+		{41, "1234.56", " 1,235"},
+		{41, "-1234.56", " (1,235)"},
+		{41, "0", " -"},
+		{42, "1234.56", "$ 1,235"},
+		{42, "-1234.56", "$ (1,235)"},
+		{42, "0", "$ -"},
+		{43, "1234.56", " 1,234.56"},
+		{43, "-1234.56", " (1,234.56)"},
+		{43, "0", " -  "},
+		{44, "1234.56", "$ 1,234.56"},
+		{44, "-1234.56", "$ (1,234.56)"},
+		{44, "0", "$ -  "},
+	}
+	for _, testCase := range testCases {
+		cell := &Cell{
+			cellType: CellTypeNumeric,
+			NumFmt:   builtInNumFmt[testCase.numFmtId],
+			Value:    testCase.value,
+		}
+		val, err := cell.FormattedValue()
+		c.Assert(err, IsNil, Commentf("numFmtId %d", testCase.numFmtId))
+		c.Assert(val, Equals, testCase.expected, Commentf("numFmtId %d, value %s", testCase.numFmtId, testCase.value))
+	}
+}
+
+func (l *CellSuite) TestLocaleBuiltInNumberFormats(c *C) {
+	styles := newXlsxStyleSheet(nil)
+	styles.CellXfs = xlsxCellXfs{Count: 2, Xf: []xlsxXf{{NumFmtId: 31}, {NumFmtId: 32}}}
+	styles.numFmtRefTable = map[int]xlsxNumFmt{32: {NumFmtId: 32, FormatCode: "hh:mm"}}
+
+	numFmt, _ := styles.getNumberFormat(0)
+	c.Assert(numFmt, Equals, builtInNumFmt[14])
+	// A locale dependent format defined by the file is used as it is.
+	numFmt, _ = styles.getNumberFormat(1)
+	c.Assert(numFmt, Equals, "hh:mm")
+}
@@ -10,6 +10,7 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 const (
@@ -97,12 +98,12 @@ func formatColumnName(colId []int) string {
 			// range 0-25, all other numbers are 1-26,
 			// hence we use a differente offset for the
 			// last part.
-			result += string(part + 65)
+			result += string(rune(part + 65))
 		} else {
 			// Don't output leading 0s, as there is no
 			// representation of 0 in this format.
 			if part > 0 {
-				result += string(part + 64)
+				result += string(rune(part + 64))
 			}
 		}
 	}
@@ -292,6 +293,7 @@ func makeRowFromSpan(spans string, sheet *Sheet) *Row {
 	for i := 0; i < upper; i++ {
 		cell = new(Cell)
 		cell.Value = ""
+		cell.absent = true
 		row.Cells[i] = cell
 	}
 	return row
@@ -328,6 +330,7 @@ func makeRowFromRaw(rawrow xlsxRow, sheet *Sheet) *Row {
 	for i := 0; i < upper; i++ {
 		cell = new(Cell)
 		cell.Value = ""
+		cell.absent = true
 		row.Cells[i] = cell
 	}
 	return row
@@ -467,6 +470,7 @@ func shiftCell(cellID string, dx, dy int) string {
 // general enough - we should support retaining tabs and newlines.
 func fillCellData(rawCell xlsxC, refTable *RefTable, sharedFormulas map[int]sharedFormula, cell *Cell) {
 	val := strings.Trim(rawCell.V, " \t\n\r")
+	cell.absent = false
 	cell.formula = formulaForCell(rawCell, sharedFormulas)
 	switch rawCell.T {
 	case "s": // Shared String
@@ -477,6 +481,7 @@ func fillCellData(rawCell xlsxC, refTable *RefTable, sharedFormulas map[int]shar
 				panic(err)
 			}
 			cell.Value = refTable.ResolveSharedString(ref)
+			cell.RichText = refTable.resolveRichText(ref)
 		}
 	case "inlineStr":
 		cell.cellType = CellTypeInline
@@ -500,6 +505,11 @@ func fillCellData(rawCell xlsxC, refTable *RefTable, sharedFormulas map[int]shar
 	case "n": // Numeric
 		cell.Value = val
 		cell.cellType = CellTypeNumeric
+		// A number with no value and no formula to give it one is
+		// an empty cell, not 0.
+		if val == "" && cell.formula == "" {
+			cell.absent = true
+		}
 	default:
 		panic(errors.New("invalid cell type"))
 	}
@@ -515,8 +525,110 @@ func fillCellDataFromInlineString(rawcell xlsxC, cell *Cell) {
 			for _, r := range rawcell.Is.R {
 				cell.Value += r.T
 			}
+			cell.RichText = richTextFromXLSX(rawcell.Is.R)
+		}
+	}
+}
+
+// rowNumberFromCells returns the one based number of a row that has no
+// r attribute of its own, as it is given by the references of its
+// cells, or 0 if they have none either.  The row then follows the one
+// before it.
+func rowNumberFromCells(rawrow xlsxRow) int {
+	for _, rawcell := range rawrow.C {
+		if rawcell.R == "" {
+			continue
+		}
+		if _, y, err := GetCoordsFromCellIDString(rawcell.R); err == nil && y >= 0 {
+			return y + 1
 		}
 	}
+	return 0
+}
+
+// readColFromRaw is an internal helper function that makes the Col of
+// a raw column definition of a worksheet.
+func readColFromRaw(rawcol xlsxCol, file *File) *Col {
+	col := &Col{
+		Min:          rawcol.Min,
+		Max:          rawcol.Max,
+		Hidden:       rawcol.Hidden,
+		Width:        rawcol.Width,
+		OutlineLevel: rawcol.OutlineLevel,
+		BestFit:      rawcol.BestFit,
+		CustomWidth:  rawcol.CustomWidth,
+		Phonetic:     rawcol.Phonetic,
+		Collapsed:    rawcol.Collapsed,
+	}
+	if file.styles != nil {
+		col.style = file.styles.getStyle(rawcol.Style)
+		col.numFmt, col.parsedNumFmt = file.styles.getNumberFormat(rawcol.Style)
+	}
+	return col
+}
+
+// readRowFromRaw is an internal helper function that makes the Row of
+// a raw row of a worksheet, filled with its Cells.
+func readRowFromRaw(rawrow xlsxRow, file *File, sheet *Sheet, cols *ColStore, mergeCells *xlsxMergeCells, minCol int, sharedFormulas map[int]sharedFormula) *Row {
+	var row *Row
+	reftable := file.referenceTable
+	// range is not empty and only one range exist
+	if len(rawrow.Spans) != 0 && strings.Count(rawrow.Spans, cellRangeChar) == 1 {
+		row = makeRowFromSpan(rawrow.Spans, sheet)
+	} else {
+		row = makeRowFromRaw(rawrow, sheet)
+	}
+
+	row.Hidden = bool(rawrow.Hidden)
+	height, err := strconv.ParseFloat(rawrow.Ht, 64)
+	if err == nil {
+		row.Height = height
+	}
+	row.isCustom = bool(rawrow.CustomHeight)
+	row.OutlineLevel = rawrow.OutlineLevel
+	row.Collapsed = bool(rawrow.Collapsed)
+
+	insertColIndex := minCol
+	for _, rawcell := range rawrow.C {
+		h, v, err := mergeCells.getExtent(rawcell.R)
+		if err != nil {
+			panic(err.Error())
+		}
+		x, _, _ := GetCoordsFromCellIDString(rawcell.R)
+
+		// K1000000: Prevent panic when the range specified in the spreadsheet
+		//           view exceeds the actual number of columns in the dataset.
+
+		// Some spreadsheets will omit blank cells
+		// from the data.
+		for x > insertColIndex {
+			// Put an empty Cell into the array
+			if insertColIndex < len(row.Cells) {
+				row.Cells[insertColIndex] = &Cell{absent: true}
+			}
+			insertColIndex++
+		}
+		cellX := insertColIndex
+
+		if cellX < len(row.Cells) {
+			cell := row.Cells[cellX]
+			cell.HMerge = h
+			cell.VMerge = v
+			fillCellData(rawcell, reftable, sharedFormulas, cell)
+			cell.extLst = readExtLst(rawcell.ExtLst)
+			if file.styles != nil {
+				cell.style = file.styles.getStyle(rawcell.S)
+				cell.NumFmt, cell.parsedNumFmt = file.styles.getNumberFormat(rawcell.S)
+			}
+			cell.date1904 = file.Date1904
+			// Cell is considered hidden if the row or the column of this cell is hidden
+			//
+			col := cols.FindColByIndex(cellX + 1)
+			cell.Hidden = bool(rawrow.Hidden) || (col != nil && col.Hidden)
+			insertColIndex++
+		}
+	}
+	return row
 }
 
 // readRowsFromSheet is an internal helper function that extracts the
@@ -528,15 +640,13 @@ func readRowsFromSheet(Worksheet *xlsxWorksheet, file *File, sheet *Sheet, rowLi
 	var cols *ColStore
 	var row *Row
 	var minCol, maxCol, maxRow, colCount, rowCount int
-	var reftable *RefTable
 	var err error
-	var insertRowIndex, insertColIndex int
+	var insertRowIndex int
 	sharedFormulas := map[int]sharedFormula{}
 
 	if len(Worksheet.SheetData.Row) == 0 {
-		return nil, nil, 0, 0
+		return nil, &ColStore{}, 0, 0
 	}
-	reftable = file.referenceTable
 	if len(Worksheet.Dimension.Ref) > 0 && len(strings.Split(Worksheet.Dimension.Ref, cellRangeChar)) == 2 && rowLimit == NoRowLimit {
 		minCol, _, maxCol, maxRow, err = getMaxMinFromDimensionRef(Worksheet.Dimension.Ref)
 	} else {
@@ -555,28 +665,16 @@ func readRowsFromSheet(Worksheet *xlsxWorksheet, file *File, sheet *Sheet, rowLi
 		// Columns can apply to a range, for convenience we expand the
 		// ranges out into individual column definitions.
 		for _, rawcol := range Worksheet.Cols.Col {
-			col := &Col{
-				Min:          rawcol.Min,
-				Max:          rawcol.Max,
-				Hidden:       rawcol.Hidden,
-				Width:        rawcol.Width,
-				OutlineLevel: rawcol.OutlineLevel,
-				BestFit:      rawcol.BestFit,
-				CustomWidth:  rawcol.CustomWidth,
-				Phonetic:     rawcol.Phonetic,
-				Collapsed:    rawcol.Collapsed,
-			}
-			if file.styles != nil {
-				col.style = file.styles.getStyle(rawcol.Style)
-				col.numFmt, col.parsedNumFmt = file.styles.getNumberFormat(rawcol.Style)
-			}
-			cols.Add(col)
+			cols.Add(readColFromRaw(rawcol, file))
 		}
 	}
 
 	numRows := len(rows)
 	for rowIndex := 0; rowIndex < len(Worksheet.SheetData.Row); rowIndex++ {
 		rawrow := Worksheet.SheetData.Row[rowIndex]
+		if rawrow.R == 0 {
+			rawrow.R = rowNumberFromCells(rawrow)
+		}
 		// Some spreadsheets will omit blank rows from the
 		// stored data
 		for rawrow.R > (insertRowIndex + 1) {
@@ -586,60 +684,7 @@ func readRowsFromSheet(Worksheet *xlsxWorksheet, file *File, sheet *Sheet, rowLi
 			}
 			insertRowIndex++
 		}
-		// range is not empty and only one range exist
-		if len(rawrow.Spans) != 0 && strings.Count(rawrow.Spans, cellRangeChar) == 1 {
-			row = makeRowFromSpan(rawrow.Spans, sheet)
-		} else {
-			row = makeRowFromRaw(rawrow, sheet)
-		}
-
-		row.Hidden = rawrow.Hidden
-		height, err := strconv.ParseFloat(rawrow.Ht, 64)
-		if err == nil {
-			row.Height = height
-		}
-		row.isCustom = rawrow.CustomHeight
-		row.OutlineLevel = rawrow.OutlineLevel
-
-		insertColIndex = minCol
-		for _, rawcell := range rawrow.C {
-			h, v, err := Worksheet.MergeCells.getExtent(rawcell.R)
-			if err != nil {
-				panic(err.Error())
-			}
-			x, _, _ := GetCoordsFromCellIDString(rawcell.R)
-
-			// K1000000: Prevent panic when the range specified in the spreadsheet
-			//           view exceeds the actual number of columns in the dataset.
-
-			// Some spreadsheets will omit blank cells
-			// from the data.
-			for x > insertColIndex {
-				// Put an empty Cell into the array
-				if insertColIndex < len(row.Cells) {
-					row.Cells[insertColIndex] = new(Cell)
-				}
-				insertColIndex++
-			}
-			cellX := insertColIndex
-
-			if cellX < len(row.Cells) {
-				cell := row.Cells[cellX]
-				cell.HMerge = h
-				cell.VMerge = v
-				fillCellData(rawcell, reftable, sharedFormulas, cell)
-				if file.styles != nil {
-					cell.style = file.styles.getStyle(rawcell.S)
-					cell.NumFmt, cell.parsedNumFmt = file.styles.getNumberFormat(rawcell.S)
-				}
-				cell.date1904 = file.Date1904
-				// Cell is considered hidden if the row or the column of this cell is hidden
-				//
-				col := cols.FindColByIndex(cellX + 1)
-				cell.Hidden = rawrow.Hidden || (col != nil && col.Hidden)
-				insertColIndex++
-			}
-		}
+		row = readRowFromRaw(rawrow, file, sheet, cols, Worksheet.MergeCells, minCol, sharedFormulas)
 		if len(rows) > insertRowIndex {
 			rows[insertRowIndex] = row
 		}
@@ -665,7 +710,7 @@ func readSheetViews(xSheetViews xlsxSheetViews) []SheetView {
 	}
 	sheetViews := []SheetView{}
 	for _, xSheetView := range xSheetViews.SheetView {
-		sheetView := SheetView{}
+		sheetView := SheetView{TopLeftCell: xSheetView.TopLeftCell}
 		if xSheetView.Pane != nil {
 			xlsxPane := xSheetView.Pane
 			pane := &Pane{}
@@ -676,6 +721,17 @@ func readSheetViews(xSheetViews xlsxSheetViews) []SheetView {
 			pane.State = xlsxPane.State
 			sheetView.Pane = pane
 		}
+		for i, xSelection := range xSheetView.Selection {
+			// There is a selection for each pane, the one of
+			// the active pane is the one that is shown.
+			if i > 0 && (sheetView.Pane == nil || xSelection.Pane != sheetView.Pane.ActivePane) {
+				continue
+			}
+			sheetView.Selection = &Selection{ActiveCell: xSelection.ActiveCell}
+			if xSelection.SQRef != "" {
+				sheetView.Selection.SQRef = strings.Fields(xSelection.SQRef)
+			}
+		}
 		sheetViews = append(sheetViews, sheetView)
 	}
 	return sheetViews
@@ -698,6 +754,12 @@ func readSheetFromFile(sc chan *indexedSheet, index int, rsheet xlsxSheet, fi *F
 			}
 			// The only thing here, is if one close the channel. but its not the case
 			sc <- result
+			return
+		}
+		// Some errors are returned without sending the result.
+		if errRes != nil && result.Error == nil {
+			result.Error = errRes
+			sc <- result
 		}
 	}()
 
@@ -711,40 +773,69 @@ func readSheetFromFile(sc chan *indexedSheet, index int, rsheet xlsxSheet, fi *F
 	sheet.File = fi
 	sheet.Rows, sheet.Cols, sheet.MaxCol, sheet.MaxRow = readRowsFromSheet(worksheet, fi, sheet, rowLimit)
 	sheet.Hidden = rsheet.State == sheetStateHidden || rsheet.State == sheetStateVeryHidden
+	sheet.veryHidden = rsheet.State == sheetStateVeryHidden
+	sheet.codeName = worksheet.SheetPr.CodeName
+	sheet.declaredDimension = worksheet.Dimension.Ref
+	sheet.printOptions = PrintOptions{
+		HorizontalCentered: worksheet.PrintOptions.HorizontalCentered,
+		VerticalCentered:   worksheet.PrintOptions.VerticalCentered,
+		GridLines:          worksheet.PrintOptions.GridLines,
+		Headings:           worksheet.PrintOptions.Headings,
+	}
+	sheet.rowBreaks = readBreaks(worksheet.RowBreaks)
+	sheet.colBreaks = readBreaks(worksheet.ColBreaks)
+	sheet.extLst = readExtLst(worksheet.ExtLst)
+	sheet.ColorScales = readColorScales(worksheet.ConditionalFormatting)
 	sheet.SheetViews = readSheetViews(worksheet.SheetViews)
+	for _, xSheetView := range worksheet.SheetViews.SheetView {
+		sheet.Selected = sheet.Selected || xSheetView.TabSelected
+	}
 	if worksheet.AutoFilter != nil {
+		// A filter on a single cell may be given without a range.
 		autoFilterBounds := strings.Split(worksheet.AutoFilter.Ref, ":")
-		sheet.AutoFilter = &AutoFilter{autoFilterBounds[0], autoFilterBounds[1]}
+		sheet.AutoFilter = &AutoFilter{autoFilterBounds[0], autoFilterBounds[len(autoFilterBounds)-1]}
 	}
 
 	// Convert xlsxHyperlinks to Hyperlinks
 	if worksheet.Hyperlinks != nil {
 
-		worksheetRelsFile := fi.worksheetRels["sheet"+rsheet.SheetId]
-		worksheetRels := new(xlsxWorksheetRels)
-		rc, err := worksheetRelsFile.Open()
-		if err != nil {
-			return err
-		}
-		decoder := xml.NewDecoder(rc)
-		err = decoder.Decode(worksheetRels)
-		if err != nil {
-			return err
-		}
-
+		// The relations belong to the part of the sheet, which is
+		// found through its relation from the workbook.  The ids of
+		// the sheets need not follow the numbers of their parts.
+		// Links to places within the workbook have no relation.
+		var worksheetRels *xlsxWorksheetRels
 		for _, xlsxLink := range worksheet.Hyperlinks.HyperLinks {
-			newHyperLink := Hyperlink{}
-
-			relationPresent := false
-			for _, rel := range worksheetRels.Relationships {
-				if rel.Id == xlsxLink.RelationshipId {
-					newHyperLink.Link = rel.Target
-					relationPresent = true
-					break
+			newHyperLink := Hyperlink{Location: xlsxLink.Location}
+
+			if xlsxLink.RelationshipId != "" || xlsxLink.Location == "" {
+				if worksheetRels == nil {
+					worksheetRelsFile := worksheetFileForSheet(rsheet, fi.worksheetRels, sheetXMLMap)
+					if worksheetRelsFile == nil {
+						return errors.New("the sheet has hyperlinks but no relations file")
+					}
+					worksheetRels = new(xlsxWorksheetRels)
+					rc, err := worksheetRelsFile.Open()
+					if err != nil {
+						return err
+					}
+					decoder := xml.NewDecoder(rc)
+					err = decoder.Decode(worksheetRels)
+					rc.Close()
+					if err != nil {
+						return err
+					}
+				}
+				relationPresent := false
+				for _, rel := range worksheetRels.Relationships {
+					if rel.Id == xlsxLink.RelationshipId {
+						newHyperLink.Link = rel.Target
+						relationPresent = true
+						break
+					}
+				}
+				if !relationPresent {
+					return errors.New("sheets relations file has no relations for the relation id present in the hyperlink")
 				}
-			}
-			if !relationPresent {
-				return errors.New("sheets relations file has no relations for the relation id present in the hyperlink")
 			}
 
 			if xlsxLink.Tooltip != "" {
@@ -763,6 +854,24 @@ func readSheetFromFile(sc chan *indexedSheet, index int, rsheet xlsxSheet, fi *F
 		}
 	}
 
+	if worksheetRelsFile := worksheetFileForSheet(rsheet, fi.worksheetRels, sheetXMLMap); worksheetRelsFile != nil {
+		worksheetRels, err := readPartRelationsFromZipFile(worksheetRelsFile)
+		if err == nil {
+			err = readCommentsFromZipFile(sheet, worksheetRels, fi.parts)
+		}
+		if err == nil {
+			err = readThreadedCommentsFromZipFile(sheet, worksheetRels, fi.parts, fi.persons)
+		}
+		if err == nil {
+			err = readChartsFromZipFile(sheet, worksheetRels, fi.parts)
+		}
+		if err != nil {
+			result.Error = err
+			sc <- result
+			return err
+		}
+	}
+
 	sheet.SheetFormat.DefaultColWidth = worksheet.SheetFormatPr.DefaultColWidth
 	sheet.SheetFormat.DefaultRowHeight = worksheet.SheetFormatPr.DefaultRowHeight
 	sheet.SheetFormat.OutlineLevelCol = worksheet.SheetFormatPr.OutlineLevelCol
@@ -779,10 +888,35 @@ func readSheetFromFile(sc chan *indexedSheet, index int, rsheet xlsxSheet, fi *F
 	return nil
 }
 
+// stubSheetFromFile returns a Sheet holding only the metadata of a
+// worksheet whose contents were not requested.
+func stubSheetFromFile(rsheet xlsxSheet, fi *File) *Sheet {
+	return &Sheet{
+		File:       fi,
+		Hidden:     rsheet.State == sheetStateHidden || rsheet.State == sheetStateVeryHidden,
+		veryHidden: rsheet.State == sheetStateVeryHidden,
+		Cols:       &ColStore{},
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 // readSheetsFromZipFile is an internal helper function that loops
 // over the Worksheets defined in the XSLXWorkbook and loads them into
 // Sheet objects stored in the Sheets slice of a xlsx.File struct.
-func readSheetsFromZipFile(f *zip.File, file *File, sheetXMLMap map[string]string, rowLimit int) (map[string]*Sheet, []*Sheet, error) {
+// Up to concurrency sheets are parsed at the same time.
+//
+// If sheetNames is not nil, only the sheets it names are parsed, and
+// every other sheet is represented by a stub Sheet carrying just its
+// name and visibility.
+func readSheetsFromZipFile(f *zip.File, file *File, sheetXMLMap map[string]string, rowLimit int, sheetNames []string, concurrency int) (map[string]*Sheet, []*Sheet, error) {
 	var workbook *xlsxWorkbook
 	var err error
 	var rc io.ReadCloser
@@ -799,17 +933,32 @@ func readSheetsFromZipFile(f *zip.File, file *File, sheetXMLMap map[string]strin
 		return nil, nil, err
 	}
 	file.Date1904 = workbook.WorkbookPr.Date1904
+	file.codeName = workbook.WorkbookPr.CodeName
+	if len(workbook.BookViews.WorkBookView) > 0 {
+		view := workbook.BookViews.WorkBookView[0]
+		file.WorkbookView = &WorkbookView{
+			TabRatio:     view.TabRatio,
+			WindowWidth:  view.WindowWidth,
+			WindowHeight: view.WindowHeight,
+		}
+	}
 
 	for entryNum := range workbook.DefinedNames.DefinedName {
 		file.DefinedNames = append(file.DefinedNames, &workbook.DefinedNames.DefinedName[entryNum])
 	}
+	file.calcChain, err = readCalcChainFromZipFile(file.parts, workbook.Sheets.Sheet, file)
+	if err != nil {
+		return nil, nil, err
+	}
 
+	file.worksheetsByName = make(map[string]*zip.File, len(workbook.Sheets.Sheet))
 	// Only try and read sheets that have corresponding files.
 	// Notably this excludes chartsheets don't right now
 	var workbookSheets []xlsxSheet
 	for _, sheet := range workbook.Sheets.Sheet {
 		if f := worksheetFileForSheet(sheet, file.worksheets, sheetXMLMap); f != nil {
 			workbookSheets = append(workbookSheets, sheet)
+			file.worksheetsByName[sheet.Name] = f
 		}
 	}
 	sheetCount = len(workbookSheets)
@@ -817,14 +966,45 @@ func readSheetsFromZipFile(f *zip.File, file *File, sheetXMLMap map[string]strin
 	sheets := make([]*Sheet, sheetCount)
 	sheetChan := make(chan *indexedSheet, sheetCount)
 
-	go func() {
-		defer close(sheetChan)
-		err = nil
-		for i, rawsheet := range workbookSheets {
-			if err := readSheetFromFile(sheetChan, i, rawsheet, file, sheetXMLMap, rowLimit); err != nil {
-				return
-			}
+	readSheet := func(i int) error {
+		rawsheet := workbookSheets[i]
+		if sheetNames != nil && !containsString(sheetNames, rawsheet.Name) {
+			sheetChan <- &indexedSheet{Index: i, Sheet: stubSheetFromFile(rawsheet, file)}
+			return nil
 		}
+		return readSheetFromFile(sheetChan, i, rawsheet, file, sheetXMLMap, rowLimit)
+	}
+
+	// The sheets are handed out to the workers in order, and each
+	// stops at its first error.  Every sheet sends its result on
+	// sheetChan, which has room for all of them, so the workers never
+	// wait on the loop below, which returns at the first error.
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if concurrency > sheetCount {
+		concurrency = sheetCount
+	}
+	sheetIndexes := make(chan int, sheetCount)
+	for i := range workbookSheets {
+		sheetIndexes <- i
+	}
+	close(sheetIndexes)
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for w := 0; w < concurrency; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range sheetIndexes {
+				if err := readSheet(i); err != nil {
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(sheetChan)
 	}()
 
 	for j := 0; j < sheetCount; j++ {
@@ -842,8 +1022,9 @@ func readSheetsFromZipFile(f *zip.File, file *File, sheetXMLMap map[string]strin
 
 // readSharedStringsFromZipFile() is an internal helper function to
 // extract a reference table from the sharedStrings.xml file within
-// the XLSX zip file.
-func readSharedStringsFromZipFile(f *zip.File) (*RefTable, error) {
+// the XLSX zip file.  Counts declared by the table that do not match
+// its strings are recorded as warnings of file.
+func readSharedStringsFromZipFile(f *zip.File, file *File) (*RefTable, error) {
 	var sst *xlsxSST
 	var error error
 	var rc io.ReadCloser
@@ -866,6 +1047,15 @@ func readSharedStringsFromZipFile(f *zip.File) (*RefTable, error) {
 	if error != nil {
 		return nil, error
 	}
+	// The counts are optional, so only those that are given can be
+	// checked.  count is the number of references to the strings,
+	// which cannot be fewer than the strings themselves.
+	if sst.UniqueCount != 0 && sst.UniqueCount != len(sst.SI) {
+		file.addWarning(fmt.Sprintf("shared strings declare a uniqueCount of %d but hold %d strings", sst.UniqueCount, len(sst.SI)))
+	}
+	if sst.Count != 0 && sst.Count < len(sst.SI) {
+		file.addWarning(fmt.Sprintf("shared strings declare a count of %d references but hold %d strings", sst.Count, len(sst.SI)))
+	}
 	reftable = MakeSharedStringRefTable(sst)
 	return reftable, nil
 }
@@ -1001,7 +1191,12 @@ func ReadZip(f *zip.ReadCloser) (*File, error) {
 // ReadZip is not used directly, but is called internally by OpenFile.
 func ReadZipWithRowLimit(f *zip.ReadCloser, rowLimit int) (*File, error) {
 	defer f.Close()
-	return ReadZipReaderWithRowLimit(&f.Reader, rowLimit)
+	file, err := ReadZipReaderWithRowLimit(&f.Reader, rowLimit)
+	if err != nil {
+		return nil, err
+	}
+	file.zipClosed = true
+	return file, nil
 }
 
 // ReadZipReader() can be used to read an XLSX in memory without
@@ -1015,6 +1210,14 @@ func ReadZipReader(r *zip.Reader) (*File, error) {
 // rowLimit is the number of rows that should be read from the file. If rowLimit is -1, no limit is applied.
 // You can specify this with the constant NoRowLimit.
 func ReadZipReaderWithRowLimit(r *zip.Reader, rowLimit int) (*File, error) {
+	return readZipReader(r, rowLimit, nil, 1)
+}
+
+// readZipReader does the work of ReadZipReaderWithRowLimit.  If
+// sheetNames is not nil, only the sheets it names are parsed.  Up to
+// concurrency sheets are parsed at the same time, once the parts the
+// sheets share, such as the shared strings and the styles, have been.
+func readZipReader(r *zip.Reader, rowLimit int, sheetNames []string, concurrency int) (*File, error) {
 	var err error
 	var file *File
 	var reftable *RefTable
@@ -1030,12 +1233,15 @@ func ReadZipReaderWithRowLimit(r *zip.Reader, rowLimit int) (*File, error) {
 	var workbookRels *zip.File
 	var worksheets map[string]*zip.File
 	var worksheetRels map[string]*zip.File
+	var parts map[string]*zip.File
 
 	file = NewFile()
 	// file.numFmtRefTable = make(map[int]xlsxNumFmt, 1)
 	worksheets = make(map[string]*zip.File, len(r.File))
 	worksheetRels = make(map[string]*zip.File, len(r.File))
+	parts = make(map[string]*zip.File, len(r.File))
 	for _, v = range r.File {
+		parts[v.Name] = v
 		switch v.Name {
 		case "xl/sharedStrings.xml":
 			sharedStrings = v
@@ -1071,7 +1277,8 @@ func ReadZipReaderWithRowLimit(r *zip.Reader, rowLimit int) (*File, error) {
 	}
 	file.worksheets = worksheets
 	file.worksheetRels = worksheetRels
-	reftable, err = readSharedStringsFromZipFile(sharedStrings)
+	file.parts = parts
+	reftable, err = readSharedStringsFromZipFile(sharedStrings, file)
 	if err != nil {
 		return nil, err
 	}
@@ -1092,7 +1299,19 @@ func ReadZipReaderWithRowLimit(r *zip.Reader, rowLimit int) (*File, error) {
 
 		file.styles = style
 	}
-	sheetsByName, sheets, err = readSheetsFromZipFile(workbook, file, sheetXMLMap, rowLimit)
+	file.externalLinks, err = readExternalLinksFromZipFile(parts)
+	if err != nil {
+		return nil, err
+	}
+	file.vbaProject, err = readVBAProjectFromZipFile(parts)
+	if err != nil {
+		return nil, err
+	}
+	file.persons, err = readPersonsFromZipFile(parts)
+	if err != nil {
+		return nil, err
+	}
+	sheetsByName, sheets, err = readSheetsFromZipFile(workbook, file, sheetXMLMap, rowLimit, sheetNames, concurrency)
 	//sheetRelsByName, sheetRels, err = readSheetRelationsFromZipFile()
 	if err != nil {
 		return nil, err
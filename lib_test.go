@@ -1710,3 +1710,141 @@ func TestFuzzCrashers(t *testing.T) {
 		}
 	}
 }
+
+func TestSharedStringsCountWarnings(t *testing.T) {
+	c := qt.New(t)
+
+	file := NewFile()
+	sheet, err := file.AddSheet("Sheet1")
+	c.Assert(err, qt.IsNil)
+	sheet.Cell(0, 0).SetString("one")
+	sheet.Cell(0, 1).SetString("two")
+	sheet.Cell(1, 0).SetString("one")
+	var buf bytes.Buffer
+	c.Assert(file.Write(&buf), qt.IsNil)
+
+	file, err = OpenBinary(buf.Bytes())
+	c.Assert(err, qt.IsNil)
+	c.Assert(file.Warnings(), qt.HasLen, 0)
+
+	parts := unzipParts(c, buf.Bytes())
+	c.Assert(parts["xl/sharedStrings.xml"], qt.Contains, `count="2" uniqueCount="2"`)
+	parts["xl/sharedStrings.xml"] = strings.Replace(parts["xl/sharedStrings.xml"], `count="2" uniqueCount="2"`, `count="1" uniqueCount="5"`, 1)
+	file, err = OpenBinary(zipParts(c, parts))
+	c.Assert(err, qt.IsNil)
+	c.Assert(file.Warnings(), qt.DeepEquals, []string{
+		"shared strings declare a uniqueCount of 5 but hold 2 strings",
+		"shared strings declare a count of 1 references but hold 2 strings",
+	})
+	// The strings are read all the same.
+	c.Assert(file.Sheets[0].Cell(0, 1).Value, qt.Equals, "two")
+}
+
+func TestReadSheetsOutOfPartOrder(t *testing.T) {
+	c := qt.New(t)
+
+	file := NewFile()
+	for _, name := range []string{"A", "B"} {
+		sheet, err := file.AddSheet(name)
+		c.Assert(err, qt.IsNil)
+		sheet.Cell(0, 0).SetHyperlink("https://example.com/"+name, name, "")
+	}
+	var buf bytes.Buffer
+	c.Assert(file.Write(&buf), qt.IsNil)
+	parts := unzipParts(c, buf.Bytes())
+	// List the second part first, with the first sheet id, so that
+	// neither the order nor the ids of the sheets match their parts.
+	sheets := `<sheets><sheet name="A" sheetId="1" r:id="rId1" state="visible"></sheet><sheet name="B" sheetId="2" r:id="rId2" state="visible"></sheet></sheets>`
+	c.Assert(parts["xl/workbook.xml"], qt.Contains, sheets)
+	parts["xl/workbook.xml"] = strings.Replace(parts["xl/workbook.xml"], sheets, `<sheets><sheet name="B" sheetId="1" r:id="rId2" state="visible"></sheet><sheet name="A" sheetId="2" r:id="rId1" state="visible"></sheet></sheets>`, 1)
+
+	file, err := OpenBinary(zipParts(c, parts))
+	c.Assert(err, qt.IsNil)
+	c.Assert(file.Sheets, qt.HasLen, 2)
+	for i, name := range []string{"B", "A"} {
+		sheet := file.Sheets[i]
+		c.Assert(sheet.Name, qt.Equals, name)
+		c.Assert(sheet.Cell(0, 0).Value, qt.Equals, name)
+		c.Assert(sheet.Cell(0, 0).Hyperlink.Link, qt.Equals, "https://example.com/"+name)
+	}
+}
+
+func TestReadSheetStartingBelowFirstRow(t *testing.T) {
+	c := qt.New(t)
+
+	file := NewFile()
+	sheet, err := file.AddSheet("Sheet1")
+	c.Assert(err, qt.IsNil)
+	sheet.Cell(0, 0).SetString("first")
+	sheet.Cell(1, 1).SetString("second")
+	var buf bytes.Buffer
+	c.Assert(file.Write(&buf), qt.IsNil)
+	parts := unzipParts(c, buf.Bytes())
+	sheetXML := parts["xl/worksheets/sheet1.xml"]
+	sheetXML = strings.Replace(sheetXML, `<dimension ref="A1:B2"></dimension>`, ``, 1)
+	sheetXML = strings.Replace(sheetXML, `r="A1"`, `r="A5"`, 1)
+	sheetXML = strings.Replace(sheetXML, `r="B2"`, `r="B6"`, 1)
+
+	for _, test := range []struct {
+		about   string
+		rowRefs *strings.Replacer
+	}{{
+		about:   "rows with numbers",
+		rowRefs: strings.NewReplacer(`<row r="1">`, `<row r="5">`, `<row r="2">`, `<row r="6">`),
+	}, {
+		about:   "rows numbered only by their cells",
+		rowRefs: strings.NewReplacer(`<row r="1">`, `<row>`, `<row r="2">`, `<row>`),
+	}} {
+		c.Run(test.about, func(c *qt.C) {
+			parts["xl/worksheets/sheet1.xml"] = test.rowRefs.Replace(sheetXML)
+			file, err := OpenBinary(zipParts(c, parts))
+			c.Assert(err, qt.IsNil)
+			sheet := file.Sheets[0]
+			c.Assert(sheet.MaxRow, qt.Equals, 6)
+			c.Assert(sheet.Cell(0, 0).Value, qt.Equals, "")
+			c.Assert(sheet.Cell(4, 0).Value, qt.Equals, "first")
+			c.Assert(sheet.Cell(5, 1).Value, qt.Equals, "second")
+		})
+	}
+}
+
+func TestReadNumericCellWithoutValue(t *testing.T) {
+	c := qt.New(t)
+
+	file := NewFile()
+	sheet, err := file.AddSheet("Sheet1")
+	c.Assert(err, qt.IsNil)
+	sheet.Cell(0, 0).SetString("placeholder")
+	parts, err := file.MarshallParts()
+	c.Assert(err, qt.IsNil)
+	sheetXML := parts["xl/worksheets/sheet1.xml"]
+	c.Assert(sheetXML, qt.Contains, `<sheetData>`)
+	start := strings.Index(sheetXML, `<sheetData>`)
+	end := strings.Index(sheetXML, `</sheetData>`) + len(`</sheetData>`)
+	parts["xl/worksheets/sheet1.xml"] = sheetXML[:start] + `<sheetData><row r="1">` +
+		`<c r="A1" t="n"/>` +
+		`<c r="B1" t="n"><v></v></c>` +
+		`<c r="C1"><v>0</v></c>` +
+		`<c r="D1"/>` +
+		`<c r="E1" t="n"><f>1+1</f><v></v></c>` +
+		`</row></sheetData>` + sheetXML[end:]
+
+	file, err = OpenBinary(zipParts(c, parts))
+	c.Assert(err, qt.IsNil)
+	sheet = file.Sheets[0]
+	for col := 0; col < 2; col++ {
+		cell := sheet.Cell(0, col)
+		c.Assert(cell.IsEmpty(), qt.Equals, true)
+		_, err := cell.Float()
+		c.Assert(err, qt.Not(qt.IsNil))
+		_, err = cell.Int()
+		c.Assert(err, qt.Not(qt.IsNil))
+	}
+	c.Assert(sheet.Cell(0, 2).IsEmpty(), qt.Equals, false)
+	f, err := sheet.Cell(0, 2).Float()
+	c.Assert(err, qt.IsNil)
+	c.Assert(f, qt.Equals, 0.0)
+	c.Assert(sheet.Cell(0, 3).IsEmpty(), qt.Equals, true)
+	c.Assert(sheet.Cell(0, 4).IsEmpty(), qt.Equals, false)
+	c.Assert(sheet.Cell(0, 4).Formula(), qt.Equals, "1+1")
+}
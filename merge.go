@@ -0,0 +1,74 @@
+package xlsx
+
+import (
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// MergeFiles writes a single workbook to out that holds the sheets of
+// all of files, in order.  Sheets whose name is already taken by an
+// earlier sheet get a numbered suffix, so that a second "Data" sheet
+// becomes "Data (2)".  The cells keep their values, styles and number
+// formats, which are written to the styles and shared strings of the
+// new workbook.  Dates are held as serial numbers, so the workbook
+// uses the date system of the first file, and an error is returned if
+// any of the others uses the other one.
+func MergeFiles(out io.Writer, files []*File) error {
+	merged := NewFile()
+	if len(files) > 0 {
+		merged.Date1904 = files[0].Date1904
+	}
+	for i, file := range files {
+		if file.Date1904 != merged.Date1904 {
+			return fmt.Errorf("file %d uses a different date system from the first file", i+1)
+		}
+	}
+	for _, file := range files {
+		for _, sheet := range file.Sheets {
+			copied, err := merged.AppendSheet(*sheet, uniqueSheetName(merged, sheet.Name))
+			if err != nil {
+				return err
+			}
+			copied.Rows = make([]*Row, len(sheet.Rows))
+			for i, row := range sheet.Rows {
+				if row == nil {
+					continue
+				}
+				copiedRow := *row
+				copiedRow.Sheet = copied
+				copiedRow.Cells = make([]*Cell, len(row.Cells))
+				for j, cell := range row.Cells {
+					if cell == nil {
+						continue
+					}
+					copiedCell := *cell
+					copiedCell.Row = &copiedRow
+					copiedRow.Cells[j] = &copiedCell
+				}
+				copied.Rows[i] = &copiedRow
+			}
+		}
+	}
+	return merged.Write(out)
+}
+
+// uniqueSheetName returns name, or name with the lowest numbered suffix
+// that makes it unique among the sheets of file, keeping within the
+// 31 characters allowed for a sheet name.
+func uniqueSheetName(file *File, name string) string {
+	if _, exists := file.Sheet[name]; !exists {
+		return name
+	}
+	for n := 2; ; n++ {
+		suffix := fmt.Sprintf(" (%d)", n)
+		base := []rune(name)
+		if max := 31 - utf8.RuneCountInString(suffix); len(base) > max {
+			base = base[:max]
+		}
+		candidate := string(base) + suffix
+		if _, exists := file.Sheet[candidate]; !exists {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package xlsx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestMergeFiles(t *testing.T) {
+	c := qt.New(t)
+
+	first := NewFile()
+	data, err := first.AddSheet("Data")
+	c.Assert(err, qt.IsNil)
+	data.Cell(0, 0).SetString("first")
+	bold := NewStyle()
+	bold.Font.Bold = true
+	bold.ApplyFont = true
+	data.Cell(0, 0).SetStyle(bold)
+	data.Cell(1, 0).SetFloatWithFormat(1234.5, "#,##0.00")
+	summary, err := first.AddSheet("Summary")
+	c.Assert(err, qt.IsNil)
+	summary.Cell(0, 0).SetString("shared")
+
+	second := NewFile()
+	data, err = second.AddSheet("Data")
+	c.Assert(err, qt.IsNil)
+	data.Cell(0, 0).SetString("shared")
+	data.Cell(0, 1).SetString("second")
+	long, err := second.AddSheet(strings.Repeat("x", 31))
+	c.Assert(err, qt.IsNil)
+	long.Cell(0, 0).SetInt(7)
+	third := NewFile()
+	_, err = third.AddSheet(strings.Repeat("x", 31))
+	c.Assert(err, qt.IsNil)
+
+	var buf bytes.Buffer
+	c.Assert(MergeFiles(&buf, []*File{first, second, third}), qt.IsNil)
+	// The sources are left as they were.
+	c.Assert(second.Sheets[0].Name, qt.Equals, "Data")
+	c.Assert(second.Sheets[0].Rows[0].Sheet, qt.Equals, second.Sheets[0])
+
+	file, err := OpenBinary(buf.Bytes())
+	c.Assert(err, qt.IsNil)
+	var names []string
+	for _, sheet := range file.Sheets {
+		names = append(names, sheet.Name)
+	}
+	c.Assert(names, qt.DeepEquals, []string{
+		"Data",
+		"Summary",
+		"Data (2)",
+		strings.Repeat("x", 31),
+		strings.Repeat("x", 27) + " (2)",
+	})
+	sheet := file.Sheet["Data"]
+	c.Assert(sheet.Cell(0, 0).Value, qt.Equals, "first")
+	c.Assert(sheet.Cell(0, 0).GetStyle().Font.Bold, qt.Equals, true)
+	c.Assert(sheet.Cell(1, 0).Value, qt.Equals, "1234.5")
+	c.Assert(sheet.Cell(1, 0).NumFmt, qt.Equals, "#,##0.00")
+	c.Assert(file.Sheet["Summary"].Cell(0, 0).Value, qt.Equals, "shared")
+	sheet = file.Sheet["Data (2)"]
+	c.Assert(sheet.Cell(0, 0).Value, qt.Equals, "shared")
+	c.Assert(sheet.Cell(0, 1).Value, qt.Equals, "second")
+	c.Assert(sheet.Cell(0, 0).GetStyle().Font.Bold, qt.Equals, false)
+	c.Assert(file.Sheet[strings.Repeat("x", 31)].Cell(0, 0).Value, qt.Equals, "7")
+
+	// Files with different date systems cannot be merged, as the dates
+	// of one of them would move.
+	third.Date1904 = true
+	c.Assert(MergeFiles(&buf, []*File{first, second, third}), qt.ErrorMatches, "file 3 uses a different date system from the first file")
+}
@@ -0,0 +1,80 @@
+package xlsx
+
+import "sort"
+
+// The largest zero based row and column indexes, which manual breaks
+// of the other kind run up to.
+const (
+	maxRowIndex = 1048575
+	maxColIndex = 16383
+)
+
+// AddRowBreak adds a manual page break below the row at the zero
+// based index afterRow, so that the next row starts a new page when
+// the sheet is printed.  Adding the same break twice has no effect.
+func (s *Sheet) AddRowBreak(afterRow int) {
+	s.rowBreaks = addBreak(s.rowBreaks, afterRow)
+}
+
+// AddColBreak adds a manual page break to the right of the column at
+// the zero based index afterCol, so that the next column starts a new
+// page when the sheet is printed.  Adding the same break twice has no
+// effect.
+func (s *Sheet) AddColBreak(afterCol int) {
+	s.colBreaks = addBreak(s.colBreaks, afterCol)
+}
+
+// RowBreaks returns the zero based indexes of the rows that have a
+// manual page break below them, in order.
+func (s *Sheet) RowBreaks() []int {
+	return append([]int(nil), s.rowBreaks...)
+}
+
+// ColBreaks returns the zero based indexes of the columns that have a
+// manual page break to their right, in order.
+func (s *Sheet) ColBreaks() []int {
+	return append([]int(nil), s.colBreaks...)
+}
+
+// addBreak adds index to the sorted breaks, unless it is already there.
+func addBreak(breaks []int, index int) []int {
+	i := sort.SearchInts(breaks, index)
+	if i < len(breaks) && breaks[i] == index {
+		return breaks
+	}
+	breaks = append(breaks, 0)
+	copy(breaks[i+1:], breaks[i:])
+	breaks[i] = index
+	return breaks
+}
+
+// makeBreaks returns the XML representation of the manual page breaks
+// after the zero based indexes breaks, each running across to max, or
+// nil if there are none.
+func makeBreaks(breaks []int, max int) *xlsxBreaks {
+	if len(breaks) == 0 {
+		return nil
+	}
+	xBreaks := &xlsxBreaks{Count: len(breaks), ManualBreakCount: len(breaks)}
+	for _, index := range breaks {
+		// The id of a break is the one based index of the row or
+		// column before it.
+		xBreaks.Brk = append(xBreaks.Brk, xlsxBreak{Id: index + 1, Max: max, Man: true})
+	}
+	return xBreaks
+}
+
+// readBreaks returns the zero based indexes of the rows or columns
+// that the manual page breaks of xBreaks follow.
+func readBreaks(xBreaks *xlsxBreaks) []int {
+	if xBreaks == nil {
+		return nil
+	}
+	var breaks []int
+	for _, brk := range xBreaks.Brk {
+		if brk.Man {
+			breaks = addBreak(breaks, brk.Id-1)
+		}
+	}
+	return breaks
+}
@@ -0,0 +1,100 @@
+package xlsx
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	pivotCacheDefinitionRelationshipType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/pivotCacheDefinition"
+	pivotCacheDefinitionContentType      = "application/vnd.openxmlformats-officedocument.spreadsheetml.pivotCacheDefinition+xml"
+	pivotCachePathPrefix                 = "xl/pivotCache/"
+)
+
+// pivotCacheSource is a range of a sheet that pivot tables can be made
+// from.  The first row of the range holds the names of the fields.
+type pivotCacheSource struct {
+	sheet                          *Sheet
+	ref                            string
+	minCol, minRow, maxCol, maxRow int
+}
+
+// newPivotCacheSource returns the pivot cache source for the range ref,
+// such as "A1:D100", of sheet.
+func newPivotCacheSource(sheet *Sheet, ref string) (pivotCacheSource, error) {
+	if strings.Count(ref, cellRangeChar) != 1 {
+		return pivotCacheSource{}, fmt.Errorf("pivot cache source %q is not a range of cells", ref)
+	}
+	minCol, minRow, maxCol, maxRow, err := getMaxMinFromDimensionRef(ref)
+	if err != nil {
+		return pivotCacheSource{}, err
+	}
+	if minCol > maxCol || minRow >= maxRow {
+		return pivotCacheSource{}, errors.New("a pivot cache source needs a row of field names and at least one row of data")
+	}
+	return pivotCacheSource{
+		sheet:  sheet,
+		ref:    ref,
+		minCol: minCol,
+		minRow: minRow,
+		maxCol: maxCol,
+		maxRow: maxRow,
+	}, nil
+}
+
+// pivotCachePath returns the path of the definition of the pivot cache
+// with the one based id.
+func pivotCachePath(id int) string {
+	return pivotCachePathPrefix + "pivotCacheDefinition" + strconv.Itoa(id) + ".xml"
+}
+
+// headings returns the values of the first row of the range, as far as
+// the sheet has them.
+func (source pivotCacheSource) headings() []string {
+	headings := make([]string, source.maxCol-source.minCol+1)
+	if source.minRow >= len(source.sheet.Rows) || source.sheet.Rows[source.minRow] == nil {
+		return headings
+	}
+	cells := source.sheet.Rows[source.minRow].Cells
+	for i := range headings {
+		if col := source.minCol + i; col < len(cells) && cells[col] != nil {
+			headings[i] = cells[col].Value
+		}
+	}
+	return headings
+}
+
+// makeXLSXPivotCacheDefinition returns the definition of a pivot cache
+// over source, whose fields are named by headings.  No records are
+// saved with it; the cache is filled from the sheet when the file is
+// opened.
+func (source pivotCacheSource) makeXLSXPivotCacheDefinition(headings []string) xlsxPivotCacheDefinition {
+	definition := xlsxPivotCacheDefinition{
+		RefreshOnLoad: true,
+		CacheSource: xlsxCacheSource{
+			Type:            "worksheet",
+			WorksheetSource: xlsxWorksheetSource{Ref: source.ref, Sheet: source.sheet.Name},
+		},
+	}
+	// The names of the fields have to be present and unique.
+	used := make(map[string]bool)
+	for i := 0; i <= source.maxCol-source.minCol; i++ {
+		name := ""
+		if i < len(headings) {
+			name = strings.TrimSpace(headings[i])
+		}
+		if name == "" {
+			name = "Column" + strconv.Itoa(i+1)
+		}
+		unique := name
+		for n := 2; used[strings.ToLower(unique)]; n++ {
+			unique = name + strconv.Itoa(n)
+		}
+		used[strings.ToLower(unique)] = true
+		definition.CacheFields.CacheField = append(definition.CacheFields.CacheField, xlsxCacheField{Name: unique})
+	}
+	definition.CacheFields.Count = len(definition.CacheFields.CacheField)
+	return definition
+}
@@ -3,6 +3,7 @@ package xlsx
 type RefTable struct {
 	indexedStrings []string
 	knownStrings   map[string]int
+	richTexts      map[int][]RichTextRun
 	isWrite        bool
 }
 
@@ -26,7 +27,11 @@ func MakeSharedStringRefTable(source *xlsxSST) *RefTable {
 			for j := 0; j < len(si.R); j++ {
 				newString = newString + si.R[j].T
 			}
-			reftable.AddString(newString)
+			index := reftable.AddString(newString)
+			if reftable.richTexts == nil {
+				reftable.richTexts = make(map[int][]RichTextRun)
+			}
+			reftable.richTexts[index] = richTextFromXLSX(si.R)
 		} else {
 			reftable.AddString(si.T)
 		}
@@ -56,6 +61,12 @@ func (rt *RefTable) ResolveSharedString(index int) string {
 	return rt.indexedStrings[index]
 }
 
+// resolveRichText looks up the runs of a rich text string by numeric
+// index.  It returns nil for strings that are not rich text.
+func (rt *RefTable) resolveRichText(index int) []RichTextRun {
+	return rt.richTexts[index]
+}
+
 // AddString adds a string to the reference table and return it's
 // numeric index.  If the string already exists then it simply returns
 // the existing index.
@@ -0,0 +1,131 @@
+package xlsx
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Region is a rectangular block of cells within a Sheet, given by the
+// zero based indexes of its first and last rows and columns.
+type Region struct {
+	FirstRow int
+	FirstCol int
+	LastRow  int
+	LastCol  int
+}
+
+// Ref returns the region in A1 notation, e.g. "B2:D10".
+func (r Region) Ref() string {
+	return fmt.Sprintf("%s:%s", GetCellIDStringFromCoords(r.FirstCol, r.FirstRow), GetCellIDStringFromCoords(r.LastCol, r.LastRow))
+}
+
+func (r Region) overlaps(o Region) bool {
+	return r.FirstRow <= o.LastRow && o.FirstRow <= r.LastRow && r.FirstCol <= o.LastCol && o.FirstCol <= r.LastCol
+}
+
+func (r Region) union(o Region) Region {
+	if o.FirstRow < r.FirstRow {
+		r.FirstRow = o.FirstRow
+	}
+	if o.FirstCol < r.FirstCol {
+		r.FirstCol = o.FirstCol
+	}
+	if o.LastRow > r.LastRow {
+		r.LastRow = o.LastRow
+	}
+	if o.LastCol > r.LastCol {
+		r.LastCol = o.LastCol
+	}
+	return r
+}
+
+// hasNoContent reports whether the cell holds neither a value nor a
+// formula, whether or not it is empty in the sense of IsEmpty.  A nil
+// cell has no content.
+func (c *Cell) hasNoContent() bool {
+	return c == nil || (c.Value == "" && c.formula == "")
+}
+
+type cellCoord struct {
+	row, col int
+}
+
+func (s *Sheet) nonEmptyCells() map[cellCoord]bool {
+	cells := make(map[cellCoord]bool)
+	for r, row := range s.Rows {
+		if row == nil {
+			continue
+		}
+		for c, cell := range row.Cells {
+			if !cell.hasNoContent() {
+				cells[cellCoord{r, c}] = true
+			}
+		}
+	}
+	return cells
+}
+
+// CountNonEmptyCells returns the number of cells in the Sheet that
+// hold a value or a formula.
+func (s *Sheet) CountNonEmptyCells() int {
+	return len(s.nonEmptyCells())
+}
+
+// DataRegions returns the bounding boxes of the blocks of non-empty
+// cells in the Sheet, in the same way Excel determines the current
+// region around a cell: cells touching each other, including
+// diagonally, belong to the same block, and blocks whose bounding
+// boxes overlap are combined.  The regions are ordered by their top
+// left cell, row first.
+func (s *Sheet) DataRegions() []Region {
+	cells := s.nonEmptyCells()
+	visited := make(map[cellCoord]bool, len(cells))
+	var regions []Region
+	for start := range cells {
+		if visited[start] {
+			continue
+		}
+		visited[start] = true
+		region := Region{start.row, start.col, start.row, start.col}
+		queue := []cellCoord{start}
+		for len(queue) > 0 {
+			cur := queue[0]
+			queue = queue[1:]
+			region = region.union(Region{cur.row, cur.col, cur.row, cur.col})
+			for dr := -1; dr <= 1; dr++ {
+				for dc := -1; dc <= 1; dc++ {
+					next := cellCoord{cur.row + dr, cur.col + dc}
+					if cells[next] && !visited[next] {
+						visited[next] = true
+						queue = append(queue, next)
+					}
+				}
+			}
+		}
+		regions = append(regions, region)
+	}
+
+	// Combining two regions can make the result overlap a third, so
+	// keep going until nothing changes.
+	for merged := true; merged; {
+		merged = false
+		for i := 0; i < len(regions) && !merged; i++ {
+			for j := i + 1; j < len(regions); j++ {
+				if regions[i].overlaps(regions[j]) {
+					regions[i] = regions[i].union(regions[j])
+					regions = append(regions[:j], regions[j+1:]...)
+					merged = true
+					break
+				}
+			}
+		}
+	}
+
+	sort.Slice(regions, func(i, j int) bool {
+		if regions[i].FirstRow != regions[j].FirstRow {
+			return regions[i].FirstRow < regions[j].FirstRow
+		}
+		return regions[i].FirstCol < regions[j].FirstCol
+	})
+	return regions
+}
@@ -0,0 +1,63 @@
+package xlsx
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestDataRegions(t *testing.T) {
+	c := qt.New(t)
+
+	c.Run("EmptySheet", func(c *qt.C) {
+		f := NewFile()
+		sheet, err := f.AddSheet("Sheet1")
+		c.Assert(err, qt.IsNil)
+		c.Assert(sheet.DataRegions(), qt.HasLen, 0)
+		c.Assert(sheet.CountNonEmptyCells(), qt.Equals, 0)
+	})
+
+	c.Run("TwoSeparatedBlocks", func(c *qt.C) {
+		f := NewFile()
+		sheet, err := f.AddSheet("Sheet1")
+		c.Assert(err, qt.IsNil)
+		// A 2x2 block at B2:C3 ...
+		sheet.Cell(1, 1).SetString("a")
+		sheet.Cell(1, 2).SetString("b")
+		sheet.Cell(2, 1).SetInt(1)
+		sheet.Cell(2, 2).SetInt(2)
+		// ... and a second block at F6:G8, with a diagonal step.
+		sheet.Cell(5, 5).SetString("x")
+		sheet.Cell(6, 6).SetString("y")
+		sheet.Cell(7, 6).SetFormula("1+1")
+		// Cells that exist but are empty do not count.
+		sheet.Cell(3, 3)
+
+		c.Assert(sheet.CountNonEmptyCells(), qt.Equals, 7)
+		regions := sheet.DataRegions()
+		c.Assert(regions, qt.DeepEquals, []Region{
+			{FirstRow: 1, FirstCol: 1, LastRow: 2, LastCol: 2},
+			{FirstRow: 5, FirstCol: 5, LastRow: 7, LastCol: 6},
+		})
+		c.Assert(regions[0].Ref(), qt.Equals, "B2:C3")
+		c.Assert(regions[1].Ref(), qt.Equals, "F6:G8")
+	})
+
+	c.Run("OverlappingBoundingBoxesAreCombined", func(c *qt.C) {
+		f := NewFile()
+		sheet, err := f.AddSheet("Sheet1")
+		c.Assert(err, qt.IsNil)
+		// An L shape whose bounding box encloses a lone cell.
+		sheet.Cell(0, 0).SetString("a")
+		sheet.Cell(1, 0).SetString("a")
+		sheet.Cell(2, 0).SetString("a")
+		sheet.Cell(2, 1).SetString("a")
+		sheet.Cell(2, 2).SetString("a")
+		sheet.Cell(2, 3).SetString("a")
+		sheet.Cell(0, 3).SetString("b")
+
+		c.Assert(sheet.DataRegions(), qt.DeepEquals, []Region{
+			{FirstRow: 0, FirstCol: 0, LastRow: 2, LastCol: 3},
+		})
+	})
+}
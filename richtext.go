@@ -0,0 +1,119 @@
+package xlsx
+
+import "strconv"
+
+// RichTextRun is a run of text within a rich text string, such as a
+// shared string or a comment, that is formatted with its own font.
+type RichTextRun struct {
+	// Font is the formatting of the run.  It is nil when the run
+	// takes on the formatting of the cell.
+	Font *RichTextFont
+	Text string
+}
+
+// RichTextFont is the formatting of a RichTextRun.  Color is an ARGB
+// hex string, and Underline is the style of the underline, such as
+// "single" or "double", or empty if the run is not underlined.
+type RichTextFont struct {
+	Name      string
+	Size      float64
+	Bold      bool
+	Italic    bool
+	Strike    bool
+	Underline string
+	Color     string
+}
+
+// richTextFromXLSX converts the runs of a rich text string into
+// RichTextRuns.
+func richTextFromXLSX(runs []xlsxR) []RichTextRun {
+	if len(runs) == 0 {
+		return nil
+	}
+	richText := make([]RichTextRun, len(runs))
+	for i, r := range runs {
+		richText[i].Text = r.T
+		if r.RPr == nil {
+			continue
+		}
+		font := &RichTextFont{
+			Bold:   xlsxValIsTrue(r.RPr.B),
+			Italic: xlsxValIsTrue(r.RPr.I),
+			Strike: xlsxValIsTrue(r.RPr.Strike),
+		}
+		if r.RPr.RFont != nil {
+			font.Name = r.RPr.RFont.Val
+		}
+		if r.RPr.Sz != nil {
+			font.Size, _ = strconv.ParseFloat(r.RPr.Sz.Val, 64)
+		}
+		if r.RPr.U != nil {
+			font.Underline = r.RPr.U.Val
+			if font.Underline == "" {
+				font.Underline = "single"
+			} else if font.Underline == "none" {
+				font.Underline = ""
+			}
+		}
+		if r.RPr.Color != nil {
+			font.Color = r.RPr.Color.RGB
+		}
+		richText[i].Font = font
+	}
+	return richText
+}
+
+// richTextToXLSX converts RichTextRuns into the runs of a rich text
+// string.
+func richTextToXLSX(richText []RichTextRun) []xlsxR {
+	runs := make([]xlsxR, len(richText))
+	for i, run := range richText {
+		runs[i].T = run.Text
+		font := run.Font
+		if font == nil {
+			continue
+		}
+		rPr := &xlsxRunProperties{}
+		if font.Bold {
+			rPr.B = &xlsxVal{}
+		}
+		if font.Italic {
+			rPr.I = &xlsxVal{}
+		}
+		if font.Strike {
+			rPr.Strike = &xlsxVal{}
+		}
+		if font.Color != "" {
+			rPr.Color = &xlsxColor{RGB: font.Color}
+		}
+		if font.Size > 0 {
+			rPr.Sz = &xlsxVal{Val: strconv.FormatFloat(font.Size, 'f', -1, 64)}
+		}
+		switch font.Underline {
+		case "":
+		case "single":
+			rPr.U = &xlsxVal{}
+		default:
+			rPr.U = &xlsxVal{Val: font.Underline}
+		}
+		if font.Name != "" {
+			rPr.RFont = &xlsxVal{Val: font.Name}
+		}
+		runs[i].RPr = rPr
+	}
+	return runs
+}
+
+// xlsxValIsTrue reports whether a boolean property such as b or i is
+// switched on.  The property is on when it is present without a val
+// attribute.
+func xlsxValIsTrue(v *xlsxVal) bool {
+	if v == nil {
+		return false
+	}
+	switch v.Val {
+	case "0", "false":
+		return false
+	}
+	return true
+}
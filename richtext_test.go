@@ -0,0 +1,37 @@
+package xlsx
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestReadRichText(t *testing.T) {
+	c := qt.New(t)
+
+	f := NewFile()
+	sheet, err := f.AddSheet("Sheet1")
+	c.Assert(err, qt.IsNil)
+	sheet.Cell(0, 0).SetString("placeholder")
+	sheet.Cell(0, 1).SetString("plain")
+	parts, err := f.MarshallParts()
+	c.Assert(err, qt.IsNil)
+
+	parts["xl/sharedStrings.xml"] = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" count="2" uniqueCount="2"><si><r><rPr><b/><sz val="11"/><color rgb="FFFF0000"/><rFont val="Calibri"/></rPr><t>Bold</t></r><r><rPr><i/><u/><sz val="9.5"/></rPr><t xml:space="preserve"> and italic</t></r><r><t>!</t></r></si><si><t>plain</t></si></sst>`
+
+	f, err = OpenBinary(zipParts(c, parts))
+	c.Assert(err, qt.IsNil)
+
+	cell := f.Sheets[0].Cell(0, 0)
+	c.Assert(cell.Value, qt.Equals, "Bold and italic!")
+	c.Assert(cell.RichText, qt.DeepEquals, []RichTextRun{
+		{Font: &RichTextFont{Name: "Calibri", Size: 11, Bold: true, Color: "FFFF0000"}, Text: "Bold"},
+		{Font: &RichTextFont{Size: 9.5, Italic: true, Underline: "single"}, Text: " and italic"},
+		{Text: "!"},
+	})
+
+	cell = f.Sheets[0].Cell(0, 1)
+	c.Assert(cell.Value, qt.Equals, "plain")
+	c.Assert(cell.RichText, qt.IsNil)
+}
@@ -6,7 +6,10 @@ type Row struct {
 	Sheet        *Sheet
 	Height       float64
 	OutlineLevel uint8
-	isCustom     bool
+	// Collapsed is set on the row that sums up a group of rows when
+	// the group is collapsed.
+	Collapsed bool
+	isCustom  bool
 }
 
 func (r *Row) SetHeight(ht float64) {
@@ -19,8 +22,74 @@ func (r *Row) SetHeightCM(ht float64) {
 	r.isCustom = true
 }
 
+// GetHeight returns the height of the row in points.  Rows that have
+// no height of their own have the default row height of their sheet.
+func (r *Row) GetHeight() float64 {
+	if r.Height == 0 && r.Sheet != nil {
+		return r.Sheet.SheetFormat.DefaultRowHeight
+	}
+	return r.Height
+}
+
 func (r *Row) AddCell() *Cell {
 	cell := NewCell(r)
 	r.Cells = append(r.Cells, cell)
 	return cell
 }
+
+// CellVisitorOption changes the way Row.ForEachCell visits the cells
+// of a row.
+type CellVisitorOption func(*cellVisitorFlags)
+
+type cellVisitorFlags struct {
+	skipTrailingEmptyCells bool
+}
+
+// SkipTrailingEmptyCells is a CellVisitorOption that makes
+// Row.ForEachCell stop at the last cell of the row that is not empty.
+func SkipTrailingEmptyCells() CellVisitorOption {
+	return func(flags *cellVisitorFlags) {
+		flags.skipTrailingEmptyCells = true
+	}
+}
+
+// ForEachCell calls fn for each cell of the row in column order, up to
+// the widest column of the row or its sheet, so that the nth call is
+// always for the nth column.  Cells missing from a sparse row are
+// added to it as empty cells when they are visited.  If fn returns an
+// error, ForEachCell stops and returns that error.
+func (r *Row) ForEachCell(fn func(c *Cell) error, opts ...CellVisitorOption) error {
+	var flags cellVisitorFlags
+	for _, opt := range opts {
+		opt(&flags)
+	}
+
+	maxCol := len(r.Cells)
+	if r.Sheet != nil && r.Sheet.MaxCol > maxCol {
+		maxCol = r.Sheet.MaxCol
+	}
+	if flags.skipTrailingEmptyCells {
+		for maxCol > 0 {
+			if maxCol <= len(r.Cells) {
+				cell := r.Cells[maxCol-1]
+				if cell != nil && !cell.IsEmpty() {
+					break
+				}
+			}
+			maxCol--
+		}
+	}
+
+	for i := 0; i < maxCol; i++ {
+		if i >= len(r.Cells) {
+			r.AddCell().absent = true
+		} else if r.Cells[i] == nil {
+			r.Cells[i] = NewCell(r)
+			r.Cells[i].absent = true
+		}
+		if err := fn(r.Cells[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
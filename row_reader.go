@@ -0,0 +1,219 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// StreamRows returns an iterator over the rows of the named sheet,
+// which decodes them from the worksheet one at a time as they are
+// asked for instead of holding them all in memory.  Each call returns
+// the next row, with the rows the worksheet leaves out returned empty
+// so that the nth call returns the nth row, and io.EOF once there are
+// no more rows.  The part of the worksheet is closed when the iterator
+// returns io.EOF or an error; to stop reading before then, call Close
+// on the File.
+//
+// Shared strings and styles are resolved as they are for the rows of a
+// Sheet.  The merged cells of the sheet are listed after its rows, so
+// the HMerge and VMerge of the Cells are not set.  To read a large
+// sheet without also parsing it up front, open the file with
+// OpenReaderAtSheets and no sheet names.  A File opened with OpenFile
+// is opened again for each iterator, as it is closed once it is read;
+// one read with ReadZip cannot be streamed.
+func (f *File) StreamRows(sheetName string) (func() (*Row, error), error) {
+	part, ok := f.worksheetsByName[sheetName]
+	if !ok {
+		return nil, fmt.Errorf("sheet %q was not read from a file", sheetName)
+	}
+	r := &rowReader{
+		file:           f,
+		sheet:          f.Sheet[sheetName],
+		cols:           &ColStore{},
+		sharedFormulas: map[int]sharedFormula{},
+	}
+	if f.zipClosed {
+		if f.path == "" {
+			return nil, fmt.Errorf("the zip of sheet %q is closed and cannot be opened again", sheetName)
+		}
+		z, err := zip.OpenReader(f.path)
+		if err != nil {
+			return nil, err
+		}
+		part = nil
+		for _, zf := range z.File {
+			if zf.Name == f.worksheetsByName[sheetName].Name {
+				part = zf
+				break
+			}
+		}
+		if part == nil {
+			z.Close()
+			return nil, fmt.Errorf("%s has changed: its worksheet %q is missing", f.path, sheetName)
+		}
+		r.zip = z
+	}
+	rc, err := part.Open()
+	if err != nil {
+		if r.zip != nil {
+			r.zip.Close()
+		}
+		return nil, err
+	}
+	r.rc = rc
+	r.decoder = xml.NewDecoder(rc)
+
+	f.rowReadersLock.Lock()
+	if f.rowReaders == nil {
+		f.rowReaders = map[*rowReader]struct{}{}
+	}
+	f.rowReaders[r] = struct{}{}
+	f.rowReadersLock.Unlock()
+	return r.next, nil
+}
+
+// Close closes the worksheets that the iterators of StreamRows are
+// still reading, after which those iterators return an error.  It
+// need only be called when rows are no longer read before the end of
+// a sheet.  The File itself can still be used.
+func (f *File) Close() error {
+	f.rowReadersLock.Lock()
+	readers := f.rowReaders
+	f.rowReaders = nil
+	f.rowReadersLock.Unlock()
+
+	var firstErr error
+	for r := range readers {
+		if err := r.close(errors.New("the rows were closed with the File")); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// rowReader decodes the rows of a worksheet one at a time for
+// StreamRows.
+type rowReader struct {
+	rc io.ReadCloser
+	// zip is the zip the worksheet was opened from again, if it was.
+	zip            *zip.ReadCloser
+	decoder        *xml.Decoder
+	file           *File
+	sheet          *Sheet
+	cols           *ColStore
+	minCol         int
+	sharedFormulas map[int]sharedFormula
+	// rowIndex is the index of the row returned next.
+	rowIndex int
+	// pending is the raw row that was read ahead of the empty rows
+	// before it.
+	pending *xlsxRow
+	// err is returned by every call once the rows are done with.
+	err error
+}
+
+// close closes the worksheet, and its zip if it was opened again, so
+// that every later call returns err.
+func (r *rowReader) close(err error) error {
+	if r.err != nil {
+		return nil
+	}
+	r.err = err
+	closeErr := r.rc.Close()
+	if r.zip != nil {
+		if err := r.zip.Close(); err != nil && closeErr == nil {
+			closeErr = err
+		}
+	}
+	r.file.rowReadersLock.Lock()
+	delete(r.file.rowReaders, r)
+	r.file.rowReadersLock.Unlock()
+	return closeErr
+}
+
+// next returns the next row of the worksheet.
+func (r *rowReader) next() (row *Row, err error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			row = nil
+			if e, ok := e.(error); ok {
+				err = e
+			} else {
+				err = fmt.Errorf("unexpected error reading row %d: %v", r.rowIndex+1, e)
+			}
+		}
+		if err != nil {
+			r.close(err)
+		}
+	}()
+
+	if r.pending == nil {
+		r.pending, err = r.readRawRow()
+		if err != nil {
+			return nil, err
+		}
+		if r.pending.R == 0 {
+			r.pending.R = rowNumberFromCells(*r.pending)
+		}
+	}
+	// Some spreadsheets will omit blank rows from the stored data.
+	if r.pending.R > r.rowIndex+1 {
+		r.rowIndex++
+		return makeEmptyRow(r.sheet), nil
+	}
+	row = readRowFromRaw(*r.pending, r.file, r.sheet, r.cols, nil, r.minCol, r.sharedFormulas)
+	r.pending = nil
+	r.rowIndex++
+	return row, nil
+}
+
+// readRawRow decodes the worksheet up to its next row, taking in the
+// dimension and the column definitions that come before the rows.  It
+// returns io.EOF at the end of the rows.
+func (r *rowReader) readRawRow() (*xlsxRow, error) {
+	for {
+		token, err := r.decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "dimension":
+				var dimension xlsxDimension
+				if err := r.decoder.DecodeElement(&dimension, &t); err != nil {
+					return nil, err
+				}
+				if strings.Count(dimension.Ref, cellRangeChar) == 1 {
+					r.minCol, _, _, _, err = getMaxMinFromDimensionRef(dimension.Ref)
+					if err != nil {
+						return nil, err
+					}
+				}
+			case "col":
+				var rawcol xlsxCol
+				if err := r.decoder.DecodeElement(&rawcol, &t); err != nil {
+					return nil, err
+				}
+				r.cols.Add(readColFromRaw(rawcol, r.file))
+			case "row":
+				rawrow := new(xlsxRow)
+				if err := r.decoder.DecodeElement(rawrow, &t); err != nil {
+					return nil, err
+				}
+				return rawrow, nil
+			}
+		case xml.EndElement:
+			if t.Name.Local == "sheetData" {
+				return nil, io.EOF
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestStreamRows(t *testing.T) {
+	c := qt.New(t)
+
+	for _, name := range []string{
+		"testcelltypes.xlsx",
+		"empty_rows.xlsx",
+		"testfile.xlsx",
+		"inlineStrings.xlsx",
+	} {
+		c.Run(name, func(c *qt.C) {
+			data, err := ioutil.ReadFile("./testdocs/" + name)
+			c.Assert(err, qt.IsNil)
+			want, err := OpenBinary(data)
+			c.Assert(err, qt.IsNil)
+			sheet := want.Sheets[0]
+
+			// None of the sheets are parsed up front.
+			file, err := OpenReaderAtSheets(bytes.NewReader(data), int64(len(data)), nil)
+			c.Assert(err, qt.IsNil)
+			c.Assert(file.Sheets[0].Rows, qt.HasLen, 0)
+			next, err := file.StreamRows(sheet.Name)
+			c.Assert(err, qt.IsNil)
+
+			var rows []*Row
+			for {
+				row, err := next()
+				if err == io.EOF {
+					break
+				}
+				c.Assert(err, qt.IsNil)
+				c.Assert(row.Sheet, qt.Equals, file.Sheets[0])
+				rows = append(rows, row)
+			}
+			c.Assert(len(rows) > 0, qt.Equals, true)
+			c.Assert(len(rows) <= len(sheet.Rows), qt.Equals, true)
+			for i, row := range sheet.Rows {
+				if i >= len(rows) {
+					// Only the trailing empty rows of the dimension
+					// are not streamed.
+					c.Assert(row.Cells, qt.HasLen, 0)
+					continue
+				}
+				c.Assert(rows[i].Cells, qt.HasLen, len(row.Cells), qt.Commentf("row %d", i))
+				for j, cell := range row.Cells {
+					c.Assert(rows[i].Cells[j].Value, qt.Equals, cell.Value, qt.Commentf("cell %d, %d", j, i))
+					c.Assert(rows[i].Cells[j].NumFmt, qt.Equals, cell.NumFmt, qt.Commentf("cell %d, %d", j, i))
+				}
+			}
+
+			// The iterator keeps returning io.EOF.
+			_, err = next()
+			c.Assert(err, qt.Equals, io.EOF)
+		})
+	}
+
+	file := NewFile()
+	_, err := file.AddSheet("Sheet1")
+	c.Assert(err, qt.IsNil)
+	_, err = file.StreamRows("Sheet1")
+	c.Assert(err, qt.ErrorMatches, `sheet "Sheet1" was not read from a file`)
+}
+
+func TestStreamRowsOpenFile(t *testing.T) {
+	c := qt.New(t)
+
+	// The zip is closed once the file is read, so it is opened again.
+	file, err := OpenFile("./testdocs/testfile.xlsx")
+	c.Assert(err, qt.IsNil)
+	next, err := file.StreamRows(file.Sheets[0].Name)
+	c.Assert(err, qt.IsNil)
+	count := 0
+	for {
+		row, err := next()
+		if err == io.EOF {
+			break
+		}
+		c.Assert(err, qt.IsNil)
+		if count == 0 {
+			c.Assert(row.Cells[0].Value, qt.Equals, file.Sheets[0].Rows[0].Cells[0].Value)
+		}
+		count++
+	}
+	c.Assert(count > 0, qt.Equals, true)
+	c.Assert(file.rowReaders, qt.HasLen, 0)
+
+	// Close stops the iterators that have not reached the end.
+	next, err = file.StreamRows(file.Sheets[0].Name)
+	c.Assert(err, qt.IsNil)
+	_, err = next()
+	c.Assert(err, qt.IsNil)
+	c.Assert(file.rowReaders, qt.HasLen, 1)
+	c.Assert(file.Close(), qt.IsNil)
+	c.Assert(file.rowReaders, qt.HasLen, 0)
+	_, err = next()
+	c.Assert(err, qt.ErrorMatches, "the rows were closed with the File")
+
+	// A zip that was closed by the caller cannot be opened again.
+	z, err := zip.OpenReader("./testdocs/testfile.xlsx")
+	c.Assert(err, qt.IsNil)
+	file, err = ReadZip(z)
+	c.Assert(err, qt.IsNil)
+	_, err = file.StreamRows(file.Sheets[0].Name)
+	c.Assert(err, qt.ErrorMatches, `the zip of sheet ".*" is closed and cannot be opened again`)
+}
@@ -1,6 +1,9 @@
 package xlsx
 
 import (
+	"bytes"
+	"errors"
+
 	. "gopkg.in/check.v1"
 )
 
@@ -18,3 +21,69 @@ func (r *RowSuite) TestAddCell(c *C) {
 	c.Assert(cell, NotNil)
 	c.Assert(len(row.Cells), Equals, 1)
 }
+
+// Test that rows without a height of their own have the default row
+// height of their sheet.
+func (r *RowSuite) TestGetHeight(c *C) {
+	f := NewFile()
+	sheet, err := f.AddSheet("MySheet")
+	c.Assert(err, IsNil)
+	sheet.SheetFormat.DefaultRowHeight = 20
+	sheet.SheetFormat.DefaultColWidth = 12
+	sheet.Cell(0, 0).SetString("tall")
+	sheet.Row(0).SetHeight(30)
+	sheet.Cell(1, 0).SetString("default")
+	// SetColWidth counts columns from one, GetColWidth from zero.
+	sheet.SetColWidth(2, 2, 40)
+
+	var buf bytes.Buffer
+	c.Assert(f.Write(&buf), IsNil)
+	f, err = OpenBinary(buf.Bytes())
+	c.Assert(err, IsNil)
+	sheet = f.Sheets[0]
+	c.Assert(sheet.Row(0).GetHeight(), Equals, 30.0)
+	c.Assert(sheet.Row(1).GetHeight(), Equals, 20.0)
+	c.Assert(sheet.GetColWidth(0), Equals, 12.0)
+	c.Assert(sheet.GetColWidth(1), Equals, 40.0)
+}
+
+// Test that ForEachCell visits every column of a sparse row, and that
+// it can be told to stop at the last cell that isn't empty.
+func (r *RowSuite) TestForEachCell(c *C) {
+	f := NewFile()
+	sheet, err := f.AddSheet("MySheet")
+	c.Assert(err, IsNil)
+	sheet.MaxCol = 4
+	row := sheet.AddRow()
+	row.AddCell().SetString("a")
+	row.AddCell()
+	row.AddCell().SetString("c")
+
+	var values []string
+	err = row.ForEachCell(func(cell *Cell) error {
+		values = append(values, cell.Value)
+		return nil
+	})
+	c.Assert(err, IsNil)
+	c.Assert(values, DeepEquals, []string{"a", "", "c", ""})
+	c.Assert(row.Cells, HasLen, 4)
+	c.Assert(row.Cells[1].IsEmpty(), Equals, false)
+	c.Assert(row.Cells[3].IsEmpty(), Equals, true)
+
+	values = nil
+	err = row.ForEachCell(func(cell *Cell) error {
+		values = append(values, cell.Value)
+		return nil
+	}, SkipTrailingEmptyCells())
+	c.Assert(err, IsNil)
+	c.Assert(values, DeepEquals, []string{"a", "", "c"})
+
+	stop := errors.New("stop")
+	count := 0
+	err = row.ForEachCell(func(cell *Cell) error {
+		count++
+		return stop
+	})
+	c.Assert(err, Equals, stop)
+	c.Assert(count, Equals, 1)
+}
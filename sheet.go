@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
 // Sheet is a high level structure intended to provide user access to
@@ -23,10 +25,49 @@ type Sheet struct {
 	AutoFilter      *AutoFilter
 	Relations       []Relation
 	DataValidations []*xlsxDataValidation
+	ColorScales     []ColorScale
+	charts          []Chart
+	background      *sheetBackground
+	// codeName is the name VBA code uses for the sheet.
+	codeName string
+	// declaredDimension is the ref of the dimension element of the
+	// sheet as it was read, if it had one.
+	declaredDimension string
+	printOptions      PrintOptions
+	// veryHidden is set, along with Hidden, on sheets that can only be
+	// shown again by code, see SheetStateVeryHidden.
+	veryHidden bool
+	// rowBreaks and colBreaks are the zero based indexes of the rows
+	// and columns followed by a manual page break, in order.
+	rowBreaks []int
+	colBreaks []int
+	// extLst holds the content of the extension list of the sheet as
+	// it was read, which is written back as it is.
+	extLst string
+	// omitDefaultSheetView drops the sheetViews element of the sheet
+	// when it has no views of its own, see
+	// StreamFileBuilder.SetOmitDefaultSheetView.
+	omitDefaultSheetView bool
+	// streamOutlineLevelRow is the highest outline level of the rows
+	// that are to be streamed to the sheet, see
+	// StreamFileBuilder.SetMaxRowOutlineLevel.
+	streamOutlineLevelRow uint8
 }
 
 type SheetView struct {
-	Pane *Pane
+	Pane      *Pane
+	Selection *Selection
+	// TopLeftCell is the cell in the top left corner of the view, or
+	// of its top left pane if it is split.
+	TopLeftCell string
+}
+
+// Selection is the selected cells of a sheet view.  ActiveCell is the
+// cell with the cursor, and SQRef the selected ranges, such as "A1:B4"
+// or "D2".
+type Selection struct {
+	ActiveCell string
+	SQRef      []string
 }
 
 type Pane struct {
@@ -44,6 +85,40 @@ type SheetFormat struct {
 	OutlineLevelRow  uint8
 }
 
+// SheetState is whether a sheet is shown as a tab of the workbook.
+type SheetState string
+
+const (
+	SheetStateVisible SheetState = sheetStateVisible
+	// SheetStateHidden sheets can be shown again by the user.
+	SheetStateHidden SheetState = sheetStateHidden
+	// SheetStateVeryHidden sheets cannot be shown again from the
+	// user interface of spreadsheet applications, only by code.
+	SheetStateVeryHidden SheetState = sheetStateVeryHidden
+)
+
+// validate returns an error if state is not one of the sheet states.
+func (state SheetState) validate() error {
+	switch state {
+	case SheetStateVisible, SheetStateHidden, SheetStateVeryHidden:
+		return nil
+	}
+	return fmt.Errorf("invalid sheet state %q", state)
+}
+
+// PrintOptions are how the sheet is laid out on the page when it is
+// printed.
+type PrintOptions struct {
+	// HorizontalCentered and VerticalCentered center the printed
+	// cells on the page.
+	HorizontalCentered bool
+	VerticalCentered   bool
+	// GridLines prints the grid lines between the cells.
+	GridLines bool
+	// Headings prints the row numbers and column letters.
+	Headings bool
+}
+
 type AutoFilter struct {
 	TopLeftCell     string
 	BottomRightCell string
@@ -138,6 +213,14 @@ func (s *Sheet) Row(idx int) *Row {
 	return s.Rows[idx]
 }
 
+// SetRowHeight sets the height in points of the row at the given
+// (zero based) index, adding rows to the sheet as needed.  The height
+// is kept even if the row is also hidden, so that it is restored when
+// the row is shown again.
+func (s *Sheet) SetRowHeight(idx int, height float64) {
+	s.Row(idx).SetHeight(height)
+}
+
 // Return the Col that applies to this Column index, or return nil if no such Col exists
 func (s *Sheet) Col(idx int) *Col {
 	if s.Cols == nil {
@@ -164,12 +247,233 @@ func (s *Sheet) Cell(row, col int) *Cell {
 
 	r := s.Rows[row]
 	for len(r.Cells) <= col {
-		r.AddCell()
+		r.AddCell().absent = true
 	}
 
 	return r.Cells[col]
 }
 
+// RangeCells calls fn for every position in the A1 style range rng,
+// such as "B2:C3", row by row from the top left corner.  col and row
+// are the zero based coordinates of the position.  Positions the sheet
+// has no cell for, or only an empty one (see Cell.IsEmpty), are passed
+// as a nil cell.  Unlike Sheet.Cell, RangeCells never adds rows or
+// cells to the sheet.  A single cell reference is taken as a range of
+// one cell.
+func (s *Sheet) RangeCells(rng string, fn func(col, row int, cell *Cell)) error {
+	minCol, minRow, maxCol, maxRow, err := parseRange(rng)
+	if err != nil {
+		return err
+	}
+	for row := minRow; row <= maxRow; row++ {
+		var cells []*Cell
+		if row < len(s.Rows) && s.Rows[row] != nil {
+			cells = s.Rows[row].Cells
+		}
+		for col := minCol; col <= maxCol; col++ {
+			var cell *Cell
+			if col < len(cells) && cells[col] != nil && !cells[col].IsEmpty() {
+				cell = cells[col]
+			}
+			fn(col, row, cell)
+		}
+	}
+	return nil
+}
+
+// ClearRangeStyle clears the style of every cell in the A1 style range
+// rng, such as "B2:C3", see Cell.ClearStyle.  Positions the sheet has
+// no cell for are left as they are.
+func (s *Sheet) ClearRangeStyle(rng string) error {
+	minCol, minRow, maxCol, maxRow, err := parseRange(rng)
+	if err != nil {
+		return err
+	}
+	for row := minRow; row <= maxRow && row < len(s.Rows); row++ {
+		if s.Rows[row] == nil {
+			continue
+		}
+		cells := s.Rows[row].Cells
+		for col := minCol; col <= maxCol && col < len(cells); col++ {
+			if cells[col] != nil {
+				cells[col].ClearStyle()
+			}
+		}
+	}
+	return nil
+}
+
+// SetRangeOutsideBorder draws border around the A1 style range rng,
+// such as "B2:D4".  The cells along each side of the range take the
+// style and color border has for that side, and the edges between the
+// cells within the range are left as they are, as are the diagonals.
+// Cells are added for the positions along the sides the sheet has none
+// for.  The style of each cell is copied before it is changed, since
+// cells may share their styles.
+func (s *Sheet) SetRangeOutsideBorder(rng string, border Border) error {
+	minCol, minRow, maxCol, maxRow, err := parseRange(rng)
+	if err != nil {
+		return err
+	}
+	for row := minRow; row <= maxRow; row++ {
+		for col := minCol; col <= maxCol; col++ {
+			if row != minRow && row != maxRow && col != minCol {
+				// Only the last cell of the rows in between is on a side.
+				col = maxCol
+			}
+			cell := s.Cell(row, col)
+			style := *cell.GetStyle()
+			if col == minCol {
+				style.Border.Left, style.Border.LeftColor = border.Left, border.LeftColor
+			}
+			if col == maxCol {
+				style.Border.Right, style.Border.RightColor = border.Right, border.RightColor
+			}
+			if row == minRow {
+				style.Border.Top, style.Border.TopColor = border.Top, border.TopColor
+			}
+			if row == maxRow {
+				style.Border.Bottom, style.Border.BottomColor = border.Bottom, border.BottomColor
+			}
+			style.ApplyBorder = true
+			cell.SetStyle(&style)
+		}
+	}
+	return nil
+}
+
+// parseRange returns the zero based coordinates of the corners of the
+// A1 style range rng, which may also be a single cell.
+func parseRange(rng string) (minCol, minRow, maxCol, maxRow int, err error) {
+	parts := strings.SplitN(rng, cellRangeChar, 2)
+	if len(parts) == 1 {
+		parts = append(parts, parts[0])
+	}
+	minCol, minRow, err = GetCoordsFromCellIDString(parts[0])
+	if err != nil {
+		return 0, 0, 0, 0, fmt.Errorf("invalid range %q: %v", rng, err)
+	}
+	maxCol, maxRow, err = GetCoordsFromCellIDString(parts[1])
+	if err != nil {
+		return 0, 0, 0, 0, fmt.Errorf("invalid range %q: %v", rng, err)
+	}
+	if minCol < 0 || minRow < 0 || maxCol < 0 || maxRow < 0 {
+		return 0, 0, 0, 0, fmt.Errorf("invalid range %q", rng)
+	}
+	if minCol > maxCol {
+		minCol, maxCol = maxCol, minCol
+	}
+	if minRow > maxRow {
+		minRow, maxRow = maxRow, minRow
+	}
+	return minCol, minRow, maxCol, maxRow, nil
+}
+
+// GetColWidth returns the width of the column at the zero based index
+// col.  Columns that have no width of their own have the default
+// column width of the sheet.
+func (s *Sheet) GetColWidth(col int) float64 {
+	if s.Cols != nil {
+		if c := s.Col(col); c != nil && c.Width != 0 {
+			return c.Width
+		}
+	}
+	return s.SheetFormat.DefaultColWidth
+}
+
+// State returns whether the sheet is visible, hidden or very hidden.
+func (s *Sheet) State() SheetState {
+	switch {
+	case !s.Hidden:
+		return SheetStateVisible
+	case s.veryHidden:
+		return SheetStateVeryHidden
+	}
+	return SheetStateHidden
+}
+
+// SetState sets whether the sheet is visible, hidden or very hidden.
+// It sets Hidden accordingly.
+func (s *Sheet) SetState(state SheetState) error {
+	if err := state.validate(); err != nil {
+		return err
+	}
+	s.Hidden = state != SheetStateVisible
+	s.veryHidden = state == SheetStateVeryHidden
+	return nil
+}
+
+// PrintOptions returns how the sheet is laid out when it is printed.
+func (s *Sheet) PrintOptions() PrintOptions {
+	return s.printOptions
+}
+
+// SetPrintOptions sets how the sheet is laid out when it is printed.
+func (s *Sheet) SetPrintOptions(options PrintOptions) {
+	s.printOptions = options
+}
+
+// DeclaredDimension returns the range, such as "A1:D10", that the
+// dimension element of the sheet declared the used cells to be when the
+// file was read.  It is taken as it was written, so it may not match
+// MaxRow and MaxCol, which are worked out from the cells themselves.
+// The bool is false if the sheet declared no dimension.
+func (s *Sheet) DeclaredDimension() (string, bool) {
+	return s.declaredDimension, s.declaredDimension != ""
+}
+
+// textTableMaxWidth is the widest a column of the table returned by
+// Sheet.ToTextTable gets.
+const textTableMaxWidth = 30
+
+// ToTextTable returns the rows of the sheet as a table of plain text,
+// which is handy for looking at a sheet while debugging.  Each line is
+// a row, holding the formatted values of its cells lined up in columns
+// that are separated by " | ".  Line breaks and other runs of white
+// space in a value are shown as a single space, and values longer than
+// 30 characters are cut short with an ellipsis.
+func (s *Sheet) ToTextTable() string {
+	var table [][]string
+	var widths []int
+	for _, row := range s.Rows {
+		var values []string
+		if row != nil {
+			for c, cell := range row.Cells {
+				value := ""
+				if cell != nil {
+					value = strings.Join(strings.Fields(cell.String()), " ")
+				}
+				if runes := []rune(value); len(runes) > textTableMaxWidth {
+					value = string(runes[:textTableMaxWidth-1]) + "…"
+				}
+				if c >= len(widths) {
+					widths = append(widths, 0)
+				}
+				if n := utf8.RuneCountInString(value); n > widths[c] {
+					widths[c] = n
+				}
+				values = append(values, value)
+			}
+		}
+		table = append(table, values)
+	}
+
+	var b strings.Builder
+	for _, values := range table {
+		var line strings.Builder
+		for c, value := range values {
+			if c > 0 {
+				line.WriteString(" | ")
+			}
+			line.WriteString(value)
+			line.WriteString(strings.Repeat(" ", widths[c]-utf8.RuneCountInString(value)))
+		}
+		b.WriteString(strings.TrimRight(line.String(), " "))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
 //Set the parameters of a column.  Parameters are passed as a pointer
 //to a Col structure which you much construct yourself.
 func (s *Sheet) SetColParameters(col *Col) {
@@ -216,7 +520,10 @@ func (s *Sheet) setCol(min, max int, setter func(col *Col)) {
 			setter(newCol)
 			s.Cols.Add(newCol)
 		default:
-			newCol := col.copyToRange(min, max)
+			// The column lies within the range, which may
+			// be made up of several columns, so only this
+			// one is changed.
+			newCol := col.copyToRange(col.Min, col.Max)
 			setter(newCol)
 			s.Cols.Add(newCol)
 
@@ -247,6 +554,44 @@ func (s *Sheet) SetType(minCol, maxCol int, cellType CellType) {
 
 }
 
+// MergeRange is a range of merged cells, such as "B2:D3", along with
+// the coordinates, counting from 0, of the cells in its top left and
+// bottom right corners.
+type MergeRange struct {
+	Ref      string
+	StartCol int
+	StartRow int
+	EndCol   int
+	EndRow   int
+}
+
+// MergedCells returns the ranges of merged cells of the sheet, in the
+// order of the cells in their top left corners, row by row.  These are
+// the ranges of the mergeCells element of a sheet that was read, and
+// those set with Cell.Merge.
+func (s *Sheet) MergedCells() []MergeRange {
+	var ranges []MergeRange
+	for r, row := range s.Rows {
+		if row == nil {
+			continue
+		}
+		for c, cell := range row.Cells {
+			if cell == nil || (cell.HMerge <= 0 && cell.VMerge <= 0) {
+				continue
+			}
+			mr := MergeRange{
+				StartCol: c,
+				StartRow: r,
+				EndCol:   c + cell.HMerge,
+				EndRow:   r + cell.VMerge,
+			}
+			mr.Ref = GetCellIDStringFromCoords(mr.StartCol, mr.StartRow) + cellRangeChar + GetCellIDStringFromCoords(mr.EndCol, mr.EndRow)
+			ranges = append(ranges, mr)
+		}
+	}
+	return ranges
+}
+
 // When merging cells, the cell may be the 'original' or the 'covered'.
 // First, figure out which cells are merge starting points. Then create
 // the necessary cells underlying the merge area.
@@ -273,15 +618,53 @@ func (s *Sheet) handleMerged() {
 		for rownum := 0; rownum <= cell.VMerge; rownum++ {
 			for colnum := 0; colnum <= cell.HMerge; colnum++ {
 				// make cell
-				s.Cell(mainrow+rownum, maincol+colnum)
-
+				tmpcell := s.Cell(mainrow+rownum, maincol+colnum)
+
+				// Excel draws the border of a merged range from
+				// the cells along its edges, so the covered cells
+				// need a copy of the master style, keeping only
+				// the border edges they lie on.
+				if cell.style == nil || tmpcell == cell || tmpcell.style != nil {
+					continue
+				}
+				style := *cell.style
+				if colnum > 0 {
+					style.Border.Left, style.Border.LeftColor = "none", ""
+				}
+				if colnum < cell.HMerge {
+					style.Border.Right, style.Border.RightColor = "none", ""
+				}
+				if rownum > 0 {
+					style.Border.Top, style.Border.TopColor = "none", ""
+				}
+				if rownum < cell.VMerge {
+					style.Border.Bottom, style.Border.BottomColor = "none", ""
+				}
+				// The diagonals are drawn across the whole range
+				// from the master cell alone.
+				style.Border.Diagonal, style.Border.DiagonalColor = "", ""
+				style.Border.DiagonalUp, style.Border.DiagonalDown = false, false
+				tmpcell.style = &style
 			}
 		}
 	}
 }
 
 func (s *Sheet) makeSheetView(worksheet *xlsxWorksheet) {
+	if s.omitDefaultSheetView && len(s.SheetViews) == 0 && (!s.Selected || s.isFirstSheet()) {
+		worksheet.SheetViews.SheetView = nil
+		return
+	}
+	// Further views start out like the first one does.
+	defaultView := worksheet.SheetViews.SheetView[0]
+	defaultView.Selection = nil
 	for index, sheetView := range s.SheetViews {
+		if index >= len(worksheet.SheetViews.SheetView) {
+			worksheet.SheetViews.SheetView = append(worksheet.SheetViews.SheetView, defaultView)
+		}
+		if sheetView.TopLeftCell != "" {
+			worksheet.SheetViews.SheetView[index].TopLeftCell = sheetView.TopLeftCell
+		}
 		if sheetView.Pane != nil {
 			worksheet.SheetViews.SheetView[index].Pane = &xlsxPane{
 				XSplit:      sheetView.Pane.XSplit,
@@ -292,6 +675,18 @@ func (s *Sheet) makeSheetView(worksheet *xlsxWorksheet) {
 			}
 
 		}
+		if sheetView.Selection != nil {
+			selection := xlsxSelection{
+				Pane:         "topLeft",
+				ActiveCell:   sheetView.Selection.ActiveCell,
+				ActiveCellId: sheetView.Selection.activeCellId(),
+				SQRef:        strings.Join(sheetView.Selection.SQRef, " "),
+			}
+			if sheetView.Pane != nil && sheetView.Pane.ActivePane != "" {
+				selection.Pane = sheetView.Pane.ActivePane
+			}
+			worksheet.SheetViews.SheetView[index].Selection = []xlsxSelection{selection}
+		}
 	}
 	if s.Selected {
 		worksheet.SheetViews.SheetView[0].TabSelected = true
@@ -299,6 +694,38 @@ func (s *Sheet) makeSheetView(worksheet *xlsxWorksheet) {
 
 }
 
+// isFirstSheet reports whether the sheet is the first of its File,
+// which is the one shown when the file is opened.
+func (s *Sheet) isFirstSheet() bool {
+	return s.File != nil && len(s.File.Sheets) > 0 && s.File.Sheets[0] == s
+}
+
+// activeCellId returns the index of the range in SQRef that holds the
+// active cell.
+func (sel *Selection) activeCellId() int {
+	x, y, err := GetCoordsFromCellIDString(sel.ActiveCell)
+	if err != nil {
+		return 0
+	}
+	for i, ref := range sel.SQRef {
+		cells := strings.SplitN(ref, cellRangeChar, 2)
+		x1, y1, err := GetCoordsFromCellIDString(cells[0])
+		if err != nil {
+			continue
+		}
+		x2, y2 := x1, y1
+		if len(cells) == 2 {
+			if x2, y2, err = GetCoordsFromCellIDString(cells[1]); err != nil {
+				continue
+			}
+		}
+		if x >= x1 && x <= x2 && y >= y1 && y <= y2 {
+			return i
+		}
+	}
+	return 0
+}
+
 func (s *Sheet) makeSheetFormatPr(worksheet *xlsxWorksheet) {
 	if s.SheetFormat.DefaultRowHeight != 0 {
 		worksheet.SheetFormatPr.DefaultRowHeight = s.SheetFormat.DefaultRowHeight
@@ -366,11 +793,13 @@ func (s *Sheet) makeRows(worksheet *xlsxWorksheet, styles *xlsxStyleSheet, refTa
 		}
 		xRow := xlsxRow{}
 		xRow.R = r + 1
+		xRow.Hidden = xlsxBoolAttr(row.Hidden)
 		if row.isCustom {
 			xRow.CustomHeight = true
 			xRow.Ht = fmt.Sprintf("%g", row.Height)
 		}
 		xRow.OutlineLevel = row.OutlineLevel
+		xRow.Collapsed = xlsxBoolAttr(row.Collapsed)
 		if row.OutlineLevel > maxLevelRow {
 			maxLevelRow = row.OutlineLevel
 		}
@@ -400,9 +829,15 @@ func (s *Sheet) makeRows(worksheet *xlsxWorksheet, styles *xlsxStyleSheet, refTa
 			if c > maxCell {
 				maxCell = c
 			}
+			// There is no cell to write in a position that
+			// has nothing at all.
+			if cell.IsEmpty() && XfId == 0 && cell.Hyperlink == (Hyperlink{}) && cell.DataValidation == nil && cell.extLst == "" {
+				continue
+			}
 			xC := xlsxC{
-				S: XfId,
-				R: GetCellIDStringFromCoords(c, r),
+				S:      XfId,
+				R:      GetCellIDStringFromCoords(c, r),
+				ExtLst: makeExtLst(cell.extLst),
 			}
 			if cell.formula != "" {
 				xC.F = &xlsxF{Content: cell.formula}
@@ -452,17 +887,26 @@ func (s *Sheet) makeRows(worksheet *xlsxWorksheet, styles *xlsxStyleSheet, refTa
 				}
 
 				var relId string
-				for _, rel := range relations.Relationships {
-					if rel.Target == cell.Hyperlink.Link {
-						relId = rel.Id
+				if relations != nil {
+					for _, rel := range relations.Relationships {
+						if rel.Target == cell.Hyperlink.Link {
+							relId = rel.Id
+						}
 					}
 				}
 
-				if relId != "" {
+				if cell.Hyperlink.Link == "" && cell.Hyperlink.Location != "" {
+					worksheet.Hyperlinks.HyperLinks = append(worksheet.Hyperlinks.HyperLinks, xlsxHyperlink{
+						Reference:     xC.R,
+						Location:      cell.Hyperlink.Location,
+						DisplayString: cell.Hyperlink.DisplayString,
+						Tooltip:       cell.Hyperlink.Tooltip})
+				} else if relId != "" {
 
 					xlsxLink := xlsxHyperlink{
 						RelationshipId: relId,
 						Reference:      xC.R,
+						Location:       cell.Hyperlink.Location,
 						DisplayString:  cell.Hyperlink.DisplayString,
 						Tooltip:        cell.Hyperlink.Tooltip}
 					worksheet.Hyperlinks.HyperLinks = append(worksheet.Hyperlinks.HyperLinks, xlsxLink)
@@ -486,6 +930,11 @@ func (s *Sheet) makeRows(worksheet *xlsxWorksheet, styles *xlsxStyleSheet, refTa
 		}
 		xSheet.Row = append(xSheet.Row, xRow)
 	}
+	// Rows streamed to the sheet aren't in Rows yet, so their
+	// outline level is declared ahead of them.
+	if s.streamOutlineLevelRow > maxLevelRow {
+		maxLevelRow = s.streamOutlineLevelRow
+	}
 	// Update sheet format with the freshly determined max levels
 	s.SheetFormat.OutlineLevelCol = maxLevelCol
 	s.SheetFormat.OutlineLevelRow = maxLevelRow
@@ -530,10 +979,19 @@ func (s *Sheet) makeXLSXSheet(refTable *RefTable, styles *xlsxStyleSheet, relati
 	// phantom cells underlying the area covered by the merged cell
 	s.handleMerged()
 
+	worksheet.SheetPr.CodeName = s.codeName
 	s.makeSheetView(worksheet)
 	s.makeSheetFormatPr(worksheet)
+	worksheet.PrintOptions.HorizontalCentered = s.printOptions.HorizontalCentered
+	worksheet.PrintOptions.VerticalCentered = s.printOptions.VerticalCentered
+	worksheet.PrintOptions.GridLines = s.printOptions.GridLines
+	worksheet.PrintOptions.Headings = s.printOptions.Headings
+	worksheet.RowBreaks = makeBreaks(s.rowBreaks, maxColIndex)
+	worksheet.ColBreaks = makeBreaks(s.colBreaks, maxRowIndex)
+	worksheet.ExtLst = makeExtLst(s.extLst)
 	maxLevelCol := s.makeCols(worksheet, styles)
 	s.makeDataValidations(worksheet)
+	s.makeConditionalFormatting(worksheet)
 	s.makeRows(worksheet, styles, refTable, relations, maxLevelCol)
 
 	return worksheet
@@ -561,10 +1019,19 @@ func handleStyleForXLSX(style *Style, NumFmtId int, styles *xlsxStyleSheet) (XfI
 
 	xCellXf.Alignment.Horizontal = style.Alignment.Horizontal
 	xCellXf.Alignment.Indent = style.Alignment.Indent
+	if xCellXf.Alignment.Indent < 0 {
+		xCellXf.Alignment.Indent = 0
+	} else if xCellXf.Alignment.Indent > maxIndent {
+		xCellXf.Alignment.Indent = maxIndent
+	}
 	xCellXf.Alignment.ShrinkToFit = style.Alignment.ShrinkToFit
 	xCellXf.Alignment.TextRotation = style.Alignment.TextRotation
 	xCellXf.Alignment.Vertical = style.Alignment.Vertical
 	xCellXf.Alignment.WrapText = style.Alignment.WrapText
+	// Excel ignores the alignment of a format that does not apply it.
+	if !xCellXf.Alignment.isDefault() {
+		xCellXf.ApplyAlignment = true
+	}
 
 	XfId = styles.addCellXf(xCellXf)
 	return
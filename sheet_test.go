@@ -3,6 +3,7 @@ package xlsx
 import (
 	"bytes"
 	"encoding/xml"
+	"strings"
 	"testing"
 
 	qt "github.com/frankban/quicktest"
@@ -349,6 +350,38 @@ func (s *SheetSuite) TestSetRowHeightCM(c *C) {
 	c.Assert(row.Height, Equals, 42.51968505)
 }
 
+func TestSetRowHeight(t *testing.T) {
+	c := qt.New(t)
+	file := NewFile()
+	sheet, err := file.AddSheet("Sheet1")
+	c.Assert(err, qt.IsNil)
+	sheet.Cell(0, 0).SetString("tall")
+	sheet.SetRowHeight(0, 30)
+	sheet.Cell(1, 0).SetString("hidden")
+	sheet.SetRowHeight(1, 24.5)
+	sheet.Row(1).Hidden = true
+	sheet.Cell(2, 0).SetString("plain")
+
+	parts, err := file.MarshallParts()
+	c.Assert(err, qt.IsNil)
+	sheetXML := parts["xl/worksheets/sheet1.xml"]
+	c.Assert(sheetXML, qt.Contains, `<row r="1" ht="30" customHeight="1">`)
+	c.Assert(sheetXML, qt.Contains, `<row r="2" hidden="1" ht="24.5" customHeight="1">`)
+	c.Assert(sheetXML, qt.Contains, `<row r="3">`)
+
+	var buf bytes.Buffer
+	c.Assert(file.Write(&buf), qt.IsNil)
+	file, err = OpenBinary(buf.Bytes())
+	c.Assert(err, qt.IsNil)
+	sheet = file.Sheets[0]
+	c.Assert(sheet.Row(0).Height, qt.Equals, 30.0)
+	c.Assert(sheet.Row(0).Hidden, qt.Equals, false)
+	c.Assert(sheet.Row(1).Height, qt.Equals, 24.5)
+	c.Assert(sheet.Row(1).Hidden, qt.Equals, true)
+	c.Assert(sheet.Row(1).isCustom, qt.Equals, true)
+	c.Assert(sheet.Row(2).isCustom, qt.Equals, false)
+}
+
 func (s *SheetSuite) TestAlignment(c *C) {
 	leftalign := *DefaultAlignment()
 	leftalign.Horizontal = "left"
@@ -451,6 +484,48 @@ func TestBorder(t *testing.T) {
 	c.Assert(worksheet.SheetData.Row[0].C[0].S, qt.Equals, 0)
 }
 
+func TestMergedCellBorder(t *testing.T) {
+	c := qt.New(t)
+	file := NewFile()
+	sheet, _ := file.AddSheet("Sheet1")
+
+	master := sheet.Cell(0, 0)
+	master.Value = "Merged"
+	style := NewStyle()
+	style.Border = *NewBorder("thin", "thin", "thin", "thin")
+	style.Border.BottomColor = "FFFF0000"
+	style.Fill = *NewFill("solid", "FF00FF00", "FF00FF00")
+	style.ApplyBorder = true
+	style.ApplyFill = true
+	master.SetStyle(style)
+	master.Merge(2, 1)
+
+	// A covered cell with its own style is left alone.
+	own := NewStyle()
+	sheet.Cell(1, 1).SetStyle(own)
+
+	refTable := NewSharedStringRefTable()
+	styles := newXlsxStyleSheet(nil)
+	sheet.makeXLSXSheet(refTable, styles, nil)
+
+	c.Assert(master.GetStyle(), qt.Equals, style)
+	c.Assert(sheet.Cell(1, 1).GetStyle(), qt.Equals, own)
+
+	border := func(row, col int) Border {
+		return sheet.Cell(row, col).GetStyle().Border
+	}
+	c.Assert(border(0, 1), qt.Equals, Border{Left: "none", Right: "none", Top: "thin", Bottom: "none"})
+	c.Assert(border(0, 2), qt.Equals, Border{Left: "none", Right: "thin", Top: "thin", Bottom: "none"})
+	c.Assert(border(1, 0), qt.Equals, Border{Left: "thin", Right: "none", Top: "none", Bottom: "thin", BottomColor: "FFFF0000"})
+	c.Assert(border(1, 2), qt.Equals, Border{Left: "none", Right: "thin", Top: "none", Bottom: "thin", BottomColor: "FFFF0000"})
+
+	// The rest of the style is carried over, but not shared.
+	covered := sheet.Cell(1, 2).GetStyle()
+	c.Assert(covered, qt.Not(qt.Equals), style)
+	c.Assert(covered.Fill, qt.Equals, style.Fill)
+	c.Assert(covered.ApplyBorder, qt.Equals, true)
+}
+
 func TestOutlineLevels(t *testing.T) {
 	c := qt.New(t)
 	file := NewFile()
@@ -521,3 +596,278 @@ func (s *SheetSuite) TestAutoFilter(c *C) {
 	c.Assert(worksheet.AutoFilter, NotNil)
 	c.Assert(worksheet.AutoFilter.Ref, Equals, "B2:C3")
 }
+
+func TestDeclaredDimension(t *testing.T) {
+	c := qt.New(t)
+
+	file := NewFile()
+	sheet, err := file.AddSheet("Sheet1")
+	c.Assert(err, qt.IsNil)
+	_, ok := sheet.DeclaredDimension()
+	c.Assert(ok, qt.Equals, false)
+	sheet.Cell(1, 1).SetString("b2")
+	var buf bytes.Buffer
+	c.Assert(file.Write(&buf), qt.IsNil)
+
+	// The declared dimension is returned as it was written, even
+	// where it covers more than the cells of the sheet.
+	parts := unzipParts(c, buf.Bytes())
+	c.Assert(parts["xl/worksheets/sheet1.xml"], qt.Contains, `<dimension ref="A1:B2">`)
+	parts["xl/worksheets/sheet1.xml"] = strings.Replace(parts["xl/worksheets/sheet1.xml"], `<dimension ref="A1:B2">`, `<dimension ref="A1:Z100">`, 1)
+
+	file, err = OpenBinary(zipParts(c, parts))
+	c.Assert(err, qt.IsNil)
+	sheet = file.Sheets[0]
+	dimension, ok := sheet.DeclaredDimension()
+	c.Assert(ok, qt.Equals, true)
+	c.Assert(dimension, qt.Equals, "A1:Z100")
+	c.Assert(sheet.Cell(1, 1).Value, qt.Equals, "b2")
+}
+
+func TestFrozenPaneRoundTrip(t *testing.T) {
+	c := qt.New(t)
+
+	file, err := OpenFile("./testdocs/testFileToSlice.xlsx")
+	c.Assert(err, qt.IsNil)
+	sheet := file.Sheets[0]
+	c.Assert(sheet.Selected, qt.Equals, true)
+	frozen := &Pane{YSplit: 3, TopLeftCell: "A4", ActivePane: "bottomLeft", State: "frozen"}
+	c.Assert(sheet.SheetViews, qt.HasLen, 1)
+	c.Assert(sheet.SheetViews[0].Pane, qt.DeepEquals, frozen)
+	sheet.Cell(0, 0).SetString("tweaked")
+	// A second view, as used by a second window onto the workbook,
+	// is kept as well.
+	sheet.SheetViews = append(sheet.SheetViews, SheetView{TopLeftCell: "B10"})
+
+	var buf bytes.Buffer
+	c.Assert(file.Write(&buf), qt.IsNil)
+	file, err = OpenBinary(buf.Bytes())
+	c.Assert(err, qt.IsNil)
+	sheet = file.Sheets[0]
+	c.Assert(sheet.Cell(0, 0).Value, qt.Equals, "tweaked")
+	c.Assert(sheet.Selected, qt.Equals, true)
+	c.Assert(sheet.SheetViews, qt.HasLen, 2)
+	c.Assert(sheet.SheetViews[0].Pane, qt.DeepEquals, frozen)
+	c.Assert(sheet.SheetViews[1].Pane, qt.IsNil)
+	c.Assert(sheet.SheetViews[1].TopLeftCell, qt.Equals, "B10")
+}
+
+func TestPrintOptions(t *testing.T) {
+	c := qt.New(t)
+
+	file := NewFile()
+	sheet, err := file.AddSheet("Sheet1")
+	c.Assert(err, qt.IsNil)
+	c.Assert(sheet.PrintOptions(), qt.Equals, PrintOptions{})
+	sheet.Cell(0, 0).SetString("centered")
+	sheet.SetPrintOptions(PrintOptions{HorizontalCentered: true, GridLines: true})
+
+	var buf bytes.Buffer
+	c.Assert(file.Write(&buf), qt.IsNil)
+	sheetXML := unzipParts(c, buf.Bytes())["xl/worksheets/sheet1.xml"]
+	c.Assert(sheetXML, qt.Contains, `<printOptions headings="false" gridLines="true" gridLinesSet="true" horizontalCentered="true" verticalCentered="false">`)
+
+	file, err = OpenBinary(buf.Bytes())
+	c.Assert(err, qt.IsNil)
+	c.Assert(file.Sheets[0].PrintOptions(), qt.Equals, PrintOptions{HorizontalCentered: true, GridLines: true})
+}
+
+func TestRangeCells(t *testing.T) {
+	c := qt.New(t)
+
+	file := NewFile()
+	sheet, err := file.AddSheet("Sheet1")
+	c.Assert(err, qt.IsNil)
+	sheet.Cell(1, 1).SetString("b2")
+	sheet.Cell(2, 2).SetInt(42)
+	sheet.Cell(1, 2)
+
+	var visited []string
+	err = sheet.RangeCells("B2:C3", func(col, row int, cell *Cell) {
+		value := "<nil>"
+		if cell != nil {
+			value = cell.Value
+		}
+		visited = append(visited, GetCellIDStringFromCoords(col, row)+"="+value)
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(visited, qt.DeepEquals, []string{"B2=b2", "C2=<nil>", "B3=<nil>", "C3=42"})
+
+	// The sheet is not extended for positions beyond its cells.
+	c.Assert(sheet.MaxRow, qt.Equals, 3)
+	c.Assert(sheet.RangeCells("D10:E11", func(col, row int, cell *Cell) {
+		c.Assert(cell, qt.IsNil)
+	}), qt.IsNil)
+	c.Assert(sheet.MaxRow, qt.Equals, 3)
+	c.Assert(len(sheet.Rows), qt.Equals, 3)
+
+	err = sheet.RangeCells("B2:", func(col, row int, cell *Cell) {})
+	c.Assert(err, qt.ErrorMatches, `invalid range "B2:": .*`)
+}
+
+func TestPageBreaks(t *testing.T) {
+	c := qt.New(t)
+
+	file := NewFile()
+	sheet, err := file.AddSheet("Sheet1")
+	c.Assert(err, qt.IsNil)
+	sheet.Cell(30, 5).SetString("end")
+	sheet.AddRowBreak(19)
+	sheet.AddRowBreak(9)
+	sheet.AddRowBreak(19)
+	sheet.AddColBreak(2)
+	c.Assert(sheet.RowBreaks(), qt.DeepEquals, []int{9, 19})
+
+	var buf bytes.Buffer
+	c.Assert(file.Write(&buf), qt.IsNil)
+	sheetXML := unzipParts(c, buf.Bytes())["xl/worksheets/sheet1.xml"]
+	c.Assert(sheetXML, qt.Contains, `</headerFooter><rowBreaks count="2" manualBreakCount="2"><brk id="10" max="16383" man="true"></brk><brk id="20" max="16383" man="true"></brk></rowBreaks><colBreaks count="1" manualBreakCount="1"><brk id="3" max="1048575" man="true"></brk></colBreaks>`)
+
+	file, err = OpenBinary(buf.Bytes())
+	c.Assert(err, qt.IsNil)
+	sheet = file.Sheets[0]
+	c.Assert(sheet.RowBreaks(), qt.DeepEquals, []int{9, 19})
+	c.Assert(sheet.ColBreaks(), qt.DeepEquals, []int{2})
+}
+
+func TestClearRangeStyle(t *testing.T) {
+	c := qt.New(t)
+
+	file := NewFile()
+	sheet, err := file.AddSheet("Sheet1")
+	c.Assert(err, qt.IsNil)
+	style := NewStyle()
+	style.Font.Bold = true
+	style.Fill = *NewFill("solid", "FFFF0000", "FF000000")
+	style.ApplyFont = true
+	style.ApplyFill = true
+	for row := 0; row < 2; row++ {
+		for col := 0; col < 2; col++ {
+			cell := sheet.Cell(row, col)
+			cell.SetFloatWithFormat(1.5, "0.00")
+			cell.SetStyle(style)
+		}
+	}
+	var buf bytes.Buffer
+	c.Assert(file.Write(&buf), qt.IsNil)
+
+	file, err = OpenBinary(buf.Bytes())
+	c.Assert(err, qt.IsNil)
+	sheet = file.Sheets[0]
+	c.Assert(sheet.Cell(0, 0).GetStyle().Font.Bold, qt.Equals, true)
+	c.Assert(sheet.ClearRangeStyle("A1:B1"), qt.IsNil)
+	c.Assert(sheet.ClearRangeStyle("Z100"), qt.IsNil)
+	c.Assert(sheet.MaxRow, qt.Equals, 2)
+	sheet.Cell(1, 1).ClearStyle()
+	buf.Reset()
+	c.Assert(file.Write(&buf), qt.IsNil)
+
+	// The cleared cells reference the default style, which is left out.
+	sheetXML := unzipParts(c, buf.Bytes())["xl/worksheets/sheet1.xml"]
+	c.Assert(sheetXML, qt.Contains, `<c r="A1"><v>1.5</v></c><c r="B1"><v>1.5</v></c>`)
+	c.Assert(sheetXML, qt.Matches, `(?s).*<c r="A2" s="[1-9]\d*"><v>1.5</v></c><c r="B2"><v>1.5</v></c>.*`)
+
+	file, err = OpenBinary(buf.Bytes())
+	c.Assert(err, qt.IsNil)
+	sheet = file.Sheets[0]
+	c.Assert(sheet.Cell(0, 0).GetStyle().Font.Bold, qt.Equals, false)
+	c.Assert(sheet.Cell(0, 0).GetNumberFormat(), qt.Equals, "general")
+	c.Assert(sheet.Cell(1, 0).GetStyle().Font.Bold, qt.Equals, true)
+}
+
+func TestSetRangeOutsideBorder(t *testing.T) {
+	c := qt.New(t)
+
+	file := NewFile()
+	sheet, err := file.AddSheet("Sheet1")
+	c.Assert(err, qt.IsNil)
+	style := NewStyle()
+	style.Font.Bold = true
+	style.ApplyFont = true
+	for row := 1; row < 4; row++ {
+		for col := 1; col < 4; col++ {
+			sheet.Cell(row, col).SetStyle(style)
+		}
+	}
+	sheet.Cell(0, 0).SetString("outside")
+	border := Border{
+		Left: "thin", LeftColor: "FF0000FF",
+		Right: "thick", RightColor: "FF00FF00",
+		Top: "dashed", TopColor: "FFFF0000",
+		Bottom: "double", BottomColor: "FF000000",
+	}
+	c.Assert(sheet.SetRangeOutsideBorder("D4:B2", border), qt.IsNil)
+	c.Assert(sheet.SetRangeOutsideBorder("B0", border), qt.ErrorMatches, `invalid range "B0".*`)
+	// The style the cells shared is left as it was.
+	c.Assert(style.Border, qt.Equals, *DefaultBorder())
+
+	var buf bytes.Buffer
+	c.Assert(file.Write(&buf), qt.IsNil)
+	file, err = OpenBinary(buf.Bytes())
+	c.Assert(err, qt.IsNil)
+	sheet = file.Sheets[0]
+	// Each cell along the sides has the border on the sides of the
+	// range it is on, and nowhere else.
+	edges := func(row, col int) [4]string {
+		b := sheet.Cell(row, col).GetStyle().Border
+		return [4]string{b.Left, b.Right, b.Top, b.Bottom}
+	}
+	c.Assert(edges(1, 1), qt.Equals, [4]string{"thin", "none", "dashed", "none"})
+	c.Assert(edges(1, 2), qt.Equals, [4]string{"none", "none", "dashed", "none"})
+	c.Assert(edges(1, 3), qt.Equals, [4]string{"none", "thick", "dashed", "none"})
+	c.Assert(edges(2, 1), qt.Equals, [4]string{"thin", "none", "none", "none"})
+	c.Assert(edges(2, 3), qt.Equals, [4]string{"none", "thick", "none", "none"})
+	c.Assert(edges(3, 1), qt.Equals, [4]string{"thin", "none", "none", "double"})
+	c.Assert(edges(3, 2), qt.Equals, [4]string{"none", "none", "none", "double"})
+	c.Assert(edges(3, 3), qt.Equals, [4]string{"none", "thick", "none", "double"})
+	c.Assert(sheet.Cell(3, 3).GetStyle().Border.BottomColor, qt.Equals, "FF000000")
+	c.Assert(sheet.Cell(1, 1).GetStyle().Border.LeftColor, qt.Equals, "FF0000FF")
+	c.Assert(edges(2, 2), qt.Equals, [4]string{"none", "none", "none", "none"})
+	for row := 1; row < 4; row++ {
+		for col := 1; col < 4; col++ {
+			c.Assert(sheet.Cell(row, col).GetStyle().Font.Bold, qt.Equals, true)
+		}
+	}
+}
+
+func TestToTextTable(t *testing.T) {
+	c := qt.New(t)
+	file := NewFile()
+	sheet, err := file.AddSheet("Sheet1")
+	c.Assert(err, qt.IsNil)
+	sheet.Cell(0, 0).SetString("Name")
+	sheet.Cell(0, 1).SetString("Amount")
+	sheet.Cell(0, 2).SetString("Notes")
+	sheet.Cell(1, 0).SetString("Ann")
+	sheet.Cell(1, 1).SetFloatWithFormat(1234.5, "#,##0.00")
+	sheet.Cell(1, 2).SetString("first\nline")
+	sheet.Cell(2, 0).SetString("Bob")
+	sheet.Cell(2, 1).SetInt(7)
+	sheet.Cell(2, 2).SetString("a note that is far too long to show in full")
+
+	c.Assert(sheet.ToTextTable(), qt.Equals, ""+
+		"Name | Amount   | Notes\n"+
+		"Ann  | 1,234.50 | first line\n"+
+		"Bob  | 7        | a note that is far too long t…\n")
+}
+
+func TestMergedCells(t *testing.T) {
+	c := qt.New(t)
+
+	file, err := OpenFile("./testdocs/merged_cells.xlsx")
+	c.Assert(err, qt.IsNil)
+	c.Assert(file.Sheets[0].MergedCells(), qt.DeepEquals, []MergeRange{
+		{Ref: "A2:A3", StartCol: 0, StartRow: 1, EndCol: 0, EndRow: 2},
+		{Ref: "A4:A5", StartCol: 0, StartRow: 3, EndCol: 0, EndRow: 4},
+		{Ref: "B7:C7", StartCol: 1, StartRow: 6, EndCol: 2, EndRow: 6},
+	})
+
+	file = NewFile()
+	sheet, err := file.AddSheet("Sheet1")
+	c.Assert(err, qt.IsNil)
+	c.Assert(sheet.MergedCells(), qt.IsNil)
+	sheet.Cell(1, 1).Merge(2, 1)
+	c.Assert(sheet.MergedCells(), qt.DeepEquals, []MergeRange{
+		{Ref: "B2:D3", StartCol: 1, StartRow: 1, EndCol: 3, EndRow: 2},
+	})
+}
@@ -0,0 +1,107 @@
+package xlsx
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+)
+
+// testSQLDriver is a database/sql driver serving the result sets in
+// testSQLResults, keyed by query, for testing StreamFile.WriteSQLRows
+// without a database.
+type testSQLDriver struct{}
+
+type testSQLResult struct {
+	columns []string
+	// types are the names of the types of the columns in the
+	// database, if they are given.
+	types []string
+	rows  [][]driver.Value
+}
+
+var (
+	testSQLResults  = map[string]testSQLResult{}
+	registerSQLOnce sync.Once
+)
+
+// openTestSQL returns a database serving the result sets in
+// testSQLResults.
+func openTestSQL() (*sql.DB, error) {
+	registerSQLOnce.Do(func() {
+		sql.Register("xlsxtest", testSQLDriver{})
+	})
+	return sql.Open("xlsxtest", "")
+}
+
+func (testSQLDriver) Open(name string) (driver.Conn, error) {
+	return testSQLConn{}, nil
+}
+
+type testSQLConn struct{}
+
+func (testSQLConn) Prepare(query string) (driver.Stmt, error) {
+	result, ok := testSQLResults[query]
+	if !ok {
+		return nil, errors.New("unknown query " + query)
+	}
+	return testSQLStmt{result}, nil
+}
+
+func (testSQLConn) Close() error {
+	return nil
+}
+
+func (testSQLConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+type testSQLStmt struct {
+	result testSQLResult
+}
+
+func (testSQLStmt) Close() error {
+	return nil
+}
+
+func (testSQLStmt) NumInput() int {
+	return 0
+}
+
+func (testSQLStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("exec is not supported")
+}
+
+func (s testSQLStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &testSQLRows{result: s.result}, nil
+}
+
+type testSQLRows struct {
+	result testSQLResult
+	next   int
+}
+
+func (r *testSQLRows) Columns() []string {
+	return r.result.columns
+}
+
+func (r *testSQLRows) ColumnTypeDatabaseTypeName(index int) string {
+	if index < len(r.result.types) {
+		return r.result.types[index]
+	}
+	return ""
+}
+
+func (r *testSQLRows) Close() error {
+	return nil
+}
+
+func (r *testSQLRows) Next(dest []driver.Value) error {
+	if r.next == len(r.result.rows) {
+		return io.EOF
+	}
+	copy(dest, r.result.rows[r.next])
+	r.next++
+	return nil
+}
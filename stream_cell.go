@@ -1,7 +1,10 @@
 package xlsx
 
 import (
+	"fmt"
+	"math/big"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -10,6 +13,22 @@ type StreamCell struct {
 	cellData  string
 	cellStyle StreamStyle
 	cellType  CellType
+	// skip is set on cells that leave their position in the row empty.
+	skip bool
+	// autoStyle is set on cells whose style is added to the file when
+	// they are written, rather than through AddStreamStyle.
+	autoStyle bool
+	// formula is the formula of the cell, in which case cellData is
+	// its cached result.
+	formula string
+	// numFmt is the number format code of a cell with autoStyle set,
+	// which is added to the file along with its style.
+	numFmt string
+	// hyperlink is the URL the cell links to.
+	hyperlink string
+	// location is the place within the workbook, such as
+	// "'Sheet 2'!A1", that the cell links to.
+	location string
 }
 
 // NewStreamCell creates a new cell containing the given data with the given style and type.
@@ -21,6 +40,12 @@ func NewStreamCell(cellData string, cellStyle StreamStyle, cellType CellType) St
 	}
 }
 
+// SkipStreamCell returns a cell that writes nothing at all, leaving its position in the row empty. It keeps the cells
+// after it in their columns without the cost of writing an empty cell.
+func SkipStreamCell() StreamCell {
+	return StreamCell{skip: true}
+}
+
 // NewStringStreamCell creates a new cell that holds string data, is of type string and uses general formatting.
 func NewStringStreamCell(cellData string) StreamCell {
 	return NewStreamCell(cellData, StreamStyleDefaultString, CellTypeString)
@@ -31,6 +56,202 @@ func NewStyledStringStreamCell(cellData string, cellStyle StreamStyle) StreamCel
 	return NewStreamCell(cellData, cellStyle, CellTypeString)
 }
 
+// NewColoredStringStreamCell creates a new cell that holds a string shown in the given ARGB font color, for example
+// "FFFF0000" for red, and is otherwise styled like NewStringStreamCell. The style does not have to be added with
+// AddStreamStyle; it is added to the file when the cell is written, and all cells of the same color share it.
+func NewColoredStringStreamCell(cellData string, argb string) StreamCell {
+	return StreamCell{
+		cellData:  cellData,
+		cellStyle: coloredStringStyle(argb),
+		cellType:  CellTypeString,
+		autoStyle: true,
+	}
+}
+
+// NewMoneyStreamCell creates a new numeric cell that holds amount exactly, without going through a float64, shown
+// in the given number format, such as "$#,##0.00". The amount is rounded, halves away from zero, to the number of
+// decimal places of the format. As with NewColoredStringStreamCell the style does not have to be added with
+// AddStreamStyle, and all cells of the same format share it.
+func NewMoneyStreamCell(amount *big.Rat, format string) StreamCell {
+	return StreamCell{
+		cellData:  amount.FloatString(formatDecimalPlaces(format)),
+		cellStyle: numFmtStyle(format),
+		cellType:  CellTypeNumeric,
+		autoStyle: true,
+		numFmt:    format,
+	}
+}
+
+// formatDecimalPlaces returns the number of decimal places shown by the
+// positive section of the number format code format.
+func formatDecimalPlaces(format string) int {
+	quoted := false
+	for i, r := range format {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == ';':
+			return 0
+		case r == '.':
+			places := 0
+			for _, r := range format[i+1:] {
+				if r != '0' && r != '#' && r != '?' {
+					break
+				}
+				places++
+			}
+			return places
+		}
+	}
+	return 0
+}
+
+// maxFormatDigits is the most digits that the number formats of NewFractionStreamCell, NewFixedDecimalStreamCell and
+// NewPercentStreamCell are given, which is as many decimal places as Excel shows.
+const maxFormatDigits = 30
+
+// NewFractionStreamCell creates a new numeric cell that holds value and shows it as a fraction, such as 3/8 or 1 1/2,
+// whose denominator has at most denominatorDigits digits. One and two digits use the built in fraction formats.
+// Fewer than one digit are taken as one, and more than 30 as 30. As with NewMoneyStreamCell the style does not have
+// to be added with AddStreamStyle.
+func NewFractionStreamCell(value float64, denominatorDigits int) StreamCell {
+	if denominatorDigits < 1 {
+		denominatorDigits = 1
+	} else if denominatorDigits > maxFormatDigits {
+		denominatorDigits = maxFormatDigits
+	}
+	placeholders := strings.Repeat("?", denominatorDigits)
+	format := "# " + placeholders + "/" + placeholders
+	return StreamCell{
+		cellData:  strconv.FormatFloat(value, 'f', -1, 64),
+		cellStyle: numFmtStyle(format),
+		cellType:  CellTypeNumeric,
+		autoStyle: true,
+		numFmt:    format,
+	}
+}
+
+// NewFixedDecimalStreamCell creates a new numeric cell that holds value and shows it with exactly decimals decimal
+// places, in a number format such as "0.000". Fewer than zero decimals are taken as none, and more than 30 as 30. As
+// with NewMoneyStreamCell the style does not have to be added with AddStreamStyle, and all cells with the same number
+// of decimals share it.
+func NewFixedDecimalStreamCell(value float64, decimals int) StreamCell {
+	if decimals > maxFormatDigits {
+		decimals = maxFormatDigits
+	}
+	format := "0"
+	if decimals > 0 {
+		format += "." + strings.Repeat("0", decimals)
+	}
+	return StreamCell{
+		cellData:  strconv.FormatFloat(value, 'f', -1, 64),
+		cellStyle: numFmtStyle(format),
+		cellType:  CellTypeNumeric,
+		autoStyle: true,
+		numFmt:    format,
+	}
+}
+
+// NewPercentStreamCell creates a new numeric cell that holds fraction and shows it as a percentage with decimals
+// decimal places, in a number format such as "0.0%", so that 0.1234 with one decimal shows as 12.3%. Fewer than zero
+// decimals are taken as none, and more than 30 as 30. As with NewFixedDecimalStreamCell the style does not have to be
+// added with AddStreamStyle, and all cells with the same number of decimals share it.
+func NewPercentStreamCell(fraction float64, decimals int) StreamCell {
+	if decimals > maxFormatDigits {
+		decimals = maxFormatDigits
+	}
+	format := "0"
+	if decimals > 0 {
+		format += "." + strings.Repeat("0", decimals)
+	}
+	format += "%"
+	return StreamCell{
+		cellData:  strconv.FormatFloat(fraction, 'f', -1, 64),
+		cellStyle: numFmtStyle(format),
+		cellType:  CellTypeNumeric,
+		autoStyle: true,
+		numFmt:    format,
+	}
+}
+
+// NewLocalizedNumberStreamCell creates a new string cell that holds value as text, rounded to decimals decimal places
+// and written with the given decimal separator and, unless it is 0, with the thousands of its whole part separated by
+// groupSep, so that 1234.56 with a comma for the decimals and a period for the thousands reads "1.234,56". It is for
+// files read by people in locales whose separators differ from those of the number formats of the file. The cell holds
+// text, so it does not take part in calculations. Fewer than zero decimals are taken as none.
+func NewLocalizedNumberStreamCell(value float64, decimals int, decimalSep, groupSep rune) StreamCell {
+	return NewStringStreamCell(formatLocalizedNumber(value, decimals, decimalSep, groupSep))
+}
+
+// formatLocalizedNumber formats value as NewLocalizedNumberStreamCell
+// does.
+func formatLocalizedNumber(value float64, decimals int, decimalSep, groupSep rune) string {
+	if decimals < 0 {
+		decimals = 0
+	}
+	text := strconv.FormatFloat(value, 'f', decimals, 64)
+	sign := ""
+	if strings.HasPrefix(text, "-") {
+		sign, text = "-", text[1:]
+		if strings.Trim(text, "0.") == "" {
+			sign = ""
+		}
+	}
+	whole, fraction := text, ""
+	if i := strings.IndexByte(text, '.'); i >= 0 {
+		whole, fraction = text[:i], text[i+1:]
+	}
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, digit := range whole {
+		if i > 0 && groupSep != 0 && (len(whole)-i)%3 == 0 {
+			b.WriteRune(groupSep)
+		}
+		b.WriteRune(digit)
+	}
+	if fraction != "" {
+		b.WriteRune(decimalSep)
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
+
+// NewHyperlinkStreamCell creates a new cell that shows display and links to url, styled according to the given style,
+// which has to have been added with AddStreamStyle.
+func NewHyperlinkStreamCell(display, url string, style StreamStyle) StreamCell {
+	return NewStyledHyperlinkStreamCell(display, url, style)
+}
+
+// NewStyledHyperlinkStreamCell creates a new cell that shows display and links to url, styled according to the given
+// style, which has to have been added with AddStreamStyle. The link is stored as a relationship of the sheet, as links
+// made in spreadsheet applications are.
+func NewStyledHyperlinkStreamCell(display, url string, style StreamStyle) StreamCell {
+	return StreamCell{
+		cellData:  display,
+		cellStyle: style,
+		cellType:  CellTypeString,
+		hyperlink: url,
+	}
+}
+
+// NewHyperlinkFormulaStreamCell creates a new cell that links to url using the HYPERLINK formula, showing display as
+// its text. Unlike hyperlinks stored as relationships of the sheet this costs nothing beyond the cell itself, which
+// suits sheets with many links. The cell takes on the style of its column.
+func NewHyperlinkFormulaStreamCell(display, url string) StreamCell {
+	return StreamCell{
+		cellData: display,
+		cellType: CellTypeStringFormula,
+		formula:  `HYPERLINK(` + formulaString(url) + `,` + formulaString(display) + `)`,
+	}
+}
+
+// formulaString quotes s as a string literal for use in a formula.
+func formulaString(s string) string {
+	return `"` + strings.Replace(s, `"`, `""`, -1) + `"`
+}
+
 // NewIntegerStreamCell creates a new cell that holds an integer value (represented as string),
 // is formatted as a standard integer and is of type numeric.
 func NewIntegerStreamCell(cellData int) StreamCell {
@@ -43,9 +264,93 @@ func NewStyledIntegerStreamCell(cellData int, cellStyle StreamStyle) StreamCell
 	return NewStreamCell(strconv.Itoa(cellData), cellStyle, CellTypeNumeric)
 }
 
+// NewFloatStreamCell creates a new numeric cell that holds value, styled according to the given style. The value is
+// written with as many digits as it takes to read back the very same float64, so 0.1+0.2 is written as
+// 0.30000000000000004, and it is the number format of the style that decides how it is shown.
+func NewFloatStreamCell(value float64, style StreamStyle) StreamCell {
+	return NewFloatStreamCellWithPrecision(value, -1, style)
+}
+
+// NewFloatStreamCellWithPrecision creates a new numeric cell that holds value rounded to prec significant digits,
+// styled according to the given style. A negative prec keeps every digit, as NewFloatStreamCell does. NaN and the
+// infinities, which Excel has no numbers for, are written as the error #NUM!.
+func NewFloatStreamCellWithPrecision(value float64, prec int, style StreamStyle) StreamCell {
+	return NewStreamCell(strconv.FormatFloat(value, 'g', prec, 64), style, CellTypeNumeric)
+}
+
 // NewDateStreamCell creates a new cell that holds a date value and is formatted as dd-mm-yyyy
 // and is of type numeric.
 func NewDateStreamCell(t time.Time) StreamCell {
 	excelTime := TimeToExcelTime(t, false)
 	return NewStreamCell(strconv.Itoa(int(excelTime)), StreamStyleDefaultDate, CellTypeNumeric)
 }
+
+// NewZonedDateTimeStreamCell creates a new numeric cell that holds the date and time of t as they read on a clock in
+// t's location, shown in the built in "m/d/yy h:mm" format. Spreadsheets have no notion of time zones, so the zone
+// itself is lost; NewZoneStreamCell makes a cell to show it alongside. As with NewMoneyStreamCell the style does not
+// have to be added with AddStreamStyle.
+func NewZonedDateTimeStreamCell(t time.Time) StreamCell {
+	format := builtInNumFmt[DateTimeFormat_d_m_yy_h_mm]
+	return StreamCell{
+		cellData:  strconv.FormatFloat(TimeToExcelTime(wallClockUTC(t), false), 'f', -1, 64),
+		cellStyle: numFmtStyle(format),
+		cellType:  CellTypeNumeric,
+		autoStyle: true,
+		numFmt:    format,
+	}
+}
+
+// NewZoneStreamCell creates a new string cell that shows the time zone of t, as its abbreviation followed by its
+// offset from UTC, such as "CET +01:00". As with NewStringStreamCell the style StreamStyleDefaultString has to have
+// been added with AddStreamStyle.
+func NewZoneStreamCell(t time.Time) StreamCell {
+	return NewStringStreamCell(t.Format("MST -07:00"))
+}
+
+// wallClockUTC returns the time in UTC that reads the same on a clock as t does in its own location.
+func wallClockUTC(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// reportTextCell returns a cell of a report made with StreamFileBuilder.WriteReport that holds text in the given
+// style, which is added to the file when the cell is written.
+func reportTextCell(text string, style StreamStyle) StreamCell {
+	cell := NewStyledStringStreamCell(text, style)
+	cell.autoStyle = true
+	return cell
+}
+
+// valueStreamCell returns a cell that holds value, with its type and style inferred from the type of value, for the
+// rows written by StreamFileBuilder.WriteReport and StreamFile.WriteWithSchema.
+func valueStreamCell(value interface{}) StreamCell {
+	var cell StreamCell
+	switch v := value.(type) {
+	case nil:
+		return SkipStreamCell()
+	case string:
+		cell = NewStringStreamCell(v)
+	case int:
+		cell = NewIntegerStreamCell(v)
+	case int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		cell = NewStreamCell(fmt.Sprint(v), StreamStyleDefaultInteger, CellTypeNumeric)
+	case float32:
+		cell = NewStreamCell(strconv.FormatFloat(float64(v), 'f', -1, 32), generalNumberStyle, CellTypeNumeric)
+	case float64:
+		cell = NewStreamCell(strconv.FormatFloat(v, 'f', -1, 64), generalNumberStyle, CellTypeNumeric)
+	case bool:
+		cell = NewStreamCell("0", generalNumberStyle, CellTypeBool)
+		if v {
+			cell.cellData = "1"
+		}
+	case time.Time:
+		if v.Hour() == 0 && v.Minute() == 0 && v.Second() == 0 && v.Nanosecond() == 0 {
+			cell = NewDateStreamCell(v)
+		} else {
+			return NewZonedDateTimeStreamCell(v)
+		}
+	default:
+		cell = NewStringStreamCell(fmt.Sprint(v))
+	}
+	cell.autoStyle = true
+	return cell
+}
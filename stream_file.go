@@ -2,24 +2,74 @@ package xlsx
 
 import (
 	"archive/zip"
+	"database/sql"
 	"encoding/xml"
 	"errors"
+	"fmt"
 	"io"
+	"math"
+	"net/url"
+	"reflect"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type StreamFile struct {
-	xlsxFile               *File
-	sheetXmlPrefix         []string
-	sheetXmlSuffix         []string
-	zipWriter              *zip.Writer
-	currentSheet           *streamSheet
-	styleIds               [][]int
-	styleIdMap             map[StreamStyle]int
-	streamingCellMetadatas map[int]*StreamingCellMetadata
-	sheetStreamStyles      map[int]cellStreamStyle
-	sheetDefaultCellType   map[int]defaultCellType
-	err                    error
+	xlsxFile                  *File
+	sheetXmlPrefix            []string
+	sheetXmlSuffix            []string
+	zipWriter                 *zip.Writer
+	currentSheet              *streamSheet
+	styleIds                  [][]int
+	styleIdMap                map[StreamStyle]int
+	streamingCellMetadatas    map[int]*StreamingCellMetadata
+	sheetStreamStyles         map[int]cellStreamStyle
+	sheetDefaultCellType      map[int]defaultCellType
+	sheetAlternatingRowStyles map[int]alternatingRowStyles
+	sheetHeatmaps             map[int][][]float64
+	sheetReports              map[int][][]StreamCell
+	sheetMerges               map[int][]string
+	boolsAsText               bool
+	controlCharacterMode      ControlCharacterMode
+	// quotePrefix is set when a leading apostrophe of a string cell
+	// is written as the quote prefix of its style, and
+	// quotePrefixStyleIds maps the ids of the styles of such cells
+	// to the ids of their copies with the prefix.
+	quotePrefix         bool
+	quotePrefixStyleIds map[int]int
+	// absoluteLinkTargets is set when the targets of hyperlinks have
+	// to be absolute URIs.
+	absoluteLinkTargets bool
+	// alignByType is set when cells whose style has the general
+	// horizontal alignment are aligned by their type, and
+	// alignedStyleIds maps the ids of the styles of such cells to the
+	// ids of their aligned copies.
+	alignByType     bool
+	alignedStyleIds map[alignedStyle]int
+	// pivotCacheHeadings holds the names of the fields of each of the
+	// pivot cache sources of the file, once they have been written.
+	pivotCacheHeadings map[int][]string
+	// defaultNumberFormat is set when numeric cells without a style
+	// take on the style StreamStyleDefaultDecimal.
+	defaultNumberFormat bool
+	// stylesXML is the style sheet as it was when the file was
+	// built.  It is written when the file is closed, so that styles
+	// added by the cells in between can still make it in.
+	stylesXML   string
+	stylesAdded bool
+	// sheetRels holds the relations of the sheets as they were when
+	// the file was built, keyed by their paths.  They are written
+	// along with the hyperlinks of the sheet when it is finished.
+	sheetRels map[string]string
+	// progress is called after each call that writes rows, see
+	// SetProgressCallback, and inProgress is set while it runs.
+	progress      func(rowsWritten, sheetsWritten int)
+	inProgress    bool
+	rowsWritten   int
+	sheetsWritten int
+	err           error
 }
 
 type streamSheet struct {
@@ -33,6 +83,37 @@ type streamSheet struct {
 	writer     io.Writer
 	styleIds   []int
 	mergeCells []string
+	// Rows written with WriteRowAt, keyed by their zero based
+	// index, waiting for the end of the sheet to be written out in
+	// order.
+	bufferedRows map[int][]StreamCell
+	// The number of shared formulas written to the sheet so far, used
+	// as the next shared formula index.
+	sharedFormulaCount int
+	// The hyperlinks of the cells written to the sheet so far.
+	hyperlinks []streamHyperlink
+	// The outline level of the last row written, if it was in a
+	// collapsed group, so that the row after the group is marked
+	// collapsed.
+	collapsedLevel int
+}
+
+// streamRowAttrs holds the attributes of a row written to a sheet.
+type streamRowAttrs struct {
+	// height is the height of the row in points, or zero for the
+	// default height of the sheet.
+	height       float64
+	outlineLevel int
+	// collapsed is set on rows within a collapsed group, which are
+	// hidden.
+	collapsed bool
+}
+
+// streamHyperlink is the link of a cell written to a sheet.
+type streamHyperlink struct {
+	ref      string
+	url      string
+	location string
 }
 
 var (
@@ -40,8 +121,29 @@ var (
 	WrongNumberOfRowsError   = errors.New("invalid number of cells passed to Write. All calls to Write on the same sheet must have the same number of cells")
 	AlreadyOnLastSheetError  = errors.New("NextSheet() called, but already on last sheet")
 	UnsupportedCellTypeError = errors.New("the given cell type is not supported")
+	BufferedSheetError       = errors.New("rows cannot be written in order to a sheet once WriteRowAt has been used on it")
 )
 
+// SetProgressCallback sets a function that is called after each call that writes rows to the file, such as WriteS and
+// WriteAllS, with the number of rows written so far and the number of sheets finished so far, by NextSheet. A nil
+// function, the default, turns the calls off. The function may write to the file itself; it is not called again for
+// the rows it writes.
+func (sf *StreamFile) SetProgressCallback(fn func(rowsWritten, sheetsWritten int)) {
+	sf.progress = fn
+}
+
+// reportProgress adds rows to the number of rows written and calls the
+// progress callback, if there is one and it isn't already running.
+func (sf *StreamFile) reportProgress(rows int) {
+	sf.rowsWritten += rows
+	if sf.progress == nil || sf.inProgress {
+		return
+	}
+	sf.inProgress = true
+	defer func() { sf.inProgress = false }()
+	sf.progress(sf.rowsWritten, sf.sheetsWritten)
+}
+
 // Write will write a row of cells to the current sheet. Every call to Write on the same sheet must contain the
 // same number of cells as the header provided when the sheet was created or an error will be returned. This function
 // will always trigger a flush on success. Currently the only supported data type is string data.
@@ -54,6 +156,7 @@ func (sf *StreamFile) Write(cells []string) error {
 		sf.err = err
 		return err
 	}
+	sf.reportProgress(1)
 	return sf.zipWriter.Flush()
 }
 
@@ -72,6 +175,7 @@ func (sf *StreamFile) WriteWithColumnDefaultMetadata(cells []string) error {
 		sf.err = err
 		return err
 	}
+	sf.reportProgress(1)
 	return sf.zipWriter.Flush()
 }
 
@@ -88,6 +192,56 @@ func (sf *StreamFile) WriteS(cells []StreamCell) error {
 		sf.err = err
 		return err
 	}
+	sf.reportProgress(1)
+	return sf.zipWriter.Flush()
+}
+
+// WriteSWithHeight writes a row of cells to the current sheet as WriteS does, and gives the row a height of height
+// points, such as a header row that has to fit wrapped text. A height of zero leaves the row at the default height of
+// the sheet, just as WriteS does.
+func (sf *StreamFile) WriteSWithHeight(cells []StreamCell, height float64) error {
+	if sf.err != nil {
+		return sf.err
+	}
+	if height < 0 {
+		return errors.New("the row height must not be negative")
+	}
+	err := sf.writeRow(cells, streamRowAttrs{height: height})
+	if err != nil {
+		sf.err = err
+		return err
+	}
+	sf.reportProgress(1)
+	return sf.zipWriter.Flush()
+}
+
+// WriteSWithOutline writes a row of cells to the current sheet as
+// WriteS does, grouped at the outline level, from 1 up to the level
+// set with StreamFileBuilder.SetMaxRowOutlineLevel, or not grouped if
+// it is 0.  Consecutive rows at the same level form a group, summed up
+// by the row below it as spreadsheet applications expect by default.
+// The rows of a collapsed group are written with collapsed set, which
+// hides them, and the row that follows the group is marked collapsed.
+func (sf *StreamFile) WriteSWithOutline(cells []StreamCell, level int, collapsed bool) error {
+	if sf.err != nil {
+		return sf.err
+	}
+	if sf.currentSheet == nil {
+		return NoCurrentSheetError
+	}
+	maxLevel := int(sf.xlsxFile.Sheets[sf.currentSheet.index-1].streamOutlineLevelRow)
+	if level < 0 || level > maxLevel {
+		return fmt.Errorf("invalid outline level %d, the sheet allows levels from 0 to %d", level, maxLevel)
+	}
+	if collapsed && level == 0 {
+		return errors.New("a row must be grouped to be collapsed")
+	}
+	err := sf.writeRow(cells, streamRowAttrs{outlineLevel: level, collapsed: collapsed})
+	if err != nil {
+		sf.err = err
+		return err
+	}
+	sf.reportProgress(1)
 	return sf.zipWriter.Flush()
 }
 
@@ -102,6 +256,7 @@ func (sf *StreamFile) WriteAll(records [][]string) error {
 			return err
 		}
 	}
+	sf.reportProgress(len(records))
 	return sf.zipWriter.Flush()
 }
 
@@ -119,9 +274,338 @@ func (sf *StreamFile) WriteAllS(records [][]StreamCell) error {
 			return err
 		}
 	}
+	sf.reportProgress(len(records))
+	return sf.zipWriter.Flush()
+}
+
+// WriteWithSchema writes a row of headers followed by rows of values to the current sheet, checking the values against
+// schema, which gives the type of each column: CellTypeString columns take strings, CellTypeNumeric columns integers
+// and floats, CellTypeBool columns bools and CellTypeDate columns time.Time values, written as dates. A nil value
+// leaves its cell empty whatever the type of its column. All the rows are checked before any is written, so a value
+// of the wrong type fails the call without writing anything, with an error naming its row and column.
+func (sf *StreamFile) WriteWithSchema(headers []string, rows [][]interface{}, schema []CellType) error {
+	if sf.err != nil {
+		return sf.err
+	}
+	if len(headers) != len(schema) {
+		return fmt.Errorf("there are %d headers but the schema has %d columns", len(headers), len(schema))
+	}
+	for col, cellType := range schema {
+		if schemaTypeName(cellType) == "" {
+			return fmt.Errorf("column %q: cell type %d is not supported in a schema", headers[col], cellType)
+		}
+	}
+	for i, values := range rows {
+		if len(values) != len(schema) {
+			return fmt.Errorf("row %d has %d values but the schema has %d columns", i, len(values), len(schema))
+		}
+		for col, value := range values {
+			if !matchesSchemaType(value, schema[col]) {
+				return fmt.Errorf("row %d, column %q: %v of type %T is not a %s", i, headers[col], value, value, schemaTypeName(schema[col]))
+			}
+		}
+	}
+	records := make([][]StreamCell, 0, len(rows)+1)
+	headerCells := make([]StreamCell, len(headers))
+	for i, header := range headers {
+		headerCells[i] = reportTextCell(header, StreamStyleDefaultString)
+	}
+	records = append(records, headerCells)
+	for _, values := range rows {
+		cells := make([]StreamCell, len(values))
+		for col, value := range values {
+			cells[col] = valueStreamCell(value)
+		}
+		records = append(records, cells)
+	}
+	return sf.WriteAllS(records)
+}
+
+// schemaTypeName returns the name of a cell type that can be used in
+// the schema of StreamFile.WriteWithSchema, or an empty string for the
+// types that cannot.
+func schemaTypeName(cellType CellType) string {
+	switch cellType {
+	case CellTypeString:
+		return "string"
+	case CellTypeNumeric:
+		return "number"
+	case CellTypeBool:
+		return "bool"
+	case CellTypeDate:
+		return "date"
+	}
+	return ""
+}
+
+// matchesSchemaType returns true if value can be written to a column
+// of cellType by StreamFile.WriteWithSchema.
+func matchesSchemaType(value interface{}, cellType CellType) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return cellType == CellTypeString
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return cellType == CellTypeNumeric
+	case float32:
+		return cellType == CellTypeNumeric && !math.IsInf(float64(v), 0) && !math.IsNaN(float64(v))
+	case float64:
+		return cellType == CellTypeNumeric && !math.IsInf(v, 0) && !math.IsNaN(v)
+	case bool:
+		return cellType == CellTypeBool
+	case time.Time:
+		return cellType == CellTypeDate
+	}
+	return false
+}
+
+// WriteSQLRows writes a row of headers, the names of the columns of
+// rows in headerStyle, to the current sheet, followed by a row for each
+// row of rows.  The type of each cell is that of the value the database
+// driver gives: numbers, bools and times are written as such, and text
+// and bytes as strings.  As drivers give decimals, and some drivers
+// every value, as bytes, the bytes of columns of a numeric type are
+// written as numbers.  NULL values leave their cells empty.  Rows are
+// written as they are scanned, and rows is not closed.
+func (sf *StreamFile) WriteSQLRows(rows *sql.Rows, headerStyle StreamStyle) error {
+	if sf.err != nil {
+		return sf.err
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+	numeric := make([]bool, len(columnTypes))
+	for i, columnType := range columnTypes {
+		numeric[i] = isNumericSQLColumn(columnType)
+	}
+	cells := make([]StreamCell, len(columns))
+	for i, column := range columns {
+		cells[i] = reportTextCell(column, headerStyle)
+	}
+	if err := sf.WriteS(cells); err != nil {
+		return err
+	}
+	values := make([]interface{}, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		for i, value := range values {
+			if b, ok := value.([]byte); ok {
+				value = string(b)
+				if numeric[i] {
+					if f, err := strconv.ParseFloat(string(b), 64); err == nil {
+						value = f
+					}
+				}
+			}
+			cells[i] = valueStreamCell(value)
+		}
+		if err := sf.WriteS(cells); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// isNumericSQLColumn reports whether the values of a column are
+// numbers, by the type the driver scans them into or else by the name
+// of their type in the database.
+func isNumericSQLColumn(columnType *sql.ColumnType) bool {
+	if scanType := columnType.ScanType(); scanType != nil {
+		switch scanType.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return true
+		}
+	}
+	name := strings.TrimSpace(strings.TrimPrefix(strings.ToUpper(columnType.DatabaseTypeName()), "UNSIGNED"))
+	switch name {
+	case "DECIMAL", "NUMERIC", "NUMBER", "DEC", "FIXED",
+		"INT", "INTEGER", "TINYINT", "SMALLINT", "MEDIUMINT", "BIGINT",
+		"INT2", "INT4", "INT8", "FLOAT", "FLOAT4", "FLOAT8", "DOUBLE", "REAL":
+		return true
+	}
+	return false
+}
+
+// WriteRowAt puts the current sheet into buffered mode and stores a row of cells to be written at the zero based
+// rowIndex. Rows may be given in any order, and are held in memory until the sheet is finished by NextSheet() or
+// Close(), at which point they are written out in ascending order; the rows in the gaps are left out, and so are
+// empty. rowIndex must be below Excel2006MaxRowCount. Once WriteRowAt has been used on a sheet, the other Write
+// functions can no longer be used on it. As with WriteS, every row must contain the same number of cells.
+func (sf *StreamFile) WriteRowAt(rowIndex int, cells []StreamCell) error {
+	if sf.err != nil {
+		return sf.err
+	}
+	if sf.currentSheet == nil {
+		return NoCurrentSheetError
+	}
+	if len(cells) != sf.currentSheet.columnCount {
+		if sf.currentSheet.columnCount != 0 {
+			return WrongNumberOfRowsError
+		}
+		sf.currentSheet.columnCount = len(cells)
+	}
+	if rowIndex < sf.currentSheet.rowCount {
+		return fmt.Errorf("row %d has already been written", rowIndex)
+	}
+	if rowIndex > Excel2006MaxRowIndex {
+		return fmt.Errorf("row %d is beyond the last row of a sheet, %d", rowIndex, Excel2006MaxRowIndex)
+	}
+	if _, ok := sf.currentSheet.bufferedRows[rowIndex]; ok {
+		return fmt.Errorf("row %d has already been written", rowIndex)
+	}
+	if sf.currentSheet.bufferedRows == nil {
+		sf.currentSheet.bufferedRows = make(map[int][]StreamCell)
+	}
+	sf.currentSheet.bufferedRows[rowIndex] = append([]StreamCell(nil), cells...)
+	return nil
+}
+
+// writeBufferedRows writes out the rows stored by WriteRowAt in ascending order, leaving out the rows in any gaps.
+func (sf *StreamFile) writeBufferedRows() error {
+	bufferedRows := sf.currentSheet.bufferedRows
+	if len(bufferedRows) == 0 {
+		return nil
+	}
+	sf.currentSheet.bufferedRows = nil
+	rowIndexes := make([]int, 0, len(bufferedRows))
+	for rowIndex := range bufferedRows {
+		rowIndexes = append(rowIndexes, rowIndex)
+	}
+	sort.Ints(rowIndexes)
+	for _, rowIndex := range rowIndexes {
+		// writeS numbers the row after the last one written.
+		sf.currentSheet.rowCount = rowIndex
+		if err := sf.writeS(bufferedRows[rowIndex]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteBlankRow writes a row to the current sheet that holds a blank cell in the given style for each of the sheet's
+// columns, such as a spacer between sections. The style must have been added to the StreamFileBuilder.
+func (sf *StreamFile) WriteBlankRow(style StreamStyle) error {
+	if sf.err != nil {
+		return sf.err
+	}
+	err := sf.writeBlankRow(style)
+	if err != nil {
+		sf.err = err
+		return err
+	}
 	return sf.zipWriter.Flush()
 }
 
+func (sf *StreamFile) writeBlankRow(style StreamStyle) error {
+	if sf.currentSheet == nil {
+		return NoCurrentSheetError
+	}
+	if len(sf.currentSheet.bufferedRows) > 0 {
+		return BufferedSheetError
+	}
+	styleId, ok := sf.styleIdMap[style]
+	if !ok {
+		return errors.New("trying to make use of a style that has not been added")
+	}
+	sf.currentSheet.rowCount++
+	if err := sf.currentSheet.write(`<row r="` + strconv.Itoa(sf.currentSheet.rowCount) + `">`); err != nil {
+		return err
+	}
+	for colIndex := 0; colIndex < sf.currentSheet.columnCount; colIndex++ {
+		marshaledCell, err := xml.Marshal(xlsxC{
+			XMLName: xml.Name{Local: "c"},
+			R:       GetCellIDStringFromCoords(colIndex, sf.currentSheet.rowCount-1),
+			S:       styleId,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := sf.currentSheet.writer.Write(marshaledCell); err != nil {
+			return err
+		}
+	}
+	return sf.currentSheet.write(`</row>`)
+}
+
+// WriteSharedFormulaColumn writes count rows to the current sheet, each holding a single formula cell in the zero based
+// column col. The first cell is the master of a shared formula holding the given formula, and the rest only refer to
+// it, so that spreadsheet applications expand the formula down the column, adjusting its relative references. This
+// keeps files with many similar formulas small. The rows do not have to contain the same number of cells as the other
+// rows of the sheet, but col must be within the sheet's columns.
+func (sf *StreamFile) WriteSharedFormulaColumn(col int, master string, count int) error {
+	if sf.err != nil {
+		return sf.err
+	}
+	err := sf.writeSharedFormulaColumn(col, master, count)
+	if err != nil {
+		sf.err = err
+		return err
+	}
+	return sf.zipWriter.Flush()
+}
+
+func (sf *StreamFile) writeSharedFormulaColumn(col int, master string, count int) error {
+	if sf.currentSheet == nil {
+		return NoCurrentSheetError
+	}
+	if len(sf.currentSheet.bufferedRows) > 0 {
+		return BufferedSheetError
+	}
+	if col < 0 || (sf.currentSheet.columnCount != 0 && col >= sf.currentSheet.columnCount) {
+		return fmt.Errorf("column %d is out of range", col)
+	}
+	if count < 1 {
+		return nil
+	}
+	si := strconv.Itoa(sf.currentSheet.sharedFormulaCount)
+	sf.currentSheet.sharedFormulaCount++
+
+	firstRow := sf.currentSheet.rowCount
+	ref := GetCellIDStringFromCoords(col, firstRow) + cellRangeChar + GetCellIDStringFromCoords(col, firstRow+count-1)
+	var styleAttr string
+	if c := sf.xlsxFile.Sheets[sf.currentSheet.index-1].Col(col); c != nil && c.outXfID != 0 {
+		styleAttr = ` s="` + strconv.Itoa(c.outXfID) + `"`
+	}
+	for i := 0; i < count; i++ {
+		sf.currentSheet.rowCount++
+		cellCoordinate := GetCellIDStringFromCoords(col, sf.currentSheet.rowCount-1)
+		if err := sf.currentSheet.write(`<row r="` + strconv.Itoa(sf.currentSheet.rowCount) + `"><c r="` + cellCoordinate + `"` + styleAttr + `>`); err != nil {
+			return err
+		}
+		if i == 0 {
+			if err := sf.currentSheet.write(`<f t="shared" ref="` + ref + `" si="` + si + `">`); err != nil {
+				return err
+			}
+			if err := xml.EscapeText(sf.currentSheet.writer, []byte(master)); err != nil {
+				return err
+			}
+			if err := sf.currentSheet.write(`</f>`); err != nil {
+				return err
+			}
+		} else if err := sf.currentSheet.write(`<f t="shared" si="` + si + `"/>`); err != nil {
+			return err
+		}
+		if err := sf.currentSheet.write(`</c></row>`); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (sf *StreamFile) AddMergeCells(startRowIdx, startColumnIdx, endRowIdx, endColumnIdx int) {
 	start := GetCellIDStringFromCoords(startColumnIdx, startRowIdx)
 	end := GetCellIDStringFromCoords(endColumnIdx, endRowIdx)
@@ -133,6 +617,9 @@ func (sf *StreamFile) write(cells []string) error {
 	if sf.currentSheet == nil {
 		return NoCurrentSheetError
 	}
+	if len(sf.currentSheet.bufferedRows) > 0 {
+		return BufferedSheetError
+	}
 	cellCount := len(cells)
 	if cellCount != sf.currentSheet.columnCount {
 		if sf.currentSheet.columnCount != 0 {
@@ -142,6 +629,7 @@ func (sf *StreamFile) write(cells []string) error {
 	}
 
 	sf.currentSheet.rowCount++
+	sf.recordPivotCacheHeadings(cells)
 	if err := sf.currentSheet.write(`<row r="` + strconv.Itoa(sf.currentSheet.rowCount) + `">`); err != nil {
 		return err
 	}
@@ -165,6 +653,10 @@ func (sf *StreamFile) write(cells []string) error {
 		cellOpen += `><is><t>`
 		cellClose := `</t></is></c>`
 
+		cellData, err := sanitizeControlCharacters(cellData, sf.controlCharacterMode)
+		if err != nil {
+			return fmt.Errorf("cell %s: %v", cellCoordinate, err)
+		}
 		if err := sf.currentSheet.write(cellOpen); err != nil {
 			return err
 		}
@@ -241,9 +733,18 @@ func (sf *StreamFile) writeWithColumnDefaultMetadata(cells []string) error {
 }
 
 func (sf *StreamFile) writeS(cells []StreamCell) error {
+	return sf.writeRow(cells, streamRowAttrs{})
+}
+
+// writeRow writes a row of cells with the attributes attrs to the
+// current sheet.
+func (sf *StreamFile) writeRow(cells []StreamCell, attrs streamRowAttrs) error {
 	if sf.currentSheet == nil {
 		return NoCurrentSheetError
 	}
+	if len(sf.currentSheet.bufferedRows) > 0 {
+		return BufferedSheetError
+	}
 	if len(cells) != sf.currentSheet.columnCount {
 		if sf.currentSheet.columnCount != 0 {
 			return WrongNumberOfRowsError
@@ -252,13 +753,45 @@ func (sf *StreamFile) writeS(cells []StreamCell) error {
 	}
 
 	sf.currentSheet.rowCount++
+	if len(sf.xlsxFile.pivotCacheSources) > 0 {
+		values := make([]string, len(cells))
+		for i, cell := range cells {
+			values[i] = cell.cellData
+		}
+		sf.recordPivotCacheHeadings(values)
+	}
 	// Write the row opening
-	if err := sf.currentSheet.write(`<row r="` + strconv.Itoa(sf.currentSheet.rowCount) + `">`); err != nil {
+	rowOpen := `<row r="` + strconv.Itoa(sf.currentSheet.rowCount) + `"`
+	if rowStyleId, ok := sf.rowStyleId(); ok {
+		rowOpen += ` s="` + strconv.Itoa(rowStyleId) + `" customFormat="1"`
+	}
+	if attrs.height != 0 {
+		rowOpen += ` ht="` + strconv.FormatFloat(attrs.height, 'f', -1, 64) + `" customHeight="1"`
+	}
+	if attrs.collapsed {
+		rowOpen += ` hidden="1"`
+	}
+	if attrs.outlineLevel > 0 {
+		rowOpen += ` outlineLevel="` + strconv.Itoa(attrs.outlineLevel) + `"`
+	}
+	// Groups are summed up by the row below them, which is marked
+	// when the group is collapsed.
+	if sf.currentSheet.collapsedLevel > attrs.outlineLevel {
+		rowOpen += ` collapsed="1"`
+	}
+	sf.currentSheet.collapsedLevel = 0
+	if attrs.collapsed {
+		sf.currentSheet.collapsedLevel = attrs.outlineLevel
+	}
+	if err := sf.currentSheet.write(rowOpen + `>`); err != nil {
 		return err
 	}
 
 	// Add cells one by one
 	for colIndex, cell := range cells {
+		if cell.skip {
+			continue
+		}
 
 		xlsxCell, err := sf.getXlsxCell(cell, colIndex)
 		if err != nil {
@@ -273,7 +806,9 @@ func (sf *StreamFile) writeS(cells []StreamCell) error {
 		if _, err := sf.currentSheet.writer.Write(marshaledCell); err != nil {
 			return err
 		}
-
+		if cell.hyperlink != "" || cell.location != "" {
+			sf.currentSheet.hyperlinks = append(sf.currentSheet.hyperlinks, streamHyperlink{ref: xlsxCell.R, url: cell.hyperlink, location: cell.location})
+		}
 	}
 	// Write the row ending
 	if err := sf.currentSheet.write(`</row>`); err != nil {
@@ -291,14 +826,176 @@ func (sf *StreamFile) getXlsxCell(cell StreamCell, colIndex int) (xlsxC, error)
 	if cell.cellStyle != (StreamStyle{}) {
 		if idx, ok := sf.styleIdMap[cell.cellStyle]; ok {
 			cellStyleId = idx
+		} else if cell.autoStyle {
+			cellStyleId = sf.addStyle(cell.cellStyle, cell.numFmt)
 		} else {
 			return xlsxC{}, errors.New("trying to make use of a style that has not been added")
 		}
+	} else if rowStyleId, ok := sf.rowStyleId(); ok {
+		cellStyleId = rowStyleId
+	} else if col := sf.xlsxFile.Sheets[sf.currentSheet.index-1].Col(colIndex); col != nil {
+		// Cells are not covered by the style of their column
+		// once they are written, so they have to carry it.
+		cellStyleId = col.outXfID
+	}
+	if cellStyleId == 0 && cell.cellType == CellTypeNumeric && sf.defaultNumberFormat {
+		cellStyleId = sf.styleIdMap[StreamStyleDefaultDecimal]
 	}
 
+	if cell.hyperlink != "" && sf.absoluteLinkTargets {
+		if target, err := url.Parse(cell.hyperlink); err != nil || !target.IsAbs() {
+			return xlsxC{}, fmt.Errorf("cell %s: the hyperlink target %q is not an absolute URI", cellCoordinate, cell.hyperlink)
+		}
+	}
+
+	switch cell.cellType {
+	case CellTypeString, CellTypeInline, CellTypeStringFormula:
+		cellData, err := sanitizeControlCharacters(cell.cellData, sf.controlCharacterMode)
+		if err != nil {
+			return xlsxC{}, fmt.Errorf("cell %s: %v", cellCoordinate, err)
+		}
+		cell.cellData = cellData
+		if sf.quotePrefix && cell.cellType != CellTypeStringFormula && strings.HasPrefix(cell.cellData, "'") {
+			cell.cellData = cell.cellData[1:]
+			cellStyleId = sf.quotePrefixStyleId(cellStyleId)
+		}
+	}
+	if sf.alignByType {
+		switch cell.cellType {
+		case CellTypeNumeric, CellTypeDate:
+			cellStyleId = sf.alignedStyleId(cellStyleId, HorizontalRight)
+		case CellTypeString, CellTypeInline:
+			cellStyleId = sf.alignedStyleId(cellStyleId, HorizontalLeft)
+		}
+	}
+	if cell.formula != "" {
+		return xlsxC{XMLName: xml.Name{Local: "c"}, R: cellCoordinate, S: cellStyleId, T: "str", F: &xlsxF{Content: cell.formula}, V: cell.cellData}, nil
+	}
+	if cell.cellType == CellTypeBool && sf.boolsAsText {
+		return makeXlsxCell(CellTypeInline, cellCoordinate, cellStyleId, boolText(cell.cellData))
+	}
 	return makeXlsxCell(cell.cellType, cellCoordinate, cellStyleId, cell.cellData)
 }
 
+// quotePrefixStyleId returns the id of a copy of the style with id
+// styleId that has the quote prefix set, adding it to the style sheet
+// the first time.
+func (sf *StreamFile) quotePrefixStyleId(styleId int) int {
+	if id, ok := sf.quotePrefixStyleIds[styleId]; ok {
+		return id
+	}
+	styles := sf.xlsxFile.styles
+	var xf xlsxXf
+	if styleId < len(styles.CellXfs.Xf) {
+		xf = styles.CellXfs.Xf[styleId]
+	}
+	xf.QuotePrefix = true
+	id := styles.addCellXf(xf)
+	if sf.quotePrefixStyleIds == nil {
+		sf.quotePrefixStyleIds = make(map[int]int)
+	}
+	sf.quotePrefixStyleIds[styleId] = id
+	sf.stylesAdded = true
+	return id
+}
+
+// alignedStyle identifies a copy of a style with a horizontal
+// alignment.
+type alignedStyle struct {
+	styleId    int
+	horizontal string
+}
+
+// alignedStyleId returns the id of a copy of the style with id styleId
+// that is aligned horizontally, adding it to the style sheet the first
+// time.  Styles that already have a horizontal alignment other than
+// the general one are kept as they are.
+func (sf *StreamFile) alignedStyleId(styleId int, horizontal string) int {
+	key := alignedStyle{styleId: styleId, horizontal: horizontal}
+	if id, ok := sf.alignedStyleIds[key]; ok {
+		return id
+	}
+	styles := sf.xlsxFile.styles
+	var xf xlsxXf
+	if styleId < len(styles.CellXfs.Xf) {
+		xf = styles.CellXfs.Xf[styleId]
+	}
+	if xf.Alignment.Horizontal != "" && xf.Alignment.Horizontal != HorizontalGeneral {
+		return styleId
+	}
+	xf.ApplyAlignment = true
+	xf.Alignment.Horizontal = horizontal
+	id := styles.addCellXf(xf)
+	if sf.alignedStyleIds == nil {
+		sf.alignedStyleIds = make(map[alignedStyle]int)
+	}
+	sf.alignedStyleIds[key] = id
+	sf.stylesAdded = true
+	return id
+}
+
+// addStyle adds a style to the style sheet of a file that has
+// already been built, and returns its id.  A non empty numFmt is the
+// number format code of the style, which takes the place of its
+// number format id.
+func (sf *StreamFile) addStyle(style StreamStyle, numFmt string) int {
+	numFmtId := style.xNumFmtId
+	if numFmt != "" {
+		numFmtId = sf.xlsxFile.styles.newNumFmt(numFmt).NumFmtId
+	}
+	id := handleStyleForXLSX(style.style, numFmtId, sf.xlsxFile.styles)
+	sf.styleIdMap[style] = id
+	sf.stylesAdded = true
+	return id
+}
+
+// writeStyles writes the style sheet, including any styles added
+// since the file was built.
+func (sf *StreamFile) writeStyles() error {
+	stylesXML := sf.stylesXML
+	if sf.stylesAdded {
+		var err error
+		stylesXML, err = sf.xlsxFile.styles.Marshal()
+		if err != nil {
+			return err
+		}
+	}
+	stylesFile, err := sf.zipWriter.Create(stylesFilePath)
+	if err != nil {
+		return err
+	}
+	_, err = stylesFile.Write([]byte(stylesXML))
+	return err
+}
+
+// boolText returns the text "TRUE" or "FALSE" for the data of a
+// boolean cell.  Data that is not a boolean is returned unchanged.
+func boolText(cellData string) string {
+	b, err := strconv.ParseBool(cellData)
+	if err != nil {
+		return cellData
+	}
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// rowStyleId returns the id of the style that SetAlternatingRowStyles
+// gave to the row currently being written, if any.
+func (sf *StreamFile) rowStyleId() (int, bool) {
+	styles, ok := sf.sheetAlternatingRowStyles[sf.currentSheet.index-1]
+	if !ok {
+		return 0, false
+	}
+	style := styles.odd
+	if sf.currentSheet.rowCount%2 == 0 {
+		style = styles.even
+	}
+	id, ok := sf.styleIdMap[style]
+	return id, ok
+}
+
 func makeXlsxCell(cellType CellType, cellCoordinate string, cellStyleId int, cellData string) (xlsxC, error) {
 	// documentation for the c.t (cell.Type) attribute:
 	// b (Boolean): Cell containing a boolean.
@@ -320,6 +1017,11 @@ func makeXlsxCell(cellType CellType, cellCoordinate string, cellStyleId int, cel
 	case CellTypeInline:
 		return xlsxC{XMLName: xml.Name{Local: "c"}, R: cellCoordinate, S: cellStyleId, T: "inlineStr", Is: &xlsxSI{T: cellData}}, nil
 	case CellTypeNumeric:
+		// Excel has no numbers that are not finite, and takes a file
+		// that holds one for corrupt, so they are written as errors.
+		if cellData == "NaN" || cellData == "+Inf" || cellData == "-Inf" {
+			return xlsxC{XMLName: xml.Name{Local: "c"}, R: cellCoordinate, S: cellStyleId, T: "e", V: "#NUM!"}, nil
+		}
 		return xlsxC{XMLName: xml.Name{Local: "c"}, R: cellCoordinate, S: cellStyleId, T: "n", V: cellData}, nil
 	case CellTypeString:
 		// TODO Currently shared strings are types as inline strings
@@ -360,6 +1062,7 @@ func (sf *StreamFile) NextSheet() error {
 			sf.err = err
 			return err
 		}
+		sf.sheetsWritten++
 		sheetIndex = sf.currentSheet.index
 	}
 	sheetIndex++
@@ -381,6 +1084,40 @@ func (sf *StreamFile) NextSheet() error {
 		sf.err = err
 		return err
 	}
+	if err := sf.writeHeatmap(); err != nil {
+		sf.err = err
+		return err
+	}
+	if err := sf.writeReport(); err != nil {
+		sf.err = err
+		return err
+	}
+	return nil
+}
+
+// writeReport writes the rows of the report set for the current sheet
+// with StreamFileBuilder.WriteReport, if any.
+func (sf *StreamFile) writeReport() error {
+	for _, cells := range sf.sheetReports[sf.currentSheet.index-1] {
+		if err := sf.writeS(cells); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeHeatmap writes the rows of the heatmap set for the current
+// sheet with StreamFileBuilder.WriteHeatmap, if any.
+func (sf *StreamFile) writeHeatmap() error {
+	for _, values := range sf.sheetHeatmaps[sf.currentSheet.index-1] {
+		cells := make([]StreamCell, len(values))
+		for i, v := range values {
+			cells[i] = NewStreamCell(strconv.FormatFloat(v, 'f', -1, 64), StreamStyle{}, CellTypeNumeric)
+		}
+		if err := sf.writeS(cells); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -404,6 +1141,15 @@ func (sf *StreamFile) Close() error {
 			sf.err = err
 			return err
 		}
+		sf.sheetsWritten++
+	}
+	if err := sf.writeStyles(); err != nil {
+		sf.err = err
+		return err
+	}
+	if err := sf.writePivotCaches(); err != nil {
+		sf.err = err
+		return err
 	}
 	err := sf.zipWriter.Close()
 	if err != nil {
@@ -412,6 +1158,45 @@ func (sf *StreamFile) Close() error {
 	return err
 }
 
+// recordPivotCacheHeadings keeps the values of the row just written to
+// the current sheet that name the fields of a pivot cache source.
+func (sf *StreamFile) recordPivotCacheHeadings(values []string) {
+	for i, source := range sf.xlsxFile.pivotCacheSources {
+		if source.sheet != sf.xlsxFile.Sheets[sf.currentSheet.index-1] || source.minRow != sf.currentSheet.rowCount-1 {
+			continue
+		}
+		headings := make([]string, source.maxCol-source.minCol+1)
+		for j := range headings {
+			if col := source.minCol + j; col < len(values) {
+				headings[j] = values[col]
+			}
+		}
+		if sf.pivotCacheHeadings == nil {
+			sf.pivotCacheHeadings = make(map[int][]string)
+		}
+		sf.pivotCacheHeadings[i] = headings
+	}
+}
+
+// writePivotCaches writes the definitions of the pivot caches of the
+// file, see StreamFileBuilder.AddPivotCacheSource.
+func (sf *StreamFile) writePivotCaches() error {
+	for i, source := range sf.xlsxFile.pivotCacheSources {
+		body, err := xml.Marshal(source.makeXLSXPivotCacheDefinition(sf.pivotCacheHeadings[i]))
+		if err != nil {
+			return err
+		}
+		w, err := sf.zipWriter.Create(pivotCachePath(i + 1))
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, xml.Header+string(body)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // writeSheetStart will write the start of the Sheet's XML
 func (sf *StreamFile) writeSheetStart() error {
 	if sf.currentSheet == nil {
@@ -425,26 +1210,114 @@ func (sf *StreamFile) writeSheetEnd() error {
 	if sf.currentSheet == nil {
 		return NoCurrentSheetError
 	}
+	if err := sf.writeBufferedRows(); err != nil {
+		return err
+	}
 	if err := sf.currentSheet.write(endSheetDataTag); err != nil {
 		return err
 	}
 
-	if len(sf.currentSheet.mergeCells) > 0 {
-		mergeCellData := "<mergeCells count=\"" + strconv.Itoa(len(sf.currentSheet.mergeCells)) + "\">"
-		if err := sf.currentSheet.write(mergeCellData); err != nil {
+	suffix := sf.sheetXmlSuffix[sf.currentSheet.index-1]
+	mergeCells := append(append([]string(nil), sf.sheetMerges[sf.currentSheet.index-1]...), sf.currentSheet.mergeCells...)
+	if len(mergeCells) > 0 {
+		var mergeCellData strings.Builder
+		mergeCellData.WriteString("<mergeCells count=\"" + strconv.Itoa(len(mergeCells)) + "\">")
+		for _, ref := range mergeCells {
+			mergeCellData.WriteString("<mergeCell ref=\"" + ref + "\"/>")
+		}
+		mergeCellData.WriteString("</mergeCells>")
+		// The merged cells follow the filter of the sheet, and go
+		// ahead of everything else the suffix may hold.
+		suffix = insertBeforeFirst(suffix, mergeCellData.String(),
+			"<phoneticPr", "<conditionalFormatting", "<dataValidations", "<hyperlinks", "<printOptions")
+	}
+	relsPath := sheetRelsPathPrefix + strconv.Itoa(sf.currentSheet.index) + ".xml.rels"
+	rels, ok := sf.sheetRels[relsPath]
+	if len(sf.currentSheet.hyperlinks) > 0 {
+		var err error
+		suffix, rels, err = sf.addHyperlinks(suffix, rels)
+		if err != nil {
 			return err
 		}
-		for _, ref := range sf.currentSheet.mergeCells {
-			if err := sf.currentSheet.write("<mergeCell ref=\"" + ref + "\"/>"); err != nil {
-				return err
-			}
+		ok = rels != ""
+	}
+	if err := sf.currentSheet.write(suffix); err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	w, err := sf.zipWriter.Create(relsPath)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, rels)
+	return err
+}
+
+// addHyperlinks adds the hyperlinks of the current sheet to the end of
+// its XML, suffix, and to its relations, rels, which is empty if the
+// sheet had none before.  Links within the workbook add no relations,
+// so rels is still empty if those are all the sheet has.
+func (sf *StreamFile) addHyperlinks(suffix, rels string) (string, string, error) {
+	xRels := xlsxWorksheetRels{}
+	if rels != "" {
+		if err := xml.Unmarshal([]byte(rels), &xRels); err != nil {
+			return "", "", err
 		}
-		if err := sf.currentSheet.write("</mergeCells>"); err != nil {
-			return err
+	}
+	var hyperlinks strings.Builder
+	hyperlinks.WriteString(`<hyperlinks>`)
+	for _, link := range sf.currentSheet.hyperlinks {
+		if link.url == "" {
+			// Links within the workbook need no relation.
+			hyperlinks.WriteString(`<hyperlink ref="` + link.ref + `" location="`)
+			if err := xml.EscapeText(&hyperlinks, []byte(link.location)); err != nil {
+				return "", "", err
+			}
+			hyperlinks.WriteString(`"></hyperlink>`)
+			continue
 		}
+		relId := xRels.nextId()
+		xRels.Relationships = append(xRels.Relationships, xlsxWorksheetRelation{
+			Id:         relId,
+			Type:       RelationshipTypeHyperlink,
+			Target:     link.url,
+			TargetMode: RelationshipTargetModeExternal,
+		})
+		hyperlinks.WriteString(`<hyperlink ref="` + link.ref + `" r:id="` + relId + `"></hyperlink>`)
+	}
+	hyperlinks.WriteString(`</hyperlinks>`)
+	// The hyperlinks go ahead of the print settings of the sheet.
+	i := strings.Index(suffix, "<printOptions")
+	if i == -1 {
+		i = 0
+	}
+	suffix = suffix[:i] + hyperlinks.String() + suffix[i:]
+	if len(xRels.Relationships) == 0 {
+		return suffix, rels, nil
+	}
+	body, err := xml.Marshal(xRels)
+	if err != nil {
+		return "", "", err
 	}
+	return suffix, xml.Header + string(body), nil
+}
 
-	return sf.currentSheet.write(sf.sheetXmlSuffix[sf.currentSheet.index-1])
+// insertBeforeFirst returns the XML of the suffix of a sheet with data
+// inserted ahead of the first of the elements that starts with one of
+// tags, or at its start if it has none of them.
+func insertBeforeFirst(suffix, data string, tags ...string) string {
+	i := -1
+	for _, tag := range tags {
+		if j := strings.Index(suffix, tag); j != -1 && (i == -1 || j < i) {
+			i = j
+		}
+	}
+	if i == -1 {
+		i = 0
+	}
+	return suffix[:i] + data + suffix[i:]
 }
 
 func (ss *streamSheet) write(data string) error {
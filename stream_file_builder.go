@@ -33,8 +33,10 @@ package xlsx
 import (
 	"archive/zip"
 	"errors"
+	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -57,12 +59,124 @@ type StreamFileBuilder struct {
 	streamingCellMetadatas                  map[int]*StreamingCellMetadata
 	sheetStreamStyles                       map[int]cellStreamStyle
 	sheetDefaultCellType                    map[int]defaultCellType
+	sheetAlternatingRowStyles               map[int]alternatingRowStyles
+	sheetHeatmaps                           map[int][][]float64
+	sheetReports                            map[int][][]StreamCell
+	sheetMerges                             map[int][]string
+	tableOfContents                         string
+	boolsAsText                             bool
+	controlCharacterMode                    ControlCharacterMode
+	quotePrefix                             bool
+	absoluteLinkTargets                     bool
+	alignByType                             bool
+	omitDefaultSheetView                    bool
 	defaultColumnStreamingCellMetadataAdded bool
+	// defaultNumberFormat is set by SetDefaultNumberFormat, which
+	// gives the default integer and decimal styles the number format
+	// defaultNumFmtId.
+	defaultNumberFormat bool
+	defaultNumFmtId     int
+}
+
+// alternatingRowStyles holds the styles given to the even and odd
+// numbered rows of a sheet.
+type alternatingRowStyles struct {
+	even, odd StreamStyle
+}
+
+// AddPivotCacheSource writes a pivot cache over the range rng, such as
+// "A1:D100", of the sheet at sheetIndex, from which pivot tables can
+// be inserted into the file.  The first row of the range holds the
+// names of the fields and the rows below it their data.  No pivot table
+// is written, and the cache holds no records of its own; it is filled
+// from the sheet when the file is opened.
+func (sb *StreamFileBuilder) AddPivotCacheSource(sheetIndex int, rng string) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	if sheetIndex < 0 || sheetIndex >= len(sb.xlsxFile.Sheets) {
+		return errors.New("sheet index out of range")
+	}
+	source, err := newPivotCacheSource(sb.xlsxFile.Sheets[sheetIndex], rng)
+	if err != nil {
+		return err
+	}
+	sb.xlsxFile.pivotCacheSources = append(sb.xlsxFile.pivotCacheSources, source)
+	return nil
+}
+
+// SetQuotePrefix controls how string cells written with WriteS whose
+// text starts with an apostrophe are written.  By default the
+// apostrophe is kept as part of the text.  When enabled it is taken as
+// the prefix spreadsheet applications use to mark a value as text: it
+// is dropped from the text and the cell's style gets the quote prefix
+// instead, see Style.QuotePrefix.
+func (sb *StreamFileBuilder) SetQuotePrefix(enabled bool) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	sb.quotePrefix = enabled
+	return nil
+}
+
+// SetOmitDefaultSheetView controls whether sheets that have no view of
+// their own are written with the default sheetViews element.  When
+// enabled it is left out, which makes the file smaller; spreadsheet
+// applications then show those sheets with their own defaults.  A
+// sheet other than the first that is Selected keeps its view.
+func (sb *StreamFileBuilder) SetOmitDefaultSheetView(enabled bool) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	sb.omitDefaultSheetView = enabled
+	return nil
+}
+
+// SetControlCharacterMode sets how strings written to the file deal
+// with the control characters that XML does not allow, which would
+// otherwise make the file unreadable.  By default they are stripped.
+func (sb *StreamFileBuilder) SetControlCharacterMode(mode ControlCharacterMode) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	sb.controlCharacterMode = mode
+	return nil
+}
+
+// SetAbsoluteLinkTargets controls the targets of the hyperlinks of
+// streamed cells.  They are always written as external relationships,
+// with TargetMode="External".  When enabled, a target must also be an
+// absolute URI, such as "https://example.com/a", and writing a cell
+// that links to a relative one, such as "a.html", fails, rather than
+// leaving the link to be resolved by the application that opens the
+// file, which some do not.
+func (sb *StreamFileBuilder) SetAbsoluteLinkTargets(enabled bool) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	sb.absoluteLinkTargets = enabled
+	return nil
+}
+
+// SetAlignByType controls the horizontal alignment of cells written
+// with WriteS whose style leaves it to the application, as the default
+// styles do.  When enabled they are aligned the way spreadsheet
+// applications align them on their own: numbers and dates to the
+// right, and text to the left.  This keeps the alignment when the file
+// is opened by applications that would otherwise align them all alike.
+func (sb *StreamFileBuilder) SetAlignByType(enabled bool) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	sb.alignByType = enabled
+	return nil
 }
 
 const (
 	sheetFilePathPrefix = "xl/worksheets/sheet"
 	sheetFilePathSuffix = ".xml"
+	stylesFilePath      = "xl/styles.xml"
+	sheetRelsPathPrefix = "xl/worksheets/_rels/sheet"
 	endSheetDataTag     = "</sheetData>"
 	dimensionTag        = `<dimension ref="%s"></dimension>`
 	// This is the index of the max style that this library will insert into XLSX sheets by default.
@@ -76,16 +190,20 @@ var BuiltStreamFileBuilderError = errors.New("StreamFileBuilder has already been
 // NewStreamFileBuilder creates an StreamFileBuilder that will write to the the provided io.writer
 func NewStreamFileBuilder(writer io.Writer) *StreamFileBuilder {
 	return &StreamFileBuilder{
-		zipWriter:              zip.NewWriter(writer),
-		xlsxFile:               NewFile(),
-		cellTypeToStyleIds:     make(map[CellType]int),
-		maxStyleId:             initMaxStyleId,
-		customStreamStyles:     make(map[StreamStyle]struct{}),
-		customNumFormats:       make(map[int]xlsxNumFmt),
-		styleIdMap:             make(map[StreamStyle]int),
-		streamingCellMetadatas: make(map[int]*StreamingCellMetadata),
-		sheetStreamStyles:      make(map[int]cellStreamStyle),
-		sheetDefaultCellType:   make(map[int]defaultCellType),
+		zipWriter:                 zip.NewWriter(writer),
+		xlsxFile:                  NewFile(),
+		cellTypeToStyleIds:        make(map[CellType]int),
+		maxStyleId:                initMaxStyleId,
+		customStreamStyles:        make(map[StreamStyle]struct{}),
+		customNumFormats:          make(map[int]xlsxNumFmt),
+		styleIdMap:                make(map[StreamStyle]int),
+		streamingCellMetadatas:    make(map[int]*StreamingCellMetadata),
+		sheetStreamStyles:         make(map[int]cellStreamStyle),
+		sheetDefaultCellType:      make(map[int]defaultCellType),
+		sheetAlternatingRowStyles: make(map[int]alternatingRowStyles),
+		sheetHeatmaps:             make(map[int][][]float64),
+		sheetReports:              make(map[int][][]StreamCell),
+		sheetMerges:               make(map[int][]string),
 	}
 }
 
@@ -207,15 +325,26 @@ func (sb *StreamFileBuilder) AddSheetWithDefaultColumnMetadata(name string, colu
 // All the styles in columnStyles have to have been added or an error will be returned.
 // Sheet names must be unique, or an error will be returned.
 func (sb *StreamFileBuilder) AddSheetS(name string, columnStyles []StreamStyle) error {
+	return sb.AddSheetSWithState(name, columnStyles, SheetStateVisible)
+}
+
+// AddSheetSWithState adds a sheet as AddSheetS does, in the given state,
+// so that helper sheets can be hidden from the user.  At least one
+// sheet of the workbook has to be left visible.
+func (sb *StreamFileBuilder) AddSheetSWithState(name string, columnStyles []StreamStyle, state SheetState) error {
 	if sb.built {
 		return BuiltStreamFileBuilderError
 	}
+	if err := state.validate(); err != nil {
+		return err
+	}
 	sheet, err := sb.xlsxFile.AddSheet(name)
 	if err != nil {
 		// Set built on error so that all subsequent calls to the builder will also fail.
 		sb.built = true
 		return err
 	}
+	sheet.SetState(state)
 	// To make sure no new styles can be added after adding a sheet
 	sb.firstSheetAdded = true
 
@@ -253,20 +382,706 @@ func (sb *StreamFileBuilder) AddValidation(sheetIndex int, validation *xlsxDataV
 	sheet.AddDataValidation(validation)
 }
 
+// AddDataValidation adds the validation, such as a drop down list made
+// with NewDataValidation and SetDropList, to the cells in ref of the
+// sheet at sheetIndex.  The ref is a cell or a range, such as
+// "B2:B100", or several of them separated by spaces, and takes the
+// place of the cells the validation was made for.  The validations of
+// a sheet are written after its rows.
+func (sb *StreamFileBuilder) AddDataValidation(sheetIndex int, ref string, validation *xlsxDataValidation) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	if sheetIndex < 0 || sheetIndex >= len(sb.xlsxFile.Sheets) {
+		return errors.New("sheet index out of range")
+	}
+	if validation == nil {
+		return errors.New("the data validation is nil")
+	}
+	refs := strings.Fields(ref)
+	if len(refs) == 0 {
+		return errors.New("the data validation has no cells")
+	}
+	for _, r := range refs {
+		rng := r
+		switch strings.Count(r, cellRangeChar) {
+		case 0:
+			rng = r + cellRangeChar + r
+		case 1:
+		default:
+			return fmt.Errorf("invalid data validation range %q", r)
+		}
+		minCol, minRow, maxCol, maxRow, err := getMaxMinFromDimensionRef(rng)
+		if err != nil || minCol < 0 || minRow < 0 || minCol > maxCol || minRow > maxRow {
+			return fmt.Errorf("invalid data validation range %q", r)
+		}
+	}
+	dv := *validation
+	dv.Sqref = strings.Join(refs, " ")
+	sb.xlsxFile.Sheets[sheetIndex].AddDataValidation(&dv)
+	return nil
+}
+
+// SetColStyle sets the style of the columns colStart to colEnd
+// (inclusive, counting from 1) of the sheet at sheetIndex.  The
+// style's number format is applied to the columns along with the rest
+// of the style, so cells that are never written take it on, as do
+// cells written with StreamStyleFromColumn.  The style has to have
+// been added with AddStreamStyle.
+func (sb *StreamFileBuilder) SetColStyle(sheetIndex, colStart, colEnd int, style StreamStyle) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	if sheetIndex < 0 || sheetIndex >= len(sb.xlsxFile.Sheets) {
+		return errors.New("sheet index out of range")
+	}
+	if err := checkColRange(colStart, colEnd); err != nil {
+		return err
+	}
+	if _, ok := sb.customStreamStyles[style]; !ok {
+		return errors.New("trying to make use of a style that has not been added")
+	}
+	numFmt, ok := builtInNumFmt[style.xNumFmtId]
+	if !ok {
+		xNumFmt, ok := sb.customNumFormats[style.xNumFmtId]
+		if !ok {
+			return errors.New("trying to make use of a number format that has not been added")
+		}
+		numFmt = xNumFmt.FormatCode
+	}
+	sheet := sb.xlsxFile.Sheets[sheetIndex]
+	sheet.setCol(colStart, colEnd, func(col *Col) {
+		col.SetStyle(style.style)
+		col.numFmt = numFmt
+		col.parsedNumFmt = nil
+	})
+	return nil
+}
+
+// SetColWidth sets the width of the columns colStart to colEnd
+// (inclusive, counting from 1) of the sheet at sheetIndex.  Columns
+// that are also given a style with SetColStyle keep it, and are
+// written as a single col element with both.
+func (sb *StreamFileBuilder) SetColWidth(sheetIndex, colStart, colEnd int, width float64) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	if sheetIndex < 0 || sheetIndex >= len(sb.xlsxFile.Sheets) {
+		return errors.New("sheet index out of range")
+	}
+	if err := checkColRange(colStart, colEnd); err != nil {
+		return err
+	}
+	if width <= 0 {
+		return errors.New("the width of a column must be positive")
+	}
+	sb.xlsxFile.Sheets[sheetIndex].SetColWidth(colStart, colEnd, width)
+	return nil
+}
+
+// SetColOutlineLevel groups the columns colStart to colEnd (inclusive,
+// counting from 1) of the sheet at sheetIndex at the outline level,
+// from 1 to 7, or ungroups them if it is 0.  Spreadsheet applications
+// show a bar above the columns to expand and collapse the groups.
+func (sb *StreamFileBuilder) SetColOutlineLevel(sheetIndex, colStart, colEnd, level int) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	if sheetIndex < 0 || sheetIndex >= len(sb.xlsxFile.Sheets) {
+		return errors.New("sheet index out of range")
+	}
+	if err := checkColRange(colStart, colEnd); err != nil {
+		return err
+	}
+	if level < 0 || level > 7 {
+		return fmt.Errorf("invalid outline level %d, it must be from 0 to 7", level)
+	}
+	sb.xlsxFile.Sheets[sheetIndex].SetOutlineLevel(colStart, colEnd, uint8(level))
+	return nil
+}
+
+// SetMaxRowOutlineLevel declares the highest outline level, from 0 to
+// 7, of the rows that are to be written to the sheet at sheetIndex with
+// StreamFile.WriteSWithOutline.  The start of the sheet, which is
+// written before its rows, has to hold it for spreadsheet applications
+// to show the bar to expand and collapse the groups.
+func (sb *StreamFileBuilder) SetMaxRowOutlineLevel(sheetIndex, level int) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	if sheetIndex < 0 || sheetIndex >= len(sb.xlsxFile.Sheets) {
+		return errors.New("sheet index out of range")
+	}
+	if level < 0 || level > 7 {
+		return fmt.Errorf("invalid outline level %d, it must be from 0 to 7", level)
+	}
+	sb.xlsxFile.Sheets[sheetIndex].streamOutlineLevelRow = uint8(level)
+	return nil
+}
+
+// SetColCollapsed collapses or expands the grouped columns colStart to
+// colEnd (inclusive, counting from 1) of the sheet at sheetIndex, see
+// SetColOutlineLevel.  The columns of a collapsed group are hidden.
+func (sb *StreamFileBuilder) SetColCollapsed(sheetIndex, colStart, colEnd int, collapsed bool) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	if sheetIndex < 0 || sheetIndex >= len(sb.xlsxFile.Sheets) {
+		return errors.New("sheet index out of range")
+	}
+	if err := checkColRange(colStart, colEnd); err != nil {
+		return err
+	}
+	sb.xlsxFile.Sheets[sheetIndex].setCol(colStart, colEnd, func(col *Col) {
+		col.Collapsed = collapsed
+		col.Hidden = collapsed
+	})
+	return nil
+}
+
+// maxColumnCount is the number of columns a worksheet can have.
+const maxColumnCount = 16384
+
+// checkColRange returns an error if colStart to colEnd, counting from
+// 1, is not a range of the columns of a worksheet.
+func checkColRange(colStart, colEnd int) error {
+	if colStart < 1 || colEnd < colStart || colEnd > maxColumnCount {
+		return fmt.Errorf("invalid column range %d to %d", colStart, colEnd)
+	}
+	return nil
+}
+
+// SetAlternatingRowStyles makes the rows written to the sheet at
+// sheetIndex alternate between two styles.  Rows are numbered from 1
+// as they are in spreadsheet applications, so the first row gets the
+// odd style, the second the even style and so on.  The style is set on
+// the row itself and on each cell written with StreamStyleFromColumn;
+// cells given their own style keep it.  Both styles have to have been
+// added with AddStreamStyle.
+func (sb *StreamFileBuilder) SetAlternatingRowStyles(sheetIndex int, even, odd StreamStyle) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	if sheetIndex < 0 || sheetIndex >= len(sb.xlsxFile.Sheets) {
+		return errors.New("sheet index out of range")
+	}
+	for _, style := range []StreamStyle{even, odd} {
+		if _, ok := sb.customStreamStyles[style]; !ok {
+			return errors.New("trying to make use of a style that has not been added")
+		}
+	}
+	sb.sheetAlternatingRowStyles[sheetIndex] = alternatingRowStyles{even: even, odd: odd}
+	return nil
+}
+
+// SetSheetBackground sets the image that is tiled behind the cells of
+// the sheet at sheetIndex.  format is the image's file extension, see
+// Sheet.SetBackground.
+func (sb *StreamFileBuilder) SetSheetBackground(sheetIndex int, img []byte, format string) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	if sheetIndex < 0 || sheetIndex >= len(sb.xlsxFile.Sheets) {
+		return errors.New("sheet index out of range")
+	}
+	return sb.xlsxFile.Sheets[sheetIndex].SetBackground(img, format)
+}
+
+// RegisterMerge merges the cells of the range ref, such as "A1:D1",
+// of the sheet at sheetIndex, for instance to have a title span the
+// columns of a report.  The merges are written when the sheet is
+// finished.  The range has to cover more than one cell, lie within
+// the columns of the sheet and not overlap a range that has already
+// been registered.  See also StreamFile.AddMergeCells, which merges
+// cells of the current sheet as it is written.
+func (sb *StreamFileBuilder) RegisterMerge(sheetIndex int, ref string) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	if sheetIndex < 0 || sheetIndex >= len(sb.xlsxFile.Sheets) {
+		return errors.New("sheet index out of range")
+	}
+	if strings.Count(ref, cellRangeChar) != 1 {
+		return fmt.Errorf("invalid merge range %q", ref)
+	}
+	minCol, minRow, maxCol, maxRow, err := getMaxMinFromDimensionRef(ref)
+	if err != nil || minCol < 0 || minRow < 0 || minCol > maxCol || minRow > maxRow {
+		return fmt.Errorf("invalid merge range %q", ref)
+	}
+	if minCol == maxCol && minRow == maxRow {
+		return fmt.Errorf("the merge range %q covers a single cell", ref)
+	}
+	columnCount := len(sb.styleIds[sheetIndex])
+	if styles := len(sb.sheetStreamStyles[sheetIndex]); styles > columnCount {
+		columnCount = styles
+	}
+	if maxCol >= columnCount {
+		return fmt.Errorf("the merge range %q lies beyond the columns of the sheet", ref)
+	}
+	return sb.addMerge(sheetIndex, ref)
+}
+
+// SetAutoFilter adds the filter drop downs to the cells in ref, such as
+// the header row "A1:D1", of the sheet at sheetIndex.  The filter then
+// applies to the rows below them.  The range must lie within the
+// columns the sheet was added with.
+func (sb *StreamFileBuilder) SetAutoFilter(sheetIndex int, ref string) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	if sheetIndex < 0 || sheetIndex >= len(sb.xlsxFile.Sheets) {
+		return errors.New("sheet index out of range")
+	}
+	if strings.Count(ref, cellRangeChar) != 1 {
+		return fmt.Errorf("invalid auto filter range %q", ref)
+	}
+	minCol, minRow, maxCol, maxRow, err := getMaxMinFromDimensionRef(ref)
+	if err != nil || minCol < 0 || minRow < 0 || minCol > maxCol || minRow > maxRow {
+		return fmt.Errorf("invalid auto filter range %q", ref)
+	}
+	columnCount := len(sb.styleIds[sheetIndex])
+	if styles := len(sb.sheetStreamStyles[sheetIndex]); styles > columnCount {
+		columnCount = styles
+	}
+	if maxCol >= columnCount {
+		return fmt.Errorf("the auto filter range %q lies beyond the columns of the sheet", ref)
+	}
+	sb.xlsxFile.Sheets[sheetIndex].AutoFilter = &AutoFilter{
+		TopLeftCell:     GetCellIDStringFromCoords(minCol, minRow),
+		BottomRightCell: GetCellIDStringFromCoords(maxCol, maxRow),
+	}
+	return nil
+}
+
+// addMerge adds the valid merge range ref to the sheet at sheetIndex,
+// unless it overlaps a range that has already been registered.
+func (sb *StreamFileBuilder) addMerge(sheetIndex int, ref string) error {
+	minCol, minRow, maxCol, maxRow, _ := getMaxMinFromDimensionRef(ref)
+	for _, other := range sb.sheetMerges[sheetIndex] {
+		otherMinCol, otherMinRow, otherMaxCol, otherMaxRow, _ := getMaxMinFromDimensionRef(other)
+		if minCol <= otherMaxCol && otherMinCol <= maxCol && minRow <= otherMaxRow && otherMinRow <= maxRow {
+			return fmt.Errorf("the merge range %q overlaps %q", ref, other)
+		}
+	}
+	sb.sheetMerges[sheetIndex] = append(sb.sheetMerges[sheetIndex], ref)
+	return nil
+}
+
+// AddRowBreak adds a manual page break below the row at the zero based
+// index afterRow of the sheet at sheetIndex, see Sheet.AddRowBreak.
+func (sb *StreamFileBuilder) AddRowBreak(sheetIndex, afterRow int) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	if sheetIndex < 0 || sheetIndex >= len(sb.xlsxFile.Sheets) {
+		return errors.New("sheet index out of range")
+	}
+	if afterRow < 0 || afterRow >= maxRowIndex {
+		return errors.New("row index out of range")
+	}
+	sb.xlsxFile.Sheets[sheetIndex].AddRowBreak(afterRow)
+	return nil
+}
+
+// AddColBreak adds a manual page break to the right of the column at
+// the zero based index afterCol of the sheet at sheetIndex, see
+// Sheet.AddColBreak.
+func (sb *StreamFileBuilder) AddColBreak(sheetIndex, afterCol int) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	if sheetIndex < 0 || sheetIndex >= len(sb.xlsxFile.Sheets) {
+		return errors.New("sheet index out of range")
+	}
+	if afterCol < 0 || afterCol >= maxColIndex {
+		return errors.New("column index out of range")
+	}
+	sb.xlsxFile.Sheets[sheetIndex].AddColBreak(afterCol)
+	return nil
+}
+
+// WriteHeatmap writes the matrix of numbers data as the first rows of
+// the sheet at sheetIndex, and shades them with a two color scale
+// running from minColor for the lowest number to maxColor for the
+// highest.  The colors are ARGB hex strings, such as "FFF8696B".  The
+// rows are written when the sheet is started, further rows written to
+// the sheet follow them and have to have as many cells.
+func (sb *StreamFileBuilder) WriteHeatmap(sheetIndex int, data [][]float64, minColor, maxColor string) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	if sheetIndex < 0 || sheetIndex >= len(sb.xlsxFile.Sheets) {
+		return errors.New("sheet index out of range")
+	}
+	if len(data) == 0 || len(data[0]) == 0 {
+		return errors.New("the heatmap has no values")
+	}
+	for _, row := range data {
+		if len(row) != len(data[0]) {
+			return errors.New("rows of the heatmap must have the same number of values")
+		}
+	}
+	if _, ok := sb.sheetReports[sheetIndex]; ok {
+		return errors.New("the sheet already starts with a report")
+	}
+	sheet := sb.xlsxFile.Sheets[sheetIndex]
+	if sheet.MaxCol != 0 && sheet.MaxCol != len(data[0]) {
+		return WrongNumberOfRowsError
+	}
+	ref := "A1:" + GetCellIDStringFromCoords(len(data[0])-1, len(data)-1)
+	sheet.AddColorScale(ref, minColor, maxColor)
+	sb.sheetHeatmaps[sheetIndex] = data
+	return nil
+}
+
+// WriteReport writes a report as the first rows of the sheet at
+// sheetIndex: a bold title merged across the columns of the report,
+// the metadata pairs with their names in italics, an empty row, the
+// bold headers, and then the rows of data, one column per header.  The
+// cells of the data take their type from their values: numbers, bools
+// and time.Time values are written as such, nil values leave the cell
+// empty and anything else is written as text.  The rows are written
+// when the sheet is started, further rows written to the sheet follow
+// them and have to have as many cells.
+func (sb *StreamFileBuilder) WriteReport(sheetIndex int, title string, meta [][2]string, headers []string, rows [][]interface{}) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	if sheetIndex < 0 || sheetIndex >= len(sb.xlsxFile.Sheets) {
+		return errors.New("sheet index out of range")
+	}
+	if len(headers) == 0 {
+		return errors.New("the report has no headers")
+	}
+	if _, ok := sb.sheetHeatmaps[sheetIndex]; ok {
+		return errors.New("the sheet already starts with a heatmap")
+	}
+	// The metadata takes up two columns, even if the data does not.
+	width := len(headers)
+	if len(meta) > 0 && width < 2 {
+		width = 2
+	}
+	sheet := sb.xlsxFile.Sheets[sheetIndex]
+	if sheet.MaxCol != 0 && sheet.MaxCol != width {
+		return WrongNumberOfRowsError
+	}
+	row := func(cells ...StreamCell) []StreamCell {
+		for len(cells) < width {
+			cells = append(cells, SkipStreamCell())
+		}
+		return cells
+	}
+	report := [][]StreamCell{row(reportTextCell(title, reportTitleStyle))}
+	for _, pair := range meta {
+		report = append(report, row(reportTextCell(pair[0], StreamStyleItalicString), reportTextCell(pair[1], StreamStyleDefaultString)))
+	}
+	report = append(report, row())
+	headerCells := make([]StreamCell, len(headers))
+	for i, header := range headers {
+		headerCells[i] = reportTextCell(header, StreamStyleBoldString)
+	}
+	report = append(report, row(headerCells...))
+	for i, values := range rows {
+		if len(values) != len(headers) {
+			return fmt.Errorf("row %d of the report has %d values, but there are %d headers", i, len(values), len(headers))
+		}
+		cells := make([]StreamCell, len(values))
+		for j, value := range values {
+			cells[j] = valueStreamCell(value)
+		}
+		report = append(report, row(cells...))
+	}
+	if width > 1 {
+		if err := sb.addMerge(sheetIndex, "A1:"+GetCellIDStringFromCoords(width-1, 0)); err != nil {
+			return err
+		}
+	}
+	sb.sheetReports[sheetIndex] = report
+	return nil
+}
+
+// AddTableOfContents adds a sheet named sheetName that lists the other
+// sheets of the workbook, each linking to the top left cell of its
+// sheet.  The sheet is added when the file is built, after all the
+// other sheets, so that sheets added after this call are listed too.
+// It is written like any other sheet, when NextSheet reaches it or the
+// file is closed.
+func (sb *StreamFileBuilder) AddTableOfContents(sheetName string) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	if sb.tableOfContents != "" {
+		return errors.New("the workbook already has a table of contents")
+	}
+	if sheetName == "" {
+		return errors.New("the table of contents needs a sheet name")
+	}
+	sb.tableOfContents = sheetName
+	return nil
+}
+
+// addTableOfContents adds the sheet of the table of contents, with a
+// row linking to each of the sheets before it.
+func (sb *StreamFileBuilder) addTableOfContents() error {
+	sheets := sb.xlsxFile.Sheets
+	if err := sb.AddSheet(sb.tableOfContents, nil); err != nil {
+		return err
+	}
+	rows := make([][]StreamCell, len(sheets))
+	for i, sheet := range sheets {
+		cell := reportTextCell(sheet.Name, tableOfContentsStyle)
+		cell.location = "'" + strings.Replace(sheet.Name, "'", "''", -1) + "'!A1"
+		rows[i] = []StreamCell{cell}
+	}
+	sb.sheetReports[len(sb.xlsxFile.Sheets)-1] = rows
+	return nil
+}
+
+// SetWorkbookView sets the share of the width below the sheets, in
+// thousandths, given to the sheet tabs and the size, in twips, of the
+// window the workbook is opened in, see WorkbookView.
+func (sb *StreamFileBuilder) SetWorkbookView(tabRatio int, windowWidth, windowHeight int) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	if tabRatio < 0 || tabRatio > 1000 {
+		return errors.New("the tab ratio must be between 0 and 1000")
+	}
+	if windowWidth < 0 || windowHeight < 0 {
+		return errors.New("the window size cannot be negative")
+	}
+	sb.xlsxFile.WorkbookView = &WorkbookView{
+		TabRatio:     tabRatio,
+		WindowWidth:  windowWidth,
+		WindowHeight: windowHeight,
+	}
+	return nil
+}
+
+// AddDefinedName defines name, such as a named range, to refer to
+// refersTo in the workbook, see File.AddDefinedName.  A scope of -1
+// defines the name for the whole workbook, and any other scope for the
+// sheet at that index alone.
+func (sb *StreamFileBuilder) AddDefinedName(name, refersTo string, scope int) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	return sb.xlsxFile.AddDefinedName(name, refersTo, scope)
+}
+
+// SetVBAProject embeds the binary VBA project holding the macros of the
+// workbook, see File.SetVBAProject.  The file is then written as a
+// macro enabled workbook and should be given the .xlsm extension.
+func (sb *StreamFileBuilder) SetVBAProject(bin []byte) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	sb.xlsxFile.SetVBAProject(bin)
+	return nil
+}
+
+// SetSelectionRanges selects the ranges in sqref, such as "A1:B4" or
+// "D2", on the sheet at sheetIndex when the file is opened, with the
+// cursor on activeCell.
+func (sb *StreamFileBuilder) SetSelectionRanges(sheetIndex int, activeCell string, sqref []string) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	if sheetIndex < 0 || sheetIndex >= len(sb.xlsxFile.Sheets) {
+		return errors.New("sheet index out of range")
+	}
+	sheet := sb.xlsxFile.Sheets[sheetIndex]
+	if len(sheet.SheetViews) == 0 {
+		sheet.SheetViews = []SheetView{{}}
+	}
+	sheet.SheetViews[0].Selection = &Selection{
+		ActiveCell: activeCell,
+		SQRef:      append([]string(nil), sqref...),
+	}
+	return nil
+}
+
+// SetView sets how the sheet at sheetIndex is shown when the file is
+// opened.  The first freezeRows rows and freezeCols columns are frozen,
+// so that they stay in view, and the rest of the sheet is scrolled so
+// that topLeft, such as "A1000", is the top left cell below and to the
+// right of them.  An empty topLeft leaves the sheet unscrolled.
+func (sb *StreamFileBuilder) SetView(sheetIndex int, freezeRows, freezeCols int, topLeft string) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	if sheetIndex < 0 || sheetIndex >= len(sb.xlsxFile.Sheets) {
+		return errors.New("sheet index out of range")
+	}
+	if freezeRows < 0 || freezeCols < 0 {
+		return errors.New("the number of frozen rows and columns cannot be negative")
+	}
+	if topLeft == "" {
+		topLeft = GetCellIDStringFromCoords(freezeCols, freezeRows)
+	} else {
+		x, y, err := GetCoordsFromCellIDString(topLeft)
+		if err != nil {
+			return err
+		}
+		if x < freezeCols || y < freezeRows {
+			return fmt.Errorf("top left cell %s is within the frozen rows or columns", topLeft)
+		}
+	}
+	sheet := sb.xlsxFile.Sheets[sheetIndex]
+	if len(sheet.SheetViews) == 0 {
+		sheet.SheetViews = []SheetView{{}}
+	}
+	view := &sheet.SheetViews[0]
+	if freezeRows == 0 && freezeCols == 0 {
+		view.Pane = nil
+		view.TopLeftCell = topLeft
+		return nil
+	}
+	activePane := "bottomRight"
+	if freezeCols == 0 {
+		activePane = "bottomLeft"
+	} else if freezeRows == 0 {
+		activePane = "topRight"
+	}
+	view.TopLeftCell = ""
+	view.Pane = &Pane{
+		XSplit:      float64(freezeCols),
+		YSplit:      float64(freezeRows),
+		TopLeftCell: topLeft,
+		ActivePane:  activePane,
+		State:       "frozen",
+	}
+	return nil
+}
+
+// SetFrozenRows freezes the first n rows of the sheet at sheetIndex,
+// such as a header, so that they stay in view as the rest of the sheet
+// is scrolled.  Columns frozen by SetFrozenCols stay frozen.  A count of
+// zero unfreezes the rows.  See SetView for scrolling the sheet as well.
+func (sb *StreamFileBuilder) SetFrozenRows(sheetIndex, n int) error {
+	_, frozenCols := sb.frozenPanes(sheetIndex)
+	return sb.SetView(sheetIndex, n, frozenCols, "")
+}
+
+// SetFrozenCols freezes the first n columns of the sheet at sheetIndex,
+// as SetFrozenRows does for rows.
+func (sb *StreamFileBuilder) SetFrozenCols(sheetIndex, n int) error {
+	frozenRows, _ := sb.frozenPanes(sheetIndex)
+	return sb.SetView(sheetIndex, frozenRows, n, "")
+}
+
+// frozenPanes returns the number of rows and columns frozen in the
+// sheet at sheetIndex, if there is such a sheet.
+func (sb *StreamFileBuilder) frozenPanes(sheetIndex int) (rows, cols int) {
+	if sheetIndex < 0 || sheetIndex >= len(sb.xlsxFile.Sheets) {
+		return 0, 0
+	}
+	views := sb.xlsxFile.Sheets[sheetIndex].SheetViews
+	if len(views) == 0 || views[0].Pane == nil {
+		return 0, 0
+	}
+	return int(views[0].Pane.YSplit), int(views[0].Pane.XSplit)
+}
+
+// SetDefaultNumberFormat sets the number format, such as "#,##0.00", that numbers are shown with by default. It
+// replaces the formats of StreamStyleDefaultInteger and StreamStyleDefaultDecimal in this file, so it applies to cells
+// made by NewIntegerStreamCell and to numbers written with WriteWithColumnDefaultMetadata, as well as to numeric cells
+// that have no style at all. The two styles do not have to be added with AddStreamStyle.
+func (sb *StreamFileBuilder) SetDefaultNumberFormat(code string) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	sb.defaultNumFmtId = sb.AddNewNumberFormat(code)
+	sb.defaultNumberFormat = true
+	sb.customStreamStyles[StreamStyleDefaultInteger] = struct{}{}
+	sb.customStreamStyles[StreamStyleDefaultDecimal] = struct{}{}
+	return nil
+}
+
+// SetBoolsAsText controls how cells of CellTypeBool are written.  When
+// enabled they are written as the text "TRUE" or "FALSE" in the cell's
+// style instead of as native boolean cells, for the benefit of tools
+// that do not understand the latter.
+func (sb *StreamFileBuilder) SetBoolsAsText(enabled bool) error {
+	if sb.built {
+		return BuiltStreamFileBuilderError
+	}
+	sb.boolsAsText = enabled
+	return nil
+}
+
+const (
+	// The approximate compressed size of the parts every file has,
+	// such as the workbook, theme and styles, and of each sheet's
+	// XML before and after its rows.
+	estimatedMetadataSize = 5500
+	estimatedSheetSize    = 500
+	// How much smaller the markup and the text of the cells get when
+	// compressed.  Markup repeats itself and compresses much better.
+	estimatedMarkupCompression = 10
+	estimatedTextCompression   = 2
+)
+
+// EstimateSize returns a rough estimate of the size in bytes of the
+// compressed file that the builder will write if rows rows of cols
+// cells, each holding avgCellLen characters on average, are written to
+// it in total.  It takes the sheets and styles added so far into
+// account, so it is best called just before Build.  The estimate is
+// only meant to help with planning, such as deciding whether to split
+// the data over several files.
+func (sb *StreamFileBuilder) EstimateSize(rows, cols int, avgCellLen int) int64 {
+	if rows < 0 {
+		rows = 0
+	}
+	if cols < 0 {
+		cols = 0
+	}
+	if avgCellLen < 0 {
+		avgCellLen = 0
+	}
+	// <row r="N"></row>
+	rowMarkup := int64(len(`<row r=""></row>`) + len(strconv.Itoa(rows)))
+	// <c r="A1" t="inlineStr"><is><t></t></is></c>
+	cellMarkup := int64(len(`<c r="" t="inlineStr"><is><t></t></is></c>`) +
+		len(GetCellIDStringFromCoords(cols, rows)))
+	if sb.customStylesAdded {
+		cellMarkup += int64(len(` s=""`) + len(strconv.Itoa(len(sb.customStreamStyles))))
+	}
+	cells := int64(rows) * int64(cols)
+	markup := int64(rows)*rowMarkup + cells*cellMarkup
+	text := cells * int64(avgCellLen)
+
+	sheets := int64(len(sb.xlsxFile.Sheets))
+	if sheets == 0 {
+		sheets = 1
+	}
+	return estimatedMetadataSize + sheets*estimatedSheetSize +
+		markup/estimatedMarkupCompression + text/estimatedTextCompression
+}
+
 // Build begins streaming the XLSX file to the io, by writing all the XLSX metadata. It creates a StreamFile struct
 // that can be used to write the rows to the sheets.
 func (sb *StreamFileBuilder) Build() (*StreamFile, error) {
 	if sb.built {
 		return nil, BuiltStreamFileBuilderError
 	}
+	if sb.tableOfContents != "" {
+		if err := sb.addTableOfContents(); err != nil {
+			sb.built = true
+			return nil, err
+		}
+	}
 	sb.built = true
 
+	for _, sheet := range sb.xlsxFile.Sheets {
+		sheet.omitDefaultSheetView = sb.omitDefaultSheetView
+	}
 	parts, err := sb.xlsxFile.MarshallParts()
 	if err != nil {
 		return nil, err
 	}
 
-	if sb.customStylesAdded {
+	if sb.customStylesAdded || sb.defaultNumberFormat {
 		parts["xl/styles.xml"], err = sb.marshalStyles()
 		if err != nil {
 			return nil, err
@@ -274,19 +1089,41 @@ func (sb *StreamFileBuilder) Build() (*StreamFile, error) {
 	}
 
 	es := &StreamFile{
-		zipWriter:              sb.zipWriter,
-		xlsxFile:               sb.xlsxFile,
-		sheetXmlPrefix:         make([]string, len(sb.xlsxFile.Sheets)),
-		sheetXmlSuffix:         make([]string, len(sb.xlsxFile.Sheets)),
-		styleIds:               sb.styleIds,
-		styleIdMap:             sb.styleIdMap,
-		streamingCellMetadatas: sb.streamingCellMetadatas,
-		sheetStreamStyles:      sb.sheetStreamStyles,
-		sheetDefaultCellType:   sb.sheetDefaultCellType,
+		zipWriter:                 sb.zipWriter,
+		xlsxFile:                  sb.xlsxFile,
+		sheetXmlPrefix:            make([]string, len(sb.xlsxFile.Sheets)),
+		sheetXmlSuffix:            make([]string, len(sb.xlsxFile.Sheets)),
+		sheetRels:                 make(map[string]string),
+		styleIds:                  sb.styleIds,
+		styleIdMap:                sb.styleIdMap,
+		streamingCellMetadatas:    sb.streamingCellMetadatas,
+		sheetStreamStyles:         sb.sheetStreamStyles,
+		sheetDefaultCellType:      sb.sheetDefaultCellType,
+		sheetAlternatingRowStyles: sb.sheetAlternatingRowStyles,
+		sheetHeatmaps:             sb.sheetHeatmaps,
+		sheetReports:              sb.sheetReports,
+		sheetMerges:               sb.sheetMerges,
+		boolsAsText:               sb.boolsAsText,
+		controlCharacterMode:      sb.controlCharacterMode,
+		quotePrefix:               sb.quotePrefix,
+		absoluteLinkTargets:       sb.absoluteLinkTargets,
+		alignByType:               sb.alignByType,
+		defaultNumberFormat:       sb.defaultNumberFormat,
 	}
 	for path, data := range parts {
 		// If the part is a sheet, don't write it yet. We only want to write the XLSX metadata files, since at this
 		// point the sheets are still empty. The sheet files will be written later as their rows come in.
+		// The pivot caches are written when the file is closed, once
+		// the names of their fields have been written to the sheets.
+		if strings.HasPrefix(path, pivotCachePathPrefix) {
+			continue
+		}
+		// The relations of the sheets are written with the sheets, as
+		// their hyperlinks add to them.
+		if strings.HasPrefix(path, sheetRelsPathPrefix) {
+			es.sheetRels[path] = data
+			continue
+		}
 		if strings.HasPrefix(path, sheetFilePathPrefix) {
 			// sb.default ColumnStreamingCellMetadataAdded is a hack because neither the `AddSheet` nor `AddSheetS` codepaths
 			// actually encode a valid worksheet dimension. `AddSheet` encodes an empty one: "" and `AddSheetS` encodes
@@ -298,6 +1135,11 @@ func (sb *StreamFileBuilder) Build() (*StreamFile, error) {
 			}
 			continue
 		}
+		// The style sheet is written when the file is closed.
+		if path == stylesFilePath {
+			es.stylesXML = data
+			continue
+		}
 		metadataFile, err := sb.zipWriter.Create(path)
 		if err != nil {
 			return nil, err
@@ -314,6 +1156,13 @@ func (sb *StreamFileBuilder) Build() (*StreamFile, error) {
 	return es, nil
 }
 
+// AddNewNumberFormat adds the number format code formatCode to the
+// style sheet and returns its id, for use with MakeStyle.  Built in
+// codes return their built in id.  Adding a code that has already been
+// added, whether by AddNewNumberFormat, SetDefaultNumberFormat or by
+// cells such as those of NewMoneyStreamCell, returns the id it was
+// given then, so the ids of custom formats run on from 164 without
+// gaps or duplicates.
 func (sb *StreamFileBuilder) AddNewNumberFormat(formatCode string) int {
 	if sb.xlsxFile.styles == nil {
 		sb.xlsxFile.styles = newXlsxStyleSheet(sb.xlsxFile.theme)
@@ -324,13 +1173,26 @@ func (sb *StreamFileBuilder) AddNewNumberFormat(formatCode string) int {
 }
 
 func (sb *StreamFileBuilder) marshalStyles() (string, error) {
+	// The number formats were dropped along with the rest of the style
+	// sheet when the file was marshalled.  All the added ones are put
+	// back in the order of their ids, used or not, so that the ids stay
+	// as they were handed out, without gaps.
+	numFmtIds := make([]int, 0, len(sb.customNumFormats))
+	for numFmtId := range sb.customNumFormats {
+		numFmtIds = append(numFmtIds, numFmtId)
+	}
+	sort.Ints(numFmtIds)
+	for _, numFmtId := range numFmtIds {
+		sb.customNumFmtId(numFmtId)
+	}
 
 	for streamStyle := range sb.customStreamStyles {
-		XfId := handleStyleForXLSX(streamStyle.style, streamStyle.xNumFmtId, sb.xlsxFile.styles)
-		sb.styleIdMap[streamStyle] = XfId
-		if xNumFmt, ok := sb.customNumFormats[streamStyle.xNumFmtId]; ok {
-			sb.xlsxFile.styles.addNumFmt(xNumFmt)
+		numFmtId := streamStyle.xNumFmtId
+		if sb.defaultNumberFormat && (streamStyle == StreamStyleDefaultInteger || streamStyle == StreamStyleDefaultDecimal) {
+			numFmtId = sb.defaultNumFmtId
 		}
+		XfId := handleStyleForXLSX(streamStyle.style, sb.customNumFmtId(numFmtId), sb.xlsxFile.styles)
+		sb.styleIdMap[streamStyle] = XfId
 	}
 
 	styleSheetXMLString, err := sb.xlsxFile.styles.Marshal()
@@ -340,6 +1202,29 @@ func (sb *StreamFileBuilder) marshalStyles() (string, error) {
 	return styleSheetXMLString, nil
 }
 
+// customNumFmtId makes sure a number format added with
+// AddNewNumberFormat is present in the style sheet, and returns the id
+// it has there.  The style sheet is rebuilt when the File is
+// marshalled, and column styles may claim ids for their own formats
+// in the process, so the id AddNewNumberFormat returned might already
+// be in use by a different format.
+func (sb *StreamFileBuilder) customNumFmtId(numFmtId int) int {
+	xNumFmt, ok := sb.customNumFormats[numFmtId]
+	if !ok {
+		return numFmtId
+	}
+	styles := sb.xlsxFile.styles
+	existing, ok := styles.numFmtRefTable[numFmtId]
+	switch {
+	case !ok:
+		styles.addNumFmt(xNumFmt)
+		return numFmtId
+	case existing.FormatCode == xNumFmt.FormatCode:
+		return numFmtId
+	}
+	return styles.newNumFmt(xNumFmt.FormatCode).NumFmtId
+}
+
 // AddStreamStyle adds a new style to the style sheet.
 // Only Styles that have been added through this function will be usable.
 // This function cannot be used after AddSheetS or Build has been called, and if it is
@@ -1,6 +1,9 @@
 package xlsx
 
 import (
+	"bytes"
+	"strconv"
+	"strings"
 	"testing"
 
 	qt "github.com/frankban/quicktest"
@@ -12,3 +15,580 @@ func TestRemoveDimensionTag(t *testing.T) {
 	c.Assert("<foo></foo>", qt.Equals, out)
 
 }
+
+func TestEstimateSize(t *testing.T) {
+	c := qt.New(t)
+
+	newBuilder := func(c *qt.C) (*StreamFileBuilder, *bytes.Buffer) {
+		buffer := bytes.NewBuffer(nil)
+		fileBuilder := NewStreamFileBuilder(buffer)
+		c.Assert(fileBuilder.AddStreamStyle(StreamStyleDefaultString), qt.IsNil)
+		c.Assert(fileBuilder.AddSheetS("Sheet1", []StreamStyle{StreamStyleDefaultString, StreamStyleDefaultString, StreamStyleDefaultString}), qt.IsNil)
+		return fileBuilder, buffer
+	}
+
+	c.Run("GrowsWithInputs", func(c *qt.C) {
+		fileBuilder, _ := newBuilder(c)
+		base := fileBuilder.EstimateSize(100, 3, 10)
+		c.Assert(base > 0, qt.Equals, true)
+		c.Assert(fileBuilder.EstimateSize(1000, 3, 10) > base, qt.Equals, true)
+		c.Assert(fileBuilder.EstimateSize(100, 30, 10) > base, qt.Equals, true)
+		c.Assert(fileBuilder.EstimateSize(100, 3, 100) > base, qt.Equals, true)
+		c.Assert(fileBuilder.EstimateSize(-1, 3, 10), qt.Equals, fileBuilder.EstimateSize(0, 3, 10))
+	})
+
+	c.Run("CloseToActualSize", func(c *qt.C) {
+		fileBuilder, buffer := newBuilder(c)
+		const rows = 2000
+		estimate := fileBuilder.EstimateSize(rows, 3, 8)
+		streamFile, err := fileBuilder.Build()
+		c.Assert(err, qt.IsNil)
+		for i := 0; i < rows; i++ {
+			var cells []StreamCell
+			for j := 0; j < 3; j++ {
+				cells = append(cells, NewStringStreamCell(strconv.Itoa(10000000+(i*7919+j*104729)%89999999)))
+			}
+			c.Assert(streamFile.WriteS(cells), qt.IsNil)
+		}
+		c.Assert(streamFile.Close(), qt.IsNil)
+
+		actual := int64(buffer.Len())
+		c.Assert(estimate > actual/2, qt.Equals, true, qt.Commentf("estimate %d, actual %d", estimate, actual))
+		c.Assert(estimate < actual*2, qt.Equals, true, qt.Commentf("estimate %d, actual %d", estimate, actual))
+	})
+}
+
+func TestSetSelectionRanges(t *testing.T) {
+	c := qt.New(t)
+
+	parts, file := streamRows(c, func(fileBuilder *StreamFileBuilder) {
+		c.Assert(fileBuilder.SetSelectionRanges(1, "A1", []string{"A1"}), qt.ErrorMatches, "sheet index out of range")
+		c.Assert(fileBuilder.SetSelectionRanges(0, "D5", []string{"A1:B4", "D2:E6", "G7"}), qt.IsNil)
+	}, []StreamCell{NewStringStreamCell("a")})
+	c.Assert(parts["xl/worksheets/sheet1.xml"], qt.Contains,
+		`<selection pane="topLeft" activeCell="D5" activeCellId="1" sqref="A1:B4 D2:E6 G7"></selection>`)
+
+	sheetViews := file.Sheets[0].SheetViews
+	c.Assert(sheetViews, qt.HasLen, 1)
+	c.Assert(sheetViews[0].Selection, qt.DeepEquals, &Selection{
+		ActiveCell: "D5",
+		SQRef:      []string{"A1:B4", "D2:E6", "G7"},
+	})
+}
+
+func TestStreamAddDataValidation(t *testing.T) {
+	c := qt.New(t)
+
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddStreamStyle(StreamStyleDefaultString), qt.IsNil)
+	c.Assert(fileBuilder.AddSheetS("Sheet1", []StreamStyle{StreamStyleDefaultString, StreamStyleDefaultString}), qt.IsNil)
+	dv := NewDataValidation(0, 0, 0, 0, true)
+	c.Assert(dv.SetDropList([]string{"Yes", "No", "Maybe"}), qt.IsNil)
+	c.Assert(fileBuilder.AddDataValidation(1, "B2:B100", dv), qt.ErrorMatches, "sheet index out of range")
+	c.Assert(fileBuilder.AddDataValidation(0, "B2:B", dv), qt.ErrorMatches, `invalid data validation range "B2:B"`)
+	c.Assert(fileBuilder.AddDataValidation(0, "A1:B2:C3", dv), qt.ErrorMatches, `invalid data validation range "A1:B2:C3"`)
+	c.Assert(fileBuilder.AddDataValidation(0, " ", dv), qt.ErrorMatches, "the data validation has no cells")
+	c.Assert(fileBuilder.AddDataValidation(0, "B2:B100", nil), qt.ErrorMatches, "the data validation is nil")
+	c.Assert(fileBuilder.AddDataValidation(0, "B2:B100 D4", dv), qt.IsNil)
+	// The validation is copied, so the one passed in keeps its cells.
+	c.Assert(dv.Sqref, qt.Equals, "A1")
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	c.Assert(fileBuilder.AddDataValidation(0, "C1", dv), qt.Equals, BuiltStreamFileBuilderError)
+	c.Assert(streamFile.WriteS([]StreamCell{NewStringStreamCell("Name"), NewStringStreamCell("Answer")}), qt.IsNil)
+	c.Assert(streamFile.WriteS([]StreamCell{NewStringStreamCell("Alice"), NewStringStreamCell("Yes")}), qt.IsNil)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	sheetXML := unzipParts(c, buffer.Bytes())["xl/worksheets/sheet1.xml"]
+	c.Assert(sheetXML, qt.Contains, `<dataValidations count="1">`)
+	c.Assert(sheetXML, qt.Contains, `sqref="B2:B100 D4"`)
+	c.Assert(sheetXML, qt.Contains, `<formula1>&#34;Yes,No,Maybe&#34;</formula1>`)
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	validations := file.Sheets[0].DataValidations
+	c.Assert(validations, qt.HasLen, 1)
+	c.Assert(validations[0].Type, qt.Equals, "list")
+	c.Assert(validations[0].Sqref, qt.Equals, "B2:B100 D4")
+	c.Assert(validations[0].Formula1, qt.Equals, `"Yes,No,Maybe"`)
+	c.Assert(validations[0].AllowBlank, qt.Equals, true)
+	c.Assert(file.Sheets[0].Cell(1, 1).Value, qt.Equals, "Yes")
+}
+
+func TestStreamAddDefinedName(t *testing.T) {
+	c := qt.New(t)
+
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddSheet("Data", []*CellType{CellTypeString.Ptr(), CellTypeNumeric.Ptr()}), qt.IsNil)
+	c.Assert(fileBuilder.AddDefinedName("Amounts", "Data!$B$2:$B$3", -1), qt.IsNil)
+	c.Assert(fileBuilder.AddDefinedName("Header", "Data!$A$1:$B$1", 0), qt.IsNil)
+	c.Assert(fileBuilder.AddDefinedName("B2", "Data!$B$2", -1), qt.ErrorMatches, `the defined name "B2" looks like a cell reference`)
+	c.Assert(fileBuilder.AddDefinedName("Header", "Data!$A$1", 1), qt.ErrorMatches, "sheet index out of range")
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	c.Assert(fileBuilder.AddDefinedName("Late", "Data!$A$1", -1), qt.Equals, BuiltStreamFileBuilderError)
+	c.Assert(streamFile.Write([]string{"Name", "Amount"}), qt.IsNil)
+	c.Assert(streamFile.Write([]string{"Ann", "12"}), qt.IsNil)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	c.Assert(unzipParts(c, buffer.Bytes())["xl/workbook.xml"], qt.Contains,
+		`<definedNames><definedName name="Amounts">Data!$B$2:$B$3</definedName><definedName name="Header" localSheetId="0">Data!$A$1:$B$1</definedName></definedNames>`)
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	c.Assert(file.DefinedNames, qt.HasLen, 2)
+	c.Assert(file.DefinedNames[0].Name, qt.Equals, "Amounts")
+	c.Assert(file.DefinedNames[0].LocalSheetID, qt.IsNil)
+	c.Assert(file.DefinedNames[1].Name, qt.Equals, "Header")
+	c.Assert(*file.DefinedNames[1].LocalSheetID, qt.Equals, 0)
+}
+
+func TestStreamSetAutoFilter(t *testing.T) {
+	c := qt.New(t)
+
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	cellTypes := []*CellType{CellTypeString.Ptr(), CellTypeString.Ptr(), CellTypeNumeric.Ptr(), CellTypeNumeric.Ptr()}
+	c.Assert(fileBuilder.AddSheet("Report", cellTypes), qt.IsNil)
+	c.Assert(fileBuilder.SetAutoFilter(1, "A1:D1"), qt.ErrorMatches, "sheet index out of range")
+	c.Assert(fileBuilder.SetAutoFilter(0, "A1"), qt.ErrorMatches, `invalid auto filter range "A1"`)
+	c.Assert(fileBuilder.SetAutoFilter(0, "D1:A1"), qt.ErrorMatches, `invalid auto filter range "D1:A1"`)
+	c.Assert(fileBuilder.SetAutoFilter(0, "A1:E1"), qt.ErrorMatches, `the auto filter range "A1:E1" lies beyond the columns of the sheet`)
+	c.Assert(fileBuilder.SetAutoFilter(0, "A1:D1"), qt.IsNil)
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	c.Assert(fileBuilder.SetAutoFilter(0, "A1:D1"), qt.Equals, BuiltStreamFileBuilderError)
+	c.Assert(streamFile.Write([]string{"Name", "Region", "Q1", "Q2"}), qt.IsNil)
+	c.Assert(streamFile.Write([]string{"Ann", "North", "1", "2"}), qt.IsNil)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	parts := unzipParts(c, buffer.Bytes())
+	c.Assert(parts["xl/worksheets/sheet1.xml"], qt.Contains, `<autoFilter ref="A1:D1"></autoFilter>`)
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	c.Assert(file.Sheets[0].AutoFilter, qt.DeepEquals, &AutoFilter{TopLeftCell: "A1", BottomRightCell: "D1"})
+
+	// A filter on a single cell may be written without a range.
+	parts["xl/worksheets/sheet1.xml"] = strings.Replace(parts["xl/worksheets/sheet1.xml"], `<autoFilter ref="A1:D1">`, `<autoFilter ref="B1">`, 1)
+	file, err = OpenBinary(zipParts(c, parts))
+	c.Assert(err, qt.IsNil)
+	c.Assert(file.Sheets[0].AutoFilter, qt.DeepEquals, &AutoFilter{TopLeftCell: "B1", BottomRightCell: "B1"})
+}
+
+func TestStreamSetColOutlineLevel(t *testing.T) {
+	c := qt.New(t)
+
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	cellTypes := []*CellType{CellTypeString.Ptr(), CellTypeNumeric.Ptr(), CellTypeNumeric.Ptr(), CellTypeNumeric.Ptr()}
+	c.Assert(fileBuilder.AddSheet("Report", cellTypes), qt.IsNil)
+	c.Assert(fileBuilder.SetColOutlineLevel(1, 2, 3, 1), qt.ErrorMatches, "sheet index out of range")
+	c.Assert(fileBuilder.SetColOutlineLevel(0, 3, 2, 1), qt.ErrorMatches, "invalid column range 3 to 2")
+	c.Assert(fileBuilder.SetColOutlineLevel(0, 2, 3, 8), qt.ErrorMatches, "invalid outline level 8, it must be from 0 to 7")
+	c.Assert(fileBuilder.SetColOutlineLevel(0, 2, 3, 1), qt.IsNil)
+	c.Assert(fileBuilder.SetColOutlineLevel(0, 3, 3, 2), qt.IsNil)
+	c.Assert(fileBuilder.SetColCollapsed(0, 3, 3, true), qt.IsNil)
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	c.Assert(fileBuilder.SetColOutlineLevel(0, 2, 3, 1), qt.Equals, BuiltStreamFileBuilderError)
+	c.Assert(fileBuilder.SetColCollapsed(0, 3, 3, false), qt.Equals, BuiltStreamFileBuilderError)
+	c.Assert(streamFile.Write([]string{"Region", "1", "2", "3"}), qt.IsNil)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	sheetXML := unzipParts(c, buffer.Bytes())["xl/worksheets/sheet1.xml"]
+	c.Assert(sheetXML, qt.Contains, `outlineLevelCol="2"`)
+	c.Assert(sheetXML, qt.Contains, `<col collapsed="true" hidden="true" max="3" min="3"`)
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	sheet := file.Sheets[0]
+	c.Assert(sheet.SheetFormat.OutlineLevelCol, qt.Equals, uint8(2))
+	c.Assert(sheet.Col(0).OutlineLevel, qt.Equals, uint8(0))
+	c.Assert(sheet.Col(1).OutlineLevel, qt.Equals, uint8(1))
+	c.Assert(sheet.Col(1).Collapsed, qt.Equals, false)
+	c.Assert(sheet.Col(2).OutlineLevel, qt.Equals, uint8(2))
+	c.Assert(sheet.Col(2).Collapsed, qt.Equals, true)
+	c.Assert(sheet.Col(2).Hidden, qt.Equals, true)
+}
+
+func TestStreamOmitDefaultSheetView(t *testing.T) {
+	c := qt.New(t)
+
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddSheet("Plain", []*CellType{CellTypeString.Ptr()}), qt.IsNil)
+	c.Assert(fileBuilder.AddSheet("Frozen", []*CellType{CellTypeString.Ptr()}), qt.IsNil)
+	c.Assert(fileBuilder.SetOmitDefaultSheetView(true), qt.IsNil)
+	c.Assert(fileBuilder.SetView(1, 1, 0, ""), qt.IsNil)
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	c.Assert(fileBuilder.SetOmitDefaultSheetView(false), qt.Equals, BuiltStreamFileBuilderError)
+	c.Assert(streamFile.Write([]string{"a"}), qt.IsNil)
+	c.Assert(streamFile.NextSheet(), qt.IsNil)
+	c.Assert(streamFile.Write([]string{"b"}), qt.IsNil)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	parts := unzipParts(c, buffer.Bytes())
+	c.Assert(strings.Contains(parts["xl/worksheets/sheet1.xml"], "sheetView"), qt.Equals, false)
+	c.Assert(parts["xl/worksheets/sheet2.xml"], qt.Contains, "<sheetViews>")
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	c.Assert(file.Sheets[0].SheetViews, qt.IsNil)
+	c.Assert(file.Sheets[0].Cell(0, 0).Value, qt.Equals, "a")
+	c.Assert(file.Sheets[1].SheetViews, qt.HasLen, 1)
+}
+
+func TestAddTableOfContents(t *testing.T) {
+	c := qt.New(t)
+
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddSheet("Summary", []*CellType{CellTypeString.Ptr()}), qt.IsNil)
+	c.Assert(fileBuilder.AddTableOfContents(""), qt.ErrorMatches, "the table of contents needs a sheet name")
+	c.Assert(fileBuilder.AddTableOfContents("Contents"), qt.IsNil)
+	c.Assert(fileBuilder.AddTableOfContents("Index"), qt.ErrorMatches, "the workbook already has a table of contents")
+	// Sheets added after the table of contents are listed too.
+	c.Assert(fileBuilder.AddSheet("Q1 & Q2", []*CellType{CellTypeString.Ptr()}), qt.IsNil)
+	c.Assert(fileBuilder.AddSheet("Ann's", []*CellType{CellTypeString.Ptr()}), qt.IsNil)
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	c.Assert(fileBuilder.AddTableOfContents("Index"), qt.Equals, BuiltStreamFileBuilderError)
+	c.Assert(streamFile.Write([]string{"total"}), qt.IsNil)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	parts := unzipParts(c, buffer.Bytes())
+	c.Assert(parts["xl/worksheets/sheet4.xml"], qt.Contains, `<hyperlinks><hyperlink ref="A1" location="&#39;Summary&#39;!A1"></hyperlink><hyperlink ref="A2" location="&#39;Q1 &amp; Q2&#39;!A1"></hyperlink><hyperlink ref="A3" location="&#39;Ann&#39;&#39;s&#39;!A1"></hyperlink></hyperlinks>`)
+	_, ok := parts["xl/worksheets/_rels/sheet4.xml.rels"]
+	c.Assert(ok, qt.Equals, false)
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	c.Assert(file.Sheets, qt.HasLen, 4)
+	toc := file.Sheets[3]
+	c.Assert(toc.Name, qt.Equals, "Contents")
+	c.Assert(toc.MaxRow, qt.Equals, 3)
+	for i, test := range []struct {
+		name, location string
+	}{
+		{"Summary", "'Summary'!A1"},
+		{"Q1 & Q2", "'Q1 & Q2'!A1"},
+		{"Ann's", "'Ann''s'!A1"},
+	} {
+		cell := toc.Cell(i, 0)
+		c.Assert(cell.Value, qt.Equals, test.name)
+		c.Assert(cell.Hyperlink, qt.DeepEquals, Hyperlink{Location: test.location})
+		c.Assert(cell.GetStyle().Font.Underline, qt.Equals, true)
+	}
+	c.Assert(file.Sheets[0].Cell(0, 0).Value, qt.Equals, "total")
+
+	// Links within the workbook are kept when the file is saved.
+	var saved bytes.Buffer
+	c.Assert(file.Write(&saved), qt.IsNil)
+	file, err = OpenBinary(saved.Bytes())
+	c.Assert(err, qt.IsNil)
+	c.Assert(file.Sheets[3].Cell(1, 0).Hyperlink, qt.DeepEquals, Hyperlink{Location: "'Q1 & Q2'!A1"})
+}
+
+func TestSetView(t *testing.T) {
+	c := qt.New(t)
+
+	roundTrip := func(c *qt.C, setView func(*StreamFileBuilder)) SheetView {
+		_, file := streamRows(c, setView, []StreamCell{NewStringStreamCell("a")})
+		c.Assert(file.Sheets[0].SheetViews, qt.HasLen, 1)
+		return file.Sheets[0].SheetViews[0]
+	}
+
+	c.Run("FrozenAndScrolled", func(c *qt.C) {
+		view := roundTrip(c, func(fileBuilder *StreamFileBuilder) {
+			c.Assert(fileBuilder.SetView(0, 1, 2, "C1000"), qt.IsNil)
+		})
+		c.Assert(view.Pane, qt.DeepEquals, &Pane{
+			XSplit:      2,
+			YSplit:      1,
+			TopLeftCell: "C1000",
+			ActivePane:  "bottomRight",
+			State:       "frozen",
+		})
+		c.Assert(view.TopLeftCell, qt.Equals, "A1")
+	})
+
+	c.Run("FrozenRowsOnly", func(c *qt.C) {
+		view := roundTrip(c, func(fileBuilder *StreamFileBuilder) {
+			c.Assert(fileBuilder.SetView(0, 1, 0, ""), qt.IsNil)
+		})
+		c.Assert(view.Pane.TopLeftCell, qt.Equals, "A2")
+		c.Assert(view.Pane.ActivePane, qt.Equals, "bottomLeft")
+	})
+
+	c.Run("ScrolledOnly", func(c *qt.C) {
+		view := roundTrip(c, func(fileBuilder *StreamFileBuilder) {
+			c.Assert(fileBuilder.SetView(0, 0, 0, "B50"), qt.IsNil)
+		})
+		c.Assert(view.Pane, qt.IsNil)
+		c.Assert(view.TopLeftCell, qt.Equals, "B50")
+	})
+
+	c.Run("FrozenRowsAndCols", func(c *qt.C) {
+		view := roundTrip(c, func(fileBuilder *StreamFileBuilder) {
+			c.Assert(fileBuilder.SetFrozenRows(0, 1), qt.IsNil)
+			c.Assert(fileBuilder.SetFrozenCols(0, 1), qt.IsNil)
+		})
+		c.Assert(view.Pane, qt.DeepEquals, &Pane{
+			XSplit:      1,
+			YSplit:      1,
+			TopLeftCell: "B2",
+			ActivePane:  "bottomRight",
+			State:       "frozen",
+		})
+	})
+
+	c.Run("FrozenColsThenUnfrozenRows", func(c *qt.C) {
+		view := roundTrip(c, func(fileBuilder *StreamFileBuilder) {
+			c.Assert(fileBuilder.SetFrozenCols(0, 3), qt.IsNil)
+			c.Assert(fileBuilder.SetFrozenRows(0, 2), qt.IsNil)
+			c.Assert(fileBuilder.SetFrozenRows(0, 0), qt.IsNil)
+		})
+		c.Assert(view.Pane.TopLeftCell, qt.Equals, "D1")
+		c.Assert(view.Pane.ActivePane, qt.Equals, "topRight")
+	})
+
+	c.Run("Errors", func(c *qt.C) {
+		fileBuilder := NewStreamFileBuilder(bytes.NewBuffer(nil))
+		c.Assert(fileBuilder.AddSheetS("Sheet1", nil), qt.IsNil)
+		c.Assert(fileBuilder.SetView(1, 1, 0, ""), qt.ErrorMatches, "sheet index out of range")
+		c.Assert(fileBuilder.SetFrozenRows(1, 1), qt.ErrorMatches, "sheet index out of range")
+		c.Assert(fileBuilder.SetFrozenCols(0, -1), qt.ErrorMatches, "the number of frozen rows and columns cannot be negative")
+		c.Assert(fileBuilder.SetView(0, -1, 0, ""), qt.ErrorMatches, "the number of frozen rows and columns cannot be negative")
+		c.Assert(fileBuilder.SetView(0, 2, 0, "A2"), qt.ErrorMatches, "top left cell A2 is within the frozen rows or columns")
+	})
+}
+
+func TestStreamSetVBAProject(t *testing.T) {
+	c := qt.New(t)
+
+	macros := []byte{0xd0, 0xcf, 0x11, 0xe0, 0xa1, 0xb1, 0x1a, 0xe1}
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddSheet("Sheet1", []*CellType{CellTypeString.Ptr()}), qt.IsNil)
+	c.Assert(fileBuilder.SetVBAProject(macros), qt.IsNil)
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	c.Assert(fileBuilder.SetVBAProject(nil), qt.Equals, BuiltStreamFileBuilderError)
+	c.Assert(streamFile.Write([]string{"1"}), qt.IsNil)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	parts := unzipParts(c, buffer.Bytes())
+	c.Assert(parts["xl/vbaProject.bin"], qt.Equals, string(macros))
+	c.Assert(parts["[Content_Types].xml"], qt.Contains, `<Override PartName="/xl/workbook.xml" ContentType="application/vnd.ms-excel.sheet.macroEnabled.main+xml"></Override>`)
+	c.Assert(parts["[Content_Types].xml"], qt.Contains, `<Default Extension="bin" ContentType="application/vnd.ms-office.vbaProject"></Default>`)
+	c.Assert(parts["xl/_rels/workbook.xml.rels"], qt.Contains, `Target="vbaProject.bin"`)
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	c.Assert(file.VBAProject(), qt.DeepEquals, macros)
+}
+
+func TestWriteHeatmap(t *testing.T) {
+	c := qt.New(t)
+
+	data := [][]float64{
+		{1, 2.5, 3},
+		{-4, 5, 600},
+	}
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddSheet("Heat", nil), qt.IsNil)
+	c.Assert(fileBuilder.WriteHeatmap(0, [][]float64{{1, 2}, {3}}, "FFF8696B", "FF63BE7B"), qt.ErrorMatches, "rows of the heatmap must have the same number of values")
+	c.Assert(fileBuilder.WriteHeatmap(1, data, "FFF8696B", "FF63BE7B"), qt.ErrorMatches, "sheet index out of range")
+	c.Assert(fileBuilder.WriteHeatmap(0, data, "FFF8696B", "FF63BE7B"), qt.IsNil)
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	c.Assert(fileBuilder.WriteHeatmap(0, data, "FFF8696B", "FF63BE7B"), qt.Equals, BuiltStreamFileBuilderError)
+	c.Assert(streamFile.Write([]string{"7", "8", "9"}), qt.IsNil)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	sheet := file.Sheets[0]
+	c.Assert(sheet.ColorScales, qt.DeepEquals, []ColorScale{{Ref: "A1:C2", MinColor: "FFF8696B", MaxColor: "FF63BE7B"}})
+	c.Assert(sheet.MaxRow, qt.Equals, 3)
+	for y, row := range data {
+		for x, v := range row {
+			value, err := sheet.Cell(y, x).Float()
+			c.Assert(err, qt.IsNil)
+			c.Assert(value, qt.Equals, v)
+		}
+	}
+	c.Assert(sheet.Cell(2, 0).Value, qt.Equals, "7")
+}
+
+func TestWriteHeatmapElementOrder(t *testing.T) {
+	c := qt.New(t)
+
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddSheet("Heat", []*CellType{CellTypeNumeric.Ptr(), CellTypeNumeric.Ptr()}), qt.IsNil)
+	c.Assert(fileBuilder.AddStreamStyle(StreamStyleDefaultString), qt.IsNil)
+	c.Assert(fileBuilder.WriteHeatmap(0, [][]float64{{1, 2}, {3, 4}}, "FFF8696B", "FF63BE7B"), qt.IsNil)
+	c.Assert(fileBuilder.RegisterMerge(0, "A3:B3"), qt.IsNil)
+	validation := NewDataValidation(0, 0, 0, 0, true)
+	c.Assert(validation.SetDropList([]string{"a", "b"}), qt.IsNil)
+	c.Assert(fileBuilder.AddDataValidation(0, "A4:B4", validation), qt.IsNil)
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	c.Assert(streamFile.WriteS([]StreamCell{
+		NewStyledHyperlinkStreamCell("link", "https://example.com", StreamStyleDefaultString),
+		NewStringStreamCell(""),
+	}), qt.IsNil)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	// The elements after the rows are in the order of the schema.
+	sheetXML := unzipParts(c, buffer.Bytes())["xl/worksheets/sheet1.xml"]
+	last := -1
+	for _, tag := range []string{"</sheetData>", "<mergeCells", "<conditionalFormatting", "<dataValidations", "<hyperlinks", "<printOptions"} {
+		i := strings.Index(sheetXML, tag)
+		c.Assert(i > last, qt.Equals, true, qt.Commentf("%s in %s", tag, sheetXML))
+		last = i
+	}
+
+	// Sheets written as a whole have them in the same order.
+	file := NewFile()
+	sheet, err := file.AddSheet("Heat")
+	c.Assert(err, qt.IsNil)
+	sheet.Cell(0, 0).SetFloat(1)
+	sheet.Cell(0, 1).SetHyperlink("https://example.com", "link", "")
+	sheet.ColorScales = []ColorScale{{Ref: "A1:A1", MinColor: "FFF8696B", MaxColor: "FF63BE7B"}}
+	sheet.AddDataValidation(NewDataValidation(1, 0, 1, 0, true))
+	buffer.Reset()
+	c.Assert(file.Write(buffer), qt.IsNil)
+	sheetXML = unzipParts(c, buffer.Bytes())["xl/worksheets/sheet1.xml"]
+	last = -1
+	for _, tag := range []string{"</sheetData>", "<conditionalFormatting", "<dataValidations", "<hyperlinks", "<printOptions"} {
+		i := strings.Index(sheetXML, tag)
+		c.Assert(i > last, qt.Equals, true, qt.Commentf("%s in %s", tag, sheetXML))
+		last = i
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	c := qt.New(t)
+
+	meta := [][2]string{{"Author", "Ann"}, {"Period", "March 2020"}}
+	headers := []string{"Item", "Count", "Price"}
+	rows := [][]interface{}{
+		{"apple", 3, 1.25},
+		{"pear", nil, true},
+	}
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddSheet("Report", nil), qt.IsNil)
+	c.Assert(fileBuilder.AddSheet("Heat", nil), qt.IsNil)
+	c.Assert(fileBuilder.WriteReport(2, "Sales", meta, headers, rows), qt.ErrorMatches, "sheet index out of range")
+	c.Assert(fileBuilder.WriteReport(0, "Sales", meta, nil, nil), qt.ErrorMatches, "the report has no headers")
+	c.Assert(fileBuilder.WriteReport(0, "Sales", meta, headers, [][]interface{}{{"plum"}}), qt.ErrorMatches, "row 0 of the report has 1 values, but there are 3 headers")
+	c.Assert(fileBuilder.WriteHeatmap(1, [][]float64{{1, 2, 3}}, "FFF8696B", "FF63BE7B"), qt.IsNil)
+	c.Assert(fileBuilder.WriteReport(1, "Sales", meta, headers, rows), qt.ErrorMatches, "the sheet already starts with a heatmap")
+	c.Assert(fileBuilder.WriteReport(0, "Sales", meta, headers, rows), qt.IsNil)
+	c.Assert(fileBuilder.WriteHeatmap(0, [][]float64{{1, 2, 3}}, "FFF8696B", "FF63BE7B"), qt.ErrorMatches, "the sheet already starts with a report")
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	c.Assert(fileBuilder.WriteReport(0, "Sales", meta, headers, rows), qt.Equals, BuiltStreamFileBuilderError)
+	c.Assert(streamFile.Write([]string{"total", "3", "1.25"}), qt.IsNil)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	sheetXML := unzipParts(c, buffer.Bytes())["xl/worksheets/sheet1.xml"]
+	c.Assert(sheetXML, qt.Contains, `<mergeCells count="1"><mergeCell ref="A1:C1"/></mergeCells>`)
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	sheet := file.Sheets[0]
+	c.Assert(sheet.MaxRow, qt.Equals, 8)
+	title := sheet.Cell(0, 0)
+	c.Assert(title.Value, qt.Equals, "Sales")
+	c.Assert(title.HMerge, qt.Equals, 2)
+	c.Assert(title.GetStyle().Font.Bold, qt.Equals, true)
+	c.Assert(title.GetStyle().Font.Size, qt.Equals, 14)
+	for i, pair := range meta {
+		c.Assert(sheet.Cell(1+i, 0).Value, qt.Equals, pair[0])
+		c.Assert(sheet.Cell(1+i, 0).GetStyle().Font.Italic, qt.Equals, true)
+		c.Assert(sheet.Cell(1+i, 1).Value, qt.Equals, pair[1])
+		c.Assert(sheet.Cell(1+i, 1).GetStyle().Font.Italic, qt.Equals, false)
+	}
+	c.Assert(sheet.Cell(3, 0).IsEmpty(), qt.Equals, true)
+	for i, header := range headers {
+		c.Assert(sheet.Cell(4, i).Value, qt.Equals, header)
+		c.Assert(sheet.Cell(4, i).GetStyle().Font.Bold, qt.Equals, true)
+	}
+	c.Assert(sheet.Cell(5, 0).Type(), qt.Equals, CellTypeInline)
+	c.Assert(sheet.Cell(5, 0).Value, qt.Equals, "apple")
+	c.Assert(sheet.Cell(5, 0).GetStyle().Font.Bold, qt.Equals, false)
+	count, err := sheet.Cell(5, 1).Int()
+	c.Assert(err, qt.IsNil)
+	c.Assert(count, qt.Equals, 3)
+	c.Assert(sheet.Cell(5, 2).Type(), qt.Equals, CellTypeNumeric)
+	c.Assert(sheet.Cell(5, 2).Value, qt.Equals, "1.25")
+	c.Assert(sheet.Cell(6, 1).IsEmpty(), qt.Equals, true)
+	c.Assert(sheet.Cell(6, 2).Type(), qt.Equals, CellTypeBool)
+	c.Assert(sheet.Cell(6, 2).Bool(), qt.Equals, true)
+	c.Assert(sheet.Cell(7, 0).Value, qt.Equals, "total")
+}
+
+func TestSetWorkbookView(t *testing.T) {
+	c := qt.New(t)
+
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddSheet("Sheet1", []*CellType{CellTypeString.Ptr()}), qt.IsNil)
+	c.Assert(fileBuilder.SetWorkbookView(1001, 0, 0), qt.ErrorMatches, "the tab ratio must be between 0 and 1000")
+	c.Assert(fileBuilder.SetWorkbookView(600, 28800, 17000), qt.IsNil)
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	c.Assert(fileBuilder.SetWorkbookView(600, 0, 0), qt.Equals, BuiltStreamFileBuilderError)
+	c.Assert(streamFile.Write([]string{"1"}), qt.IsNil)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	workbookXML := unzipParts(c, buffer.Bytes())["xl/workbook.xml"]
+	c.Assert(workbookXML, qt.Contains, `tabRatio="600" windowHeight="17000" windowWidth="28800"`)
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	c.Assert(file.WorkbookView, qt.DeepEquals, &WorkbookView{TabRatio: 600, WindowWidth: 28800, WindowHeight: 17000})
+}
+
+func TestAddPivotCacheSource(t *testing.T) {
+	c := qt.New(t)
+
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddSheet("Sales", []*CellType{CellTypeString.Ptr(), CellTypeString.Ptr(), CellTypeString.Ptr()}), qt.IsNil)
+	c.Assert(fileBuilder.AddPivotCacheSource(1, "A1:C4"), qt.ErrorMatches, "sheet index out of range")
+	c.Assert(fileBuilder.AddPivotCacheSource(0, "A1"), qt.ErrorMatches, `pivot cache source "A1" is not a range of cells`)
+	c.Assert(fileBuilder.AddPivotCacheSource(0, "A1:C1"), qt.ErrorMatches, "a pivot cache source needs a row of field names and at least one row of data")
+	c.Assert(fileBuilder.AddPivotCacheSource(0, "A1:C4"), qt.IsNil)
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	c.Assert(fileBuilder.AddPivotCacheSource(0, "A1:C4"), qt.Equals, BuiltStreamFileBuilderError)
+	c.Assert(streamFile.WriteAll([][]string{
+		{"Region", "Product", "Region"},
+		{"North", "Tea", "1"},
+		{"South", "Tea", "2"},
+		{"North", "Coffee", "3"},
+	}), qt.IsNil)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	parts := unzipParts(c, buffer.Bytes())
+	definition := parts["xl/pivotCache/pivotCacheDefinition1.xml"]
+	c.Assert(definition, qt.Contains, `<cacheSource type="worksheet"><worksheetSource ref="A1:C4" sheet="Sales"></worksheetSource></cacheSource>`)
+	c.Assert(definition, qt.Contains, `<cacheFields count="3"><cacheField name="Region" numFmtId="0"><sharedItems></sharedItems></cacheField><cacheField name="Product" numFmtId="0"><sharedItems></sharedItems></cacheField><cacheField name="Region2" numFmtId="0"><sharedItems></sharedItems></cacheField></cacheFields>`)
+	c.Assert(parts["xl/workbook.xml"], qt.Matches, `(?s).*<pivotCaches><pivotCache cacheId="1" r:id="(rId\d+)"></pivotCache></pivotCaches>.*`)
+	c.Assert(parts["xl/_rels/workbook.xml.rels"], qt.Contains, `Target="pivotCache/pivotCacheDefinition1.xml" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/pivotCacheDefinition"`)
+	c.Assert(parts["[Content_Types].xml"], qt.Contains, `<Override PartName="/xl/pivotCache/pivotCacheDefinition1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.pivotCacheDefinition+xml"></Override>`)
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	c.Assert(file.Sheets[0].Cell(3, 1).Value, qt.Equals, "Coffee")
+}
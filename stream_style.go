@@ -1,5 +1,10 @@
 package xlsx
 
+import (
+	"strings"
+	"sync"
+)
+
 // StreamStyle has style and formatting information.
 // Used to store a style for streaming
 type StreamStyle struct {
@@ -22,6 +27,7 @@ var (
 	StreamStyleBoldString       StreamStyle
 	StreamStyleItalicString     StreamStyle
 	StreamStyleUnderlinedString StreamStyle
+	StreamStyleWrappedTop       StreamStyle
 
 	StreamStyleDefaultInteger    StreamStyle
 	StreamStyleBoldInteger       StreamStyle
@@ -32,6 +38,36 @@ var (
 
 	StreamStyleDefaultDecimal StreamStyle
 )
+
+// coloredStringStyles holds the styles made by coloredStringStyle,
+// keyed by their font color, so that cells of the same color share a
+// single style.
+var (
+	coloredStringStylesMu sync.Mutex
+	coloredStringStyles   = map[string]StreamStyle{}
+)
+
+// reportTitleStyle is the style of the titles of the reports written
+// with StreamFileBuilder.WriteReport.
+var reportTitleStyle StreamStyle
+
+// tableOfContentsStyle is the style of the links to the sheets in the
+// table of contents added with StreamFileBuilder.AddTableOfContents,
+// which look like the links made in spreadsheet applications.
+var tableOfContentsStyle StreamStyle
+
+// generalNumberStyle is the style of the numbers and bools whose type
+// is not declared, such as those written by WriteReport and
+// StreamCSVToXLSX, which are shown in the General number format.
+var generalNumberStyle StreamStyle
+
+// numFmtStyles holds the styles made by numFmtStyle, keyed by their
+// number format code.
+var (
+	numFmtStylesMu sync.Mutex
+	numFmtStyles   = map[string]StreamStyle{}
+)
+
 var (
 	FontBold       *Font
 	FontItalic     *Font
@@ -64,6 +100,14 @@ func init() {
 	StreamStyleBoldString = MakeStringStyle(FontBold, DefaultFill(), DefaultAlignment(), DefaultBorder())
 	StreamStyleItalicString = MakeStringStyle(FontItalic, DefaultFill(), DefaultAlignment(), DefaultBorder())
 	StreamStyleUnderlinedString = MakeStringStyle(FontUnderlined, DefaultFill(), DefaultAlignment(), DefaultBorder())
+	StreamStyleWrappedTop = MakeStringStyle(DefaultFont(), DefaultFill(), NewAlignment("general", "top", true), DefaultBorder())
+	titleFont := NewFont(14, defaultFontName)
+	titleFont.Bold = true
+	reportTitleStyle = MakeStringStyle(titleFont, DefaultFill(), DefaultAlignment(), DefaultBorder())
+	linkFont := DefaultFont()
+	linkFont.Color = "FF0563C1"
+	linkFont.Underline = true
+	tableOfContentsStyle = MakeStringStyle(linkFont, DefaultFill(), DefaultAlignment(), DefaultBorder())
 
 	// Init default Integer styles
 	StreamStyleDefaultInteger = MakeIntegerStyle(DefaultFont(), DefaultFill(), DefaultAlignment(), DefaultBorder())
@@ -74,6 +118,7 @@ func init() {
 	StreamStyleDefaultDate = MakeDateStyle(DefaultFont(), DefaultFill(), DefaultAlignment(), DefaultBorder())
 
 	StreamStyleDefaultDecimal = MakeDecimalStyle(DefaultFont(), DefaultFill(), DefaultAlignment(), DefaultBorder())
+	generalNumberStyle = MakeStyle(GeneralFormat, DefaultFont(), DefaultFill(), DefaultAlignment(), DefaultBorder())
 
 	DefaultStringStreamingCellMetadata = StreamingCellMetadata{CellTypeString, StreamStyleDefaultString}
 	DefaultNumericStreamingCellMetadata = StreamingCellMetadata{CellTypeNumeric, StreamStyleDefaultString}
@@ -110,6 +155,39 @@ func MakeStringStyle(font *Font, fill *Fill, alignment *Alignment, border *Borde
 	return MakeStyle(GeneralFormat, font, fill, alignment, border)
 }
 
+// coloredStringStyle returns the default string style with its font
+// in the given ARGB color.  The same style is returned for every call
+// with the same color.
+func coloredStringStyle(argb string) StreamStyle {
+	argb = strings.ToUpper(argb)
+	coloredStringStylesMu.Lock()
+	defer coloredStringStylesMu.Unlock()
+	if style, ok := coloredStringStyles[argb]; ok {
+		return style
+	}
+	font := DefaultFont()
+	font.Color = argb
+	style := MakeStringStyle(font, DefaultFill(), DefaultAlignment(), DefaultBorder())
+	coloredStringStyles[argb] = style
+	return style
+}
+
+// numFmtStyle returns the default style for cells shown in the number
+// format code format.  The number format itself is added along with the
+// style when the first cell using it is written, as its id depends on
+// the file.  The same style is returned for every call with the same
+// format.
+func numFmtStyle(format string) StreamStyle {
+	numFmtStylesMu.Lock()
+	defer numFmtStylesMu.Unlock()
+	if style, ok := numFmtStyles[format]; ok {
+		return style
+	}
+	style := MakeStyle(GeneralFormat, DefaultFont(), DefaultFill(), DefaultAlignment(), DefaultBorder())
+	numFmtStyles[format] = style
+	return style
+}
+
 // MakeIntegerStyle creates a new style that can be used on cells with integer data.
 // If used on other data the formatting might be wrong.
 func MakeIntegerStyle(font *Font, fill *Fill, alignment *Alignment, border *Border) StreamStyle {
@@ -4,11 +4,15 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"math/big"
 	"io"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
+
+	qt "github.com/frankban/quicktest"
 )
 
 const (
@@ -889,3 +893,283 @@ func TestCustomNumberFormat(t *testing.T) {
 		t.Error("Incorrect format code")
 	}
 }
+
+func TestSetColStyle(t *testing.T) {
+	c := qt.New(t)
+
+	c.Run("UnstyledCellsInheritColumnFormat", func(c *qt.C) {
+		buffer := bytes.NewBuffer(nil)
+		fileBuilder := NewStreamFileBuilder(buffer)
+		c.Assert(fileBuilder.AddStreamStyleList([]StreamStyle{StreamStyleDefaultString, StreamStyleDefaultDecimal}), qt.IsNil)
+		c.Assert(fileBuilder.AddSheetS("Sheet1", []StreamStyle{StreamStyleDefaultString, StreamStyleDefaultString}), qt.IsNil)
+		c.Assert(fileBuilder.SetColStyle(0, 2, 2, StreamStyleDefaultDecimal), qt.IsNil)
+
+		streamFile, err := fileBuilder.Build()
+		c.Assert(err, qt.IsNil)
+		c.Assert(streamFile.WriteS([]StreamCell{
+			NewStringStreamCell("Price"),
+			NewStreamCell("1234.5", StreamStyleFromColumn, CellTypeNumeric),
+		}), qt.IsNil)
+		c.Assert(streamFile.WriteS([]StreamCell{
+			NewStringStreamCell("Cost"),
+			NewStreamCell("7", StreamStyleFromColumn, CellTypeNumeric),
+		}), qt.IsNil)
+		c.Assert(streamFile.Close(), qt.IsNil)
+
+		file, err := OpenBinary(buffer.Bytes())
+		c.Assert(err, qt.IsNil)
+		sheet := file.Sheets[0]
+		c.Assert(sheet.Col(1).GetStyle(), qt.Not(qt.IsNil))
+		c.Assert(sheet.Col(1).numFmt, qt.Equals, "0.00")
+
+		for row, expected := range []string{"1234.50", "7.00"} {
+			cell := sheet.Cell(row, 1)
+			c.Assert(cell.NumFmt, qt.Equals, "0.00")
+			value, err := cell.FormattedValue()
+			c.Assert(err, qt.IsNil)
+			c.Assert(value, qt.Equals, expected)
+		}
+	})
+
+	c.Run("CustomNumberFormats", func(c *qt.C) {
+		buffer := bytes.NewBuffer(nil)
+		fileBuilder := NewStreamFileBuilder(buffer)
+		cellFmtId := fileBuilder.AddNewNumberFormat("0.000")
+		colFmtId := fileBuilder.AddNewNumberFormat("#,##0.0")
+		cellStyle := MakeStyle(cellFmtId, DefaultFont(), DefaultFill(), DefaultAlignment(), DefaultBorder())
+		colStyle := MakeStyle(colFmtId, DefaultFont(), DefaultFill(), DefaultAlignment(), DefaultBorder())
+		c.Assert(fileBuilder.AddStreamStyleList([]StreamStyle{cellStyle, colStyle}), qt.IsNil)
+		c.Assert(fileBuilder.AddSheetS("Sheet1", []StreamStyle{cellStyle, cellStyle}), qt.IsNil)
+		c.Assert(fileBuilder.SetColStyle(0, 2, 2, colStyle), qt.IsNil)
+
+		streamFile, err := fileBuilder.Build()
+		c.Assert(err, qt.IsNil)
+		c.Assert(streamFile.WriteS([]StreamCell{
+			NewStreamCell("1.5", cellStyle, CellTypeNumeric),
+			NewStreamCell("1234.5", StreamStyleFromColumn, CellTypeNumeric),
+		}), qt.IsNil)
+		c.Assert(streamFile.Close(), qt.IsNil)
+
+		file, err := OpenBinary(buffer.Bytes())
+		c.Assert(err, qt.IsNil)
+		sheet := file.Sheets[0]
+		c.Assert(sheet.Cell(0, 0).NumFmt, qt.Equals, "0.000")
+		c.Assert(sheet.Cell(0, 1).NumFmt, qt.Equals, "#,##0.0")
+	})
+
+	c.Run("OverlappingColWidth", func(c *qt.C) {
+		buffer := bytes.NewBuffer(nil)
+		fileBuilder := NewStreamFileBuilder(buffer)
+		c.Assert(fileBuilder.AddStreamStyleList([]StreamStyle{StreamStyleDefaultString, StreamStyleDefaultDecimal}), qt.IsNil)
+		c.Assert(fileBuilder.AddSheetS("Sheet1", []StreamStyle{StreamStyleDefaultString, StreamStyleDefaultString}), qt.IsNil)
+		c.Assert(fileBuilder.SetColWidth(0, 2, 4, 20), qt.IsNil)
+		c.Assert(fileBuilder.SetColStyle(0, 3, 5, StreamStyleDefaultDecimal), qt.IsNil)
+
+		streamFile, err := fileBuilder.Build()
+		c.Assert(err, qt.IsNil)
+		c.Assert(streamFile.WriteS([]StreamCell{NewStringStreamCell("a"), NewStringStreamCell("b")}), qt.IsNil)
+		c.Assert(streamFile.Close(), qt.IsNil)
+
+		sheetXML := unzipParts(c, buffer.Bytes())["xl/worksheets/sheet1.xml"]
+		c.Assert(strings.Count(sheetXML, `min="3"`), qt.Equals, 1)
+
+		file, err := OpenBinary(buffer.Bytes())
+		c.Assert(err, qt.IsNil)
+		sheet := file.Sheets[0]
+		c.Assert(sheet.GetColWidth(1), qt.Equals, 20.0)
+		c.Assert(sheet.Col(1).numFmt, qt.Not(qt.Equals), "0.00")
+		for _, col := range []int{2, 3} {
+			c.Assert(sheet.GetColWidth(col), qt.Equals, 20.0)
+			c.Assert(sheet.Col(col).numFmt, qt.Equals, "0.00")
+		}
+		c.Assert(sheet.Col(4).numFmt, qt.Equals, "0.00")
+	})
+
+	c.Run("Errors", func(c *qt.C) {
+		fileBuilder := NewStreamFileBuilder(bytes.NewBuffer(nil))
+		unknownFormat := MakeStyle(200, DefaultFont(), DefaultFill(), DefaultAlignment(), DefaultBorder())
+		c.Assert(fileBuilder.AddStreamStyleList([]StreamStyle{StreamStyleDefaultDecimal, unknownFormat}), qt.IsNil)
+		c.Assert(fileBuilder.SetColStyle(0, 1, 1, StreamStyleDefaultDecimal), qt.ErrorMatches, "sheet index out of range")
+		c.Assert(fileBuilder.AddSheetS("Sheet1", nil), qt.IsNil)
+		c.Assert(fileBuilder.SetColStyle(0, 1, 1, unknownFormat), qt.ErrorMatches, "trying to make use of a number format that has not been added")
+		c.Assert(fileBuilder.SetColStyle(0, 1, 1, StreamStyleDefaultInteger), qt.ErrorMatches, "trying to make use of a style that has not been added")
+		for _, cols := range [][2]int{{2, 1}, {0, 1}, {1, 16385}} {
+			c.Assert(fileBuilder.SetColStyle(0, cols[0], cols[1], StreamStyleDefaultDecimal), qt.ErrorMatches, fmt.Sprintf("invalid column range %d to %d", cols[0], cols[1]))
+			c.Assert(fileBuilder.SetColWidth(0, cols[0], cols[1], 20), qt.ErrorMatches, fmt.Sprintf("invalid column range %d to %d", cols[0], cols[1]))
+		}
+		c.Assert(fileBuilder.SetColStyle(0, 1, 16384, StreamStyleDefaultDecimal), qt.IsNil)
+		c.Assert(fileBuilder.SetColWidth(1, 1, 1, 20), qt.ErrorMatches, "sheet index out of range")
+		c.Assert(fileBuilder.SetColWidth(0, 1, 1, 0), qt.ErrorMatches, "the width of a column must be positive")
+		_, err := fileBuilder.Build()
+		c.Assert(err, qt.IsNil)
+		c.Assert(fileBuilder.SetColStyle(0, 1, 1, StreamStyleDefaultDecimal), qt.Equals, BuiltStreamFileBuilderError)
+		c.Assert(fileBuilder.SetColWidth(0, 1, 1, 20), qt.Equals, BuiltStreamFileBuilderError)
+	})
+}
+
+func TestSetAlternatingRowStyles(t *testing.T) {
+	c := qt.New(t)
+
+	evenStyle := MakeStringStyle(DefaultFont(), FillGreen, DefaultAlignment(), DefaultBorder())
+	oddStyle := MakeStringStyle(DefaultFont(), FillRed, DefaultAlignment(), DefaultBorder())
+	redStyle := MakeStringStyle(FontBold, FillWhite, DefaultAlignment(), DefaultBorder())
+
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddStreamStyleList([]StreamStyle{StreamStyleDefaultString, evenStyle, oddStyle, redStyle}), qt.IsNil)
+	c.Assert(fileBuilder.AddSheetS("Sheet1", []StreamStyle{StreamStyleDefaultString, StreamStyleDefaultString}), qt.IsNil)
+	c.Assert(fileBuilder.SetAlternatingRowStyles(1, evenStyle, oddStyle), qt.ErrorMatches, "sheet index out of range")
+	c.Assert(fileBuilder.SetAlternatingRowStyles(0, evenStyle, StreamStyleDefaultDecimal), qt.ErrorMatches, "trying to make use of a style that has not been added")
+	c.Assert(fileBuilder.SetAlternatingRowStyles(0, evenStyle, oddStyle), qt.IsNil)
+
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	for i := 0; i < 4; i++ {
+		c.Assert(streamFile.WriteS([]StreamCell{
+			NewStreamCell(strconv.Itoa(i), StreamStyleFromColumn, CellTypeString),
+			NewStreamCell("own style", redStyle, CellTypeString),
+		}), qt.IsNil)
+	}
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	sheet := file.Sheets[0]
+	var fills []string
+	for row := 0; row < 4; row++ {
+		fills = append(fills, sheet.Cell(row, 0).GetStyle().Fill.FgColor)
+		c.Assert(sheet.Cell(row, 1).GetStyle().Font.Bold, qt.Equals, true)
+	}
+	c.Assert(fills, qt.DeepEquals, []string{RGB_Light_Red, RGB_Light_Green, RGB_Light_Red, RGB_Light_Green})
+}
+
+func TestStreamStyleWrappedTop(t *testing.T) {
+	c := qt.New(t)
+
+	_, file := streamRows(c, nil, []StreamCell{
+		NewStreamCell("first line\nsecond line", StreamStyleWrappedTop, CellTypeString),
+	})
+	alignment := file.Sheets[0].Cell(0, 0).GetStyle().Alignment
+	c.Assert(alignment.WrapText, qt.Equals, true)
+	c.Assert(alignment.Vertical, qt.Equals, "top")
+}
+
+func TestNewAlignment(t *testing.T) {
+	c := qt.New(t)
+
+	shrunk := NewAlignment("center", "center", false)
+	shrunk.ShrinkToFit = true
+	wrappedStyle := MakeStringStyle(DefaultFont(), DefaultFill(), NewAlignment("left", "top", true), DefaultBorder())
+	shrunkStyle := MakeStringStyle(DefaultFont(), DefaultFill(), shrunk, DefaultBorder())
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddStreamStyleList([]StreamStyle{wrappedStyle, shrunkStyle}), qt.IsNil)
+	c.Assert(fileBuilder.AddSheetS("Sheet1", []StreamStyle{wrappedStyle, shrunkStyle}), qt.IsNil)
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	c.Assert(streamFile.WriteSWithHeight([]StreamCell{
+		NewStyledStringStreamCell("a description long enough to wrap", wrappedStyle),
+		NewStyledStringStreamCell("a label shrunk to fit", shrunkStyle),
+	}, 45), qt.IsNil)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	stylesXML := unzipParts(c, buffer.Bytes())["xl/styles.xml"]
+	c.Assert(stylesXML, qt.Contains, `<alignment horizontal="left" indent="0" shrinkToFit="0" textRotation="0" vertical="top" wrapText="1"/>`)
+	c.Assert(stylesXML, qt.Contains, `<alignment horizontal="center" indent="0" shrinkToFit="1" textRotation="0" vertical="center" wrapText="0"/>`)
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	sheet := file.Sheets[0]
+	c.Assert(sheet.Row(0).Height, qt.Equals, 45.0)
+	c.Assert(sheet.Cell(0, 0).GetStyle().Alignment, qt.Equals, Alignment{Horizontal: "left", Vertical: "top", WrapText: true})
+	c.Assert(sheet.Cell(0, 1).GetStyle().Alignment, qt.Equals, Alignment{Horizontal: "center", Vertical: "center", ShrinkToFit: true})
+}
+
+func TestNewColoredStringStreamCell(t *testing.T) {
+	c := qt.New(t)
+
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddStreamStyle(StreamStyleDefaultString), qt.IsNil)
+	c.Assert(fileBuilder.AddSheetS("Sheet1", []StreamStyle{StreamStyleDefaultString, StreamStyleDefaultString}), qt.IsNil)
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	styleCount := len(streamFile.styleIdMap)
+	c.Assert(streamFile.WriteS([]StreamCell{
+		NewColoredStringStreamCell("failed", "FFFF0000"),
+		NewColoredStringStreamCell("passed", "FF00FF00"),
+	}), qt.IsNil)
+	c.Assert(streamFile.WriteS([]StreamCell{
+		NewColoredStringStreamCell("failed", "ffff0000"),
+		NewStringStreamCell("plain"),
+	}), qt.IsNil)
+	c.Assert(len(streamFile.styleIdMap), qt.Equals, styleCount+2)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	sheet := file.Sheets[0]
+	c.Assert(sheet.Cell(0, 0).Value, qt.Equals, "failed")
+	c.Assert(sheet.Cell(0, 0).GetStyle().Font.Color, qt.Equals, "FFFF0000")
+	c.Assert(sheet.Cell(0, 1).GetStyle().Font.Color, qt.Equals, "FF00FF00")
+	c.Assert(sheet.Cell(1, 0).GetStyle().Font.Color, qt.Equals, "FFFF0000")
+	c.Assert(sheet.Cell(1, 1).GetStyle().Font.Color, qt.Equals, "")
+}
+
+func TestSetDefaultNumberFormat(t *testing.T) {
+	c := qt.New(t)
+
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddStreamStyle(StreamStyleDefaultString), qt.IsNil)
+	c.Assert(fileBuilder.SetDefaultNumberFormat("#,##0.00"), qt.IsNil)
+	c.Assert(fileBuilder.AddSheetS("Sheet1", []StreamStyle{StreamStyleDefaultString, StreamStyleDefaultString, StreamStyleDefaultString}), qt.IsNil)
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	c.Assert(fileBuilder.SetDefaultNumberFormat("0"), qt.Equals, BuiltStreamFileBuilderError)
+	c.Assert(streamFile.WriteS([]StreamCell{
+		NewIntegerStreamCell(1234),
+		NewStreamCell("2.5", StreamStyleFromColumn, CellTypeNumeric),
+		NewStringStreamCell("12"),
+	}), qt.IsNil)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	sheet := file.Sheets[0]
+	for col, expected := range []string{"1,234.00", "2.50", "12"} {
+		value, err := sheet.Cell(0, col).FormattedValue()
+		c.Assert(err, qt.IsNil)
+		c.Assert(value, qt.Equals, expected)
+	}
+}
+
+func TestAddNewNumberFormatReusesIds(t *testing.T) {
+	c := qt.New(t)
+
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddNewNumberFormat("0.000"), qt.Equals, 164)
+	c.Assert(fileBuilder.AddNewNumberFormat("#,##0.0"), qt.Equals, 165)
+	c.Assert(fileBuilder.AddNewNumberFormat("0.000"), qt.Equals, 164)
+	c.Assert(fileBuilder.AddNewNumberFormat("0.00"), qt.Equals, 2)
+	// A format that no style uses still keeps its id.
+	c.Assert(fileBuilder.AddNewNumberFormat("0.0%"), qt.Equals, 166)
+	c.Assert(fileBuilder.SetDefaultNumberFormat("#,##0.0"), qt.IsNil)
+	style := MakeStyle(164, DefaultFont(), DefaultFill(), DefaultAlignment(), DefaultBorder())
+	c.Assert(fileBuilder.AddStreamStyle(style), qt.IsNil)
+	c.Assert(fileBuilder.AddSheetS("Sheet1", []StreamStyle{style, style, style}), qt.IsNil)
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	// Cells that bring their own format reuse the ids of the formats
+	// that were added, and new formats take the next id.
+	c.Assert(streamFile.WriteS([]StreamCell{
+		NewMoneyStreamCell(big.NewRat(1, 3), "0.000"),
+		NewMoneyStreamCell(big.NewRat(2, 3), "#,##0.0"),
+		NewFractionStreamCell(0.1234, 3),
+	}), qt.IsNil)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	stylesXML := unzipParts(c, buffer.Bytes())["xl/styles.xml"]
+	c.Assert(stylesXML, qt.Contains, `<numFmts count="4"><numFmt numFmtId="164" formatCode="0.000"/><numFmt numFmtId="165" formatCode="#,##0.0"/><numFmt numFmtId="166" formatCode="0.0%"/><numFmt numFmtId="167" formatCode="# ???/???"/></numFmts>`)
+}
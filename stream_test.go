@@ -2,11 +2,15 @@ package xlsx
 
 import (
 	"bytes"
+	"database/sql/driver"
 	"fmt"
 	"io"
+	"math"
+	"math/big"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	qt "github.com/frankban/quicktest"
 	. "gopkg.in/check.v1"
@@ -1076,3 +1080,995 @@ func TestMergeCells(t *testing.T) {
 		t.Error("Incorrect merge cell values")
 	}
 }
+
+func TestWriteRowAt(t *testing.T) {
+	c := qt.New(t)
+
+	build := func(c *qt.C, buffer io.Writer) *StreamFile {
+		fileBuilder := NewStreamFileBuilder(buffer)
+		c.Assert(fileBuilder.AddStreamStyle(StreamStyleDefaultString), qt.IsNil)
+		c.Assert(fileBuilder.AddSheetS("Sheet1", []StreamStyle{StreamStyleDefaultString, StreamStyleDefaultString}), qt.IsNil)
+		c.Assert(fileBuilder.AddSheetS("Sheet2", []StreamStyle{StreamStyleDefaultString}), qt.IsNil)
+		streamFile, err := fileBuilder.Build()
+		c.Assert(err, qt.IsNil)
+		return streamFile
+	}
+	row := func(values ...string) []StreamCell {
+		var cells []StreamCell
+		for _, v := range values {
+			cells = append(cells, NewStreamCell(v, StreamStyleFromColumn, CellTypeString))
+		}
+		return cells
+	}
+
+	c.Run("RowsAreWrittenInOrder", func(c *qt.C) {
+		buffer := bytes.NewBuffer(nil)
+		streamFile := build(c, buffer)
+		c.Assert(streamFile.WriteRowAt(2, row("c", "3")), qt.IsNil)
+		c.Assert(streamFile.WriteRowAt(0, row("a", "1")), qt.IsNil)
+		c.Assert(streamFile.WriteRowAt(1, row("b", "2")), qt.IsNil)
+		c.Assert(streamFile.NextSheet(), qt.IsNil)
+		c.Assert(streamFile.WriteRowAt(3, row("last")), qt.IsNil)
+		c.Assert(streamFile.WriteRowAt(1, row("first")), qt.IsNil)
+		c.Assert(streamFile.Close(), qt.IsNil)
+
+		file, err := OpenBinary(buffer.Bytes())
+		c.Assert(err, qt.IsNil)
+		output, err := file.ToSlice()
+		c.Assert(err, qt.IsNil)
+		c.Assert(output[0], qt.DeepEquals, [][]string{{"a", "1"}, {"b", "2"}, {"c", "3"}})
+		c.Assert(output[1], qt.DeepEquals, [][]string{{}, {"first"}, {}, {"last"}})
+	})
+
+	c.Run("SequentialRowsFirst", func(c *qt.C) {
+		buffer := bytes.NewBuffer(nil)
+		streamFile := build(c, buffer)
+		c.Assert(streamFile.WriteS(row("a", "1")), qt.IsNil)
+		c.Assert(streamFile.WriteRowAt(0, row("x", "0")), qt.ErrorMatches, "row 0 has already been written")
+		c.Assert(streamFile.WriteRowAt(2, row("c", "3")), qt.IsNil)
+		c.Assert(streamFile.WriteRowAt(2, row("c", "3")), qt.ErrorMatches, "row 2 has already been written")
+		c.Assert(streamFile.WriteRowAt(1, row("b")), qt.Equals, WrongNumberOfRowsError)
+		c.Assert(streamFile.WriteS(row("b", "2")), qt.Equals, BufferedSheetError)
+		c.Assert(streamFile.Close(), qt.Equals, BufferedSheetError)
+	})
+
+	c.Run("GapsAreLeftOut", func(c *qt.C) {
+		buffer := bytes.NewBuffer(nil)
+		streamFile := build(c, buffer)
+		c.Assert(streamFile.WriteRowAt(Excel2006MaxRowCount, row("x", "0")), qt.ErrorMatches, "row 1048576 is beyond the last row of a sheet, 1048575")
+		c.Assert(streamFile.WriteRowAt(Excel2006MaxRowIndex, row("z", "9")), qt.IsNil)
+		c.Assert(streamFile.WriteRowAt(1, row("a", "1")), qt.IsNil)
+		c.Assert(streamFile.Close(), qt.IsNil)
+
+		sheetXML := unzipParts(c, buffer.Bytes())["xl/worksheets/sheet1.xml"]
+		c.Assert(strings.Count(sheetXML, "<row "), qt.Equals, 2)
+		c.Assert(sheetXML, qt.Contains, `<row r="2">`)
+		c.Assert(sheetXML, qt.Contains, `<row r="1048576">`)
+	})
+}
+
+func TestWriteSharedFormulaColumn(t *testing.T) {
+	c := qt.New(t)
+
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddStreamStyle(StreamStyleDefaultString), qt.IsNil)
+	c.Assert(fileBuilder.AddSheetS("Sheet1", []StreamStyle{StreamStyleDefaultString, StreamStyleDefaultString}), qt.IsNil)
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+
+	c.Assert(streamFile.WriteS([]StreamCell{
+		NewStreamCell("a", StreamStyleFromColumn, CellTypeString),
+		NewStreamCell("b", StreamStyleFromColumn, CellTypeString),
+	}), qt.IsNil)
+	c.Assert(streamFile.WriteSharedFormulaColumn(1, `A2&"-"&$A$2`, 3), qt.IsNil)
+	c.Assert(streamFile.WriteSharedFormulaColumn(0, "SUM(B2:B3)", 2), qt.IsNil)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	sheet := file.Sheets[0]
+	var formulas []string
+	for _, coords := range [][2]int{{1, 1}, {2, 1}, {3, 1}, {4, 0}, {5, 0}} {
+		formulas = append(formulas, sheet.Cell(coords[0], coords[1]).Formula())
+	}
+	c.Assert(formulas, qt.DeepEquals, []string{
+		`A2&"-"&$A$2`, `A3&"-"&$A$2`, `A4&"-"&$A$2`, "SUM(B2:B3)", "SUM(B3:B4)",
+	})
+
+	fileBuilder = NewStreamFileBuilder(bytes.NewBuffer(nil))
+	c.Assert(fileBuilder.AddStreamStyle(StreamStyleDefaultString), qt.IsNil)
+	c.Assert(fileBuilder.AddSheetS("Sheet1", []StreamStyle{StreamStyleDefaultString, StreamStyleDefaultString}), qt.IsNil)
+	streamFile, err = fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	c.Assert(streamFile.WriteS([]StreamCell{
+		NewStreamCell("a", StreamStyleFromColumn, CellTypeString),
+		NewStreamCell("b", StreamStyleFromColumn, CellTypeString),
+	}), qt.IsNil)
+	c.Assert(streamFile.WriteSharedFormulaColumn(2, "A1", 1), qt.ErrorMatches, "column 2 is out of range")
+}
+
+func TestSetBoolsAsText(t *testing.T) {
+	c := qt.New(t)
+
+	write := func(c *qt.C, boolsAsText bool) *Sheet {
+		_, file := streamRows(c, func(fileBuilder *StreamFileBuilder) {
+			c.Assert(fileBuilder.SetBoolsAsText(boolsAsText), qt.IsNil)
+		}, []StreamCell{
+			NewStreamCell("1", StreamStyleDefaultString, CellTypeBool),
+			NewStreamCell("0", StreamStyleDefaultString, CellTypeBool),
+		})
+		return file.Sheets[0]
+	}
+
+	c.Run("Enabled", func(c *qt.C) {
+		sheet := write(c, true)
+		for col, expected := range []string{"TRUE", "FALSE"} {
+			cell := sheet.Cell(0, col)
+			c.Assert(cell.Type(), qt.Equals, CellTypeInline)
+			c.Assert(cell.Value, qt.Equals, expected)
+		}
+	})
+
+	c.Run("Disabled", func(c *qt.C) {
+		sheet := write(c, false)
+		c.Assert(sheet.Cell(0, 0).Type(), qt.Equals, CellTypeBool)
+		c.Assert(sheet.Cell(0, 0).Bool(), qt.Equals, true)
+		c.Assert(sheet.Cell(0, 1).Bool(), qt.Equals, false)
+	})
+}
+
+func TestSkipStreamCell(t *testing.T) {
+	c := qt.New(t)
+
+	parts, file := streamRows(c, nil,
+		[]StreamCell{NewStringStreamCell("a"), SkipStreamCell(), NewStringStreamCell("c")},
+		[]StreamCell{SkipStreamCell(), NewStringStreamCell("e"), NewStringStreamCell("f")},
+	)
+	sheetXML := parts["xl/worksheets/sheet1.xml"]
+	c.Assert(sheetXML, qt.Contains, `<c r="A1"`)
+	c.Assert(sheetXML, qt.Not(qt.Contains), `<c r="B1"`)
+	c.Assert(sheetXML, qt.Contains, `<c r="C1"`)
+	c.Assert(sheetXML, qt.Not(qt.Contains), `<c r="A2"`)
+	c.Assert(sheetXML, qt.Contains, `<c r="B2"`)
+
+	output, err := file.ToSlice()
+	c.Assert(err, qt.IsNil)
+	c.Assert(output[0], qt.DeepEquals, [][]string{{"a", "", "c"}, {"", "e", "f"}})
+}
+
+func TestNewHyperlinkFormulaStreamCell(t *testing.T) {
+	c := qt.New(t)
+
+	parts, file := streamRows(c, nil, []StreamCell{
+		NewStringStreamCell("home"),
+		NewHyperlinkFormulaStreamCell("Example", "https://example.com/"),
+	}, []StreamCell{
+		NewStringStreamCell("quoted"),
+		NewHyperlinkFormulaStreamCell(`Say "hi"`, "https://example.com/?q=a&b"),
+	})
+	c.Assert(parts["xl/worksheets/sheet1.xml"], qt.Contains, `<c r="B1" t="str"><f>HYPERLINK(&#34;https://example.com/&#34;,&#34;Example&#34;)</f><v>Example</v></c>`)
+
+	sheet := file.Sheets[0]
+	c.Assert(sheet.Cell(0, 1).Formula(), qt.Equals, `HYPERLINK("https://example.com/","Example")`)
+	c.Assert(sheet.Cell(0, 1).Value, qt.Equals, "Example")
+	c.Assert(sheet.Cell(1, 1).Formula(), qt.Equals, `HYPERLINK("https://example.com/?q=a&b","Say ""hi""")`)
+	c.Assert(sheet.Cell(1, 1).Value, qt.Equals, `Say "hi"`)
+}
+
+func TestWriteBlankRow(t *testing.T) {
+	c := qt.New(t)
+
+	spacerStyle := MakeStringStyle(DefaultFont(), FillGreen, DefaultAlignment(), DefaultBorder())
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddStreamStyleList([]StreamStyle{StreamStyleDefaultString, spacerStyle}), qt.IsNil)
+	c.Assert(fileBuilder.AddSheetS("Sheet1", []StreamStyle{StreamStyleDefaultString, StreamStyleDefaultString}), qt.IsNil)
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	c.Assert(streamFile.WriteS([]StreamCell{NewStringStreamCell("a"), NewStringStreamCell("b")}), qt.IsNil)
+	c.Assert(streamFile.WriteBlankRow(spacerStyle), qt.IsNil)
+	c.Assert(streamFile.WriteS([]StreamCell{NewStringStreamCell("c"), NewStringStreamCell("d")}), qt.IsNil)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	sheetXML := unzipParts(c, buffer.Bytes())["xl/worksheets/sheet1.xml"]
+	c.Assert(sheetXML, qt.Matches, `(?s).*<row r="2"><c r="A2" s="\d+"></c><c r="B2" s="\d+"></c></row>.*`)
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	sheet := file.Sheets[0]
+	for col := 0; col < 2; col++ {
+		cell := sheet.Cell(1, col)
+		c.Assert(cell.Value, qt.Equals, "")
+		c.Assert(cell.GetStyle().Fill.FgColor, qt.Equals, RGB_Light_Green)
+	}
+	c.Assert(sheet.Cell(2, 0).Value, qt.Equals, "c")
+}
+
+func TestNewMoneyStreamCell(t *testing.T) {
+	c := qt.New(t)
+
+	sum := new(big.Rat).Add(big.NewRat(1, 10), big.NewRat(2, 10))
+	parts, file := streamRows(c, nil, []StreamCell{
+		NewMoneyStreamCell(sum, "$#,##0.00"),
+		NewMoneyStreamCell(big.NewRat(-5, 2), `#,##0" EUR"`),
+	})
+	c.Assert(parts["xl/worksheets/sheet1.xml"], qt.Matches, `(?s).*<c r="A1" s="\d+" t="n"><v>0.30</v></c><c r="B1" s="\d+" t="n"><v>-3</v></c>.*`)
+
+	sheet := file.Sheets[0]
+	c.Assert(sheet.Cell(0, 0).Value, qt.Equals, "0.30")
+	c.Assert(sheet.Cell(0, 0).NumFmt, qt.Equals, "$#,##0.00")
+	c.Assert(sheet.Cell(0, 1).NumFmt, qt.Equals, `#,##0" EUR"`)
+}
+
+func TestNumberFormatStreamCells(t *testing.T) {
+	c := qt.New(t)
+
+	tests := []struct {
+		about string
+		cells []StreamCell
+		// added are the formats added to the style sheet, once each,
+		// and builtIn those that are built in and so are not.
+		added     []string
+		builtIn   []string
+		formatted []string
+	}{{
+		about: "fractions",
+		cells: []StreamCell{
+			NewFractionStreamCell(0.5, 1),
+			NewFractionStreamCell(2.375, 2),
+			NewFractionStreamCell(0.1234, 3),
+		},
+		added:     []string{"# ???/???"},
+		builtIn:   []string{"# ?/?"},
+		formatted: []string{"1/2", "2 3/8", "106/859"},
+	}, {
+		about: "fixed decimals",
+		cells: []StreamCell{
+			NewFixedDecimalStreamCell(math.Pi, 3),
+			NewFixedDecimalStreamCell(2, 3),
+			NewFixedDecimalStreamCell(2.75, 0),
+			NewFixedDecimalStreamCell(1.005, 2),
+		},
+		added:     []string{"0.000"},
+		builtIn:   []string{"0.00"},
+		formatted: []string{"3.142", "2.000", "3", "1.00"},
+	}, {
+		about: "percentages",
+		cells: []StreamCell{
+			NewPercentStreamCell(0.1234, 1),
+			NewPercentStreamCell(0.5, 1),
+			NewPercentStreamCell(0.1234, 0),
+			NewPercentStreamCell(-0.02, -1),
+		},
+		added:     []string{"0.0%"},
+		builtIn:   []string{"0%"},
+		formatted: []string{"12.3%", "50.0%", "12%", "-2%"},
+	}}
+
+	for _, test := range tests {
+		c.Run(test.about, func(c *qt.C) {
+			parts, file := streamRows(c, nil, test.cells)
+			for _, code := range test.added {
+				c.Assert(strings.Count(parts["xl/styles.xml"], `formatCode="`+code+`"`), qt.Equals, 1)
+			}
+			for _, code := range test.builtIn {
+				c.Assert(parts["xl/styles.xml"], qt.Not(qt.Contains), `formatCode="`+code+`"`)
+			}
+			sheet := file.Sheets[0]
+			for col, want := range test.formatted {
+				c.Assert(sheet.Cell(0, col).Value, qt.Equals, test.cells[col].cellData)
+				formatted, err := sheet.Cell(0, col).FormattedValue()
+				c.Assert(err, qt.IsNil)
+				c.Assert(formatted, qt.Equals, want)
+				// Cells with the same format share their style.
+				for other := 0; other < col; other++ {
+					if test.cells[other].numFmt == test.cells[col].numFmt {
+						c.Assert(sheet.Cell(0, col).GetStyle(), qt.DeepEquals, sheet.Cell(0, other).GetStyle())
+					}
+				}
+			}
+		})
+	}
+
+	// The digits of the formats are kept to 30.
+	c.Assert(NewFractionStreamCell(0.5, 1000).numFmt, qt.Equals, "# "+strings.Repeat("?", 30)+"/"+strings.Repeat("?", 30))
+	c.Assert(NewFixedDecimalStreamCell(1, 1000).numFmt, qt.Equals, "0."+strings.Repeat("0", 30))
+	c.Assert(NewPercentStreamCell(1, 1000).numFmt, qt.Equals, "0."+strings.Repeat("0", 30)+"%")
+}
+
+func TestNewLocalizedNumberStreamCell(t *testing.T) {
+	c := qt.New(t)
+
+	_, file := streamRows(c, nil, []StreamCell{
+		NewLocalizedNumberStreamCell(1234.56, 2, ',', '.'),
+		NewLocalizedNumberStreamCell(-1234567.891, 1, '.', ' '),
+		NewLocalizedNumberStreamCell(999.5, 0, ',', '.'),
+		NewLocalizedNumberStreamCell(-0.001, 2, ',', 0),
+	})
+	sheet := file.Sheets[0]
+	for col, want := range []string{"1.234,56", "-1 234 567.9", "1.000", "0,00"} {
+		c.Assert(sheet.Cell(0, col).Value, qt.Equals, want)
+	}
+}
+
+func TestWriteWithSchema(t *testing.T) {
+	c := qt.New(t)
+
+	headers := []string{"Name", "Amount", "Paid", "Due"}
+	schema := []CellType{CellTypeString, CellTypeNumeric, CellTypeBool, CellTypeDate}
+	due := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddSheet("Sheet1", []*CellType{CellTypeString.Ptr(), CellTypeNumeric.Ptr(), CellTypeBool.Ptr(), CellTypeNumeric.Ptr()}), qt.IsNil)
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+
+	err = streamFile.WriteWithSchema(headers, [][]interface{}{
+		{"Ann", 12.5, true, due},
+		{"Bob", "12.50", false, due},
+	}, schema)
+	c.Assert(err, qt.ErrorMatches, `row 1, column "Amount": 12.50 of type string is not a number`)
+	err = streamFile.WriteWithSchema(headers, [][]interface{}{{"Ann", 12.5, true, "2020-03-01"}}, schema)
+	c.Assert(err, qt.ErrorMatches, `row 0, column "Due": 2020-03-01 of type string is not a date`)
+	err = streamFile.WriteWithSchema(headers, [][]interface{}{{"Ann", 12.5, true}}, schema)
+	c.Assert(err, qt.ErrorMatches, "row 0 has 3 values but the schema has 4 columns")
+	err = streamFile.WriteWithSchema(headers[:3], nil, schema)
+	c.Assert(err, qt.ErrorMatches, "there are 3 headers but the schema has 4 columns")
+	err = streamFile.WriteWithSchema(headers, nil, []CellType{CellTypeString, CellTypeNumeric, CellTypeBool, CellTypeError})
+	c.Assert(err, qt.ErrorMatches, `column "Due": cell type 5 is not supported in a schema`)
+
+	// Nothing is written by the calls that fail.
+	c.Assert(streamFile.WriteWithSchema(headers, [][]interface{}{
+		{"Ann", 12.5, true, due},
+		{"Bob", 7, nil, due},
+	}, schema), qt.IsNil)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	sheet := file.Sheets[0]
+	c.Assert(sheet.MaxRow, qt.Equals, 3)
+	for col, header := range headers {
+		c.Assert(sheet.Cell(0, col).Value, qt.Equals, header)
+	}
+	c.Assert(sheet.Cell(1, 0).Value, qt.Equals, "Ann")
+	c.Assert(sheet.Cell(1, 1).Type(), qt.Equals, CellTypeNumeric)
+	c.Assert(sheet.Cell(1, 1).Value, qt.Equals, "12.5")
+	c.Assert(sheet.Cell(1, 2).Bool(), qt.Equals, true)
+	c.Assert(sheet.Cell(1, 3).IsDate(), qt.Equals, true)
+	date, err := sheet.Cell(1, 3).GetTime(false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(date, qt.Equals, due)
+	c.Assert(sheet.Cell(2, 1).Value, qt.Equals, "7")
+	c.Assert(sheet.Cell(2, 2).IsEmpty(), qt.Equals, true)
+}
+
+func TestWriteSQLRows(t *testing.T) {
+	c := qt.New(t)
+
+	created := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	testSQLResults["SELECT * FROM accounts"] = testSQLResult{
+		columns: []string{"name", "balance", "visits", "active", "created", "note"},
+		rows: [][]driver.Value{
+			{"Ann", 12.5, int64(3), true, created, []byte("first")},
+			{"Bob", nil, int64(-1), false, nil, nil},
+		},
+	}
+	db, err := openTestSQL()
+	c.Assert(err, qt.IsNil)
+	defer db.Close()
+	rows, err := db.Query("SELECT * FROM accounts")
+	c.Assert(err, qt.IsNil)
+	defer rows.Close()
+
+	headerStyle := MakeStyle(GeneralFormat, FontBold, DefaultFill(), DefaultAlignment(), DefaultBorder())
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddSheet("Accounts", nil), qt.IsNil)
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	c.Assert(streamFile.WriteSQLRows(rows, headerStyle), qt.IsNil)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	sheet := file.Sheets[0]
+	c.Assert(sheet.MaxRow, qt.Equals, 3)
+	for col, name := range []string{"name", "balance", "visits", "active", "created", "note"} {
+		c.Assert(sheet.Cell(0, col).Value, qt.Equals, name)
+		c.Assert(sheet.Cell(0, col).GetStyle().Font.Bold, qt.Equals, true)
+	}
+	c.Assert(sheet.Cell(1, 0).Value, qt.Equals, "Ann")
+	c.Assert(sheet.Cell(1, 1).Type(), qt.Equals, CellTypeNumeric)
+	c.Assert(sheet.Cell(1, 1).Value, qt.Equals, "12.5")
+	visits, err := sheet.Cell(1, 2).Int()
+	c.Assert(err, qt.IsNil)
+	c.Assert(visits, qt.Equals, 3)
+	c.Assert(sheet.Cell(1, 3).Bool(), qt.Equals, true)
+	c.Assert(sheet.Cell(1, 4).IsDate(), qt.Equals, true)
+	date, err := sheet.Cell(1, 4).GetTime(false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(date, qt.Equals, created)
+	c.Assert(sheet.Cell(1, 5).Value, qt.Equals, "first")
+	c.Assert(sheet.Cell(2, 1).IsEmpty(), qt.Equals, true)
+	c.Assert(sheet.Cell(2, 2).Value, qt.Equals, "-1")
+	c.Assert(sheet.Cell(2, 3).Bool(), qt.Equals, false)
+	c.Assert(sheet.Cell(2, 4).IsEmpty(), qt.Equals, true)
+	c.Assert(sheet.Cell(2, 5).IsEmpty(), qt.Equals, true)
+
+	// Drivers give decimals, and some drivers every value, as bytes,
+	// which are written as numbers in numeric columns.
+	testSQLResults["SELECT price, code FROM items"] = testSQLResult{
+		columns: []string{"price", "code"},
+		types:   []string{"DECIMAL", "VARCHAR"},
+		rows: [][]driver.Value{
+			{[]byte("19.99"), []byte("007")},
+		},
+	}
+	rows, err = db.Query("SELECT price, code FROM items")
+	c.Assert(err, qt.IsNil)
+	defer rows.Close()
+	buffer = bytes.NewBuffer(nil)
+	fileBuilder = NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddSheet("Items", nil), qt.IsNil)
+	streamFile, err = fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	c.Assert(streamFile.WriteSQLRows(rows, headerStyle), qt.IsNil)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	file, err = OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	sheet = file.Sheets[0]
+	c.Assert(sheet.Cell(1, 0).Type(), qt.Equals, CellTypeNumeric)
+	c.Assert(sheet.Cell(1, 0).Value, qt.Equals, "19.99")
+	c.Assert(sheet.Cell(1, 1).Type(), qt.Not(qt.Equals), CellTypeNumeric)
+	c.Assert(sheet.Cell(1, 1).Value, qt.Equals, "007")
+}
+
+func TestControlCharacterMode(t *testing.T) {
+	c := qt.New(t)
+
+	write := func(mode ControlCharacterMode, row []string, cells []StreamCell) ([]byte, error) {
+		buffer := bytes.NewBuffer(nil)
+		fileBuilder := NewStreamFileBuilder(buffer)
+		c.Assert(fileBuilder.AddSheet("Sheet1", []*CellType{CellTypeString.Ptr(), CellTypeString.Ptr()}), qt.IsNil)
+		c.Assert(fileBuilder.SetControlCharacterMode(mode), qt.IsNil)
+		streamFile, err := fileBuilder.Build()
+		c.Assert(err, qt.IsNil)
+		if err := streamFile.Write(row); err != nil {
+			return nil, err
+		}
+		if err := streamFile.WriteS(cells); err != nil {
+			return nil, err
+		}
+		c.Assert(streamFile.Close(), qt.IsNil)
+		return buffer.Bytes(), nil
+	}
+	row := []string{"a\x01b", "tab\tok"}
+	cells := []StreamCell{
+		NewStreamCell("bell\x07", StreamStyle{}, CellTypeString),
+		NewStreamCell("_x0041_", StreamStyle{}, CellTypeString),
+	}
+
+	c.Run("Strip", func(c *qt.C) {
+		data, err := write(StripControlCharacters, row, cells)
+		c.Assert(err, qt.IsNil)
+		file, err := OpenBinary(data)
+		c.Assert(err, qt.IsNil)
+		sheet := file.Sheets[0]
+		c.Assert(sheet.Cell(0, 0).Value, qt.Equals, "ab")
+		c.Assert(sheet.Cell(0, 1).Value, qt.Equals, "tab\tok")
+		c.Assert(sheet.Cell(1, 0).Value, qt.Equals, "bell")
+		c.Assert(sheet.Cell(1, 1).Value, qt.Equals, "_x0041_")
+	})
+
+	c.Run("Escape", func(c *qt.C) {
+		data, err := write(EscapeControlCharacters, row, cells)
+		c.Assert(err, qt.IsNil)
+		sheetXML := unzipParts(c, data)["xl/worksheets/sheet1.xml"]
+		c.Assert(sheetXML, qt.Contains, `<t>a_x0001_b</t>`)
+		c.Assert(sheetXML, qt.Contains, `<t>bell_x0007_</t>`)
+		c.Assert(sheetXML, qt.Contains, `<t>_x005F_x0041_</t>`)
+		_, err = OpenBinary(data)
+		c.Assert(err, qt.IsNil)
+	})
+
+	c.Run("Error", func(c *qt.C) {
+		_, err := write(ErrorOnControlCharacters, row, cells)
+		c.Assert(err, qt.ErrorMatches, `cell A1: the string holds the character U\+0001, which cannot be written to XML`)
+		_, err = write(ErrorOnControlCharacters, []string{"a", "b"}, cells)
+		c.Assert(err, qt.ErrorMatches, `cell A2: the string holds the character U\+0007, which cannot be written to XML`)
+	})
+}
+
+func TestSetQuotePrefix(t *testing.T) {
+	c := qt.New(t)
+
+	write := func(quotePrefix bool) *Sheet {
+		buffer := bytes.NewBuffer(nil)
+		fileBuilder := NewStreamFileBuilder(buffer)
+		c.Assert(fileBuilder.AddStreamStyle(StreamStyleBoldString), qt.IsNil)
+		c.Assert(fileBuilder.AddSheet("Sheet1", []*CellType{CellTypeString.Ptr(), CellTypeString.Ptr()}), qt.IsNil)
+		c.Assert(fileBuilder.SetQuotePrefix(quotePrefix), qt.IsNil)
+		streamFile, err := fileBuilder.Build()
+		c.Assert(err, qt.IsNil)
+		c.Assert(streamFile.WriteS([]StreamCell{
+			NewStreamCell("'0123", StreamStyle{}, CellTypeString),
+			NewStyledStringStreamCell("'=1+1", StreamStyleBoldString),
+		}), qt.IsNil)
+		c.Assert(streamFile.WriteS([]StreamCell{
+			NewStreamCell("plain", StreamStyle{}, CellTypeString),
+			NewStyledStringStreamCell("it's", StreamStyleBoldString),
+		}), qt.IsNil)
+		c.Assert(streamFile.Close(), qt.IsNil)
+		file, err := OpenBinary(buffer.Bytes())
+		c.Assert(err, qt.IsNil)
+		return file.Sheets[0]
+	}
+
+	c.Run("Literal", func(c *qt.C) {
+		sheet := write(false)
+		c.Assert(sheet.Cell(0, 0).Value, qt.Equals, "'0123")
+		c.Assert(sheet.Cell(0, 0).GetStyle().QuotePrefix, qt.Equals, false)
+		c.Assert(sheet.Cell(0, 1).Value, qt.Equals, "'=1+1")
+	})
+
+	c.Run("QuotePrefix", func(c *qt.C) {
+		sheet := write(true)
+		c.Assert(sheet.Cell(0, 0).Value, qt.Equals, "0123")
+		c.Assert(sheet.Cell(0, 0).GetStyle().QuotePrefix, qt.Equals, true)
+		c.Assert(sheet.Cell(0, 1).Value, qt.Equals, "=1+1")
+		c.Assert(sheet.Cell(0, 1).GetStyle().QuotePrefix, qt.Equals, true)
+		c.Assert(sheet.Cell(0, 1).GetStyle().Font.Bold, qt.Equals, true)
+		c.Assert(sheet.Cell(1, 0).GetStyle().QuotePrefix, qt.Equals, false)
+		c.Assert(sheet.Cell(1, 1).Value, qt.Equals, "it's")
+		c.Assert(sheet.Cell(1, 1).GetStyle().QuotePrefix, qt.Equals, false)
+		c.Assert(sheet.Cell(1, 1).GetStyle().Font.Bold, qt.Equals, true)
+	})
+}
+
+func TestSetAlignByType(t *testing.T) {
+	c := qt.New(t)
+
+	centered := DefaultAlignment()
+	centered.Horizontal = "center"
+	centeredString := MakeStringStyle(DefaultFont(), DefaultFill(), centered, DefaultBorder())
+
+	write := func(alignByType bool) *Sheet {
+		buffer := bytes.NewBuffer(nil)
+		fileBuilder := NewStreamFileBuilder(buffer)
+		c.Assert(fileBuilder.AddStreamStyle(StreamStyleDefaultString), qt.IsNil)
+		c.Assert(fileBuilder.AddStreamStyle(StreamStyleDefaultInteger), qt.IsNil)
+		c.Assert(fileBuilder.AddStreamStyle(centeredString), qt.IsNil)
+		c.Assert(fileBuilder.AddSheetS("Sheet1", []StreamStyle{StreamStyleDefaultString, StreamStyleDefaultString, StreamStyleDefaultString, StreamStyleDefaultString}), qt.IsNil)
+		c.Assert(fileBuilder.SetAlignByType(alignByType), qt.IsNil)
+		streamFile, err := fileBuilder.Build()
+		c.Assert(err, qt.IsNil)
+		c.Assert(streamFile.WriteS([]StreamCell{
+			NewStringStreamCell("text"),
+			NewIntegerStreamCell(42),
+			NewStreamCell("1.5", StreamStyle{}, CellTypeNumeric),
+			NewStyledStringStreamCell("centered", centeredString),
+		}), qt.IsNil)
+		c.Assert(streamFile.Close(), qt.IsNil)
+		file, err := OpenBinary(buffer.Bytes())
+		c.Assert(err, qt.IsNil)
+		return file.Sheets[0]
+	}
+
+	c.Run("Enabled", func(c *qt.C) {
+		sheet := write(true)
+		c.Assert(sheet.Cell(0, 0).GetStyle().Alignment.Horizontal, qt.Equals, "left")
+		c.Assert(sheet.Cell(0, 1).GetStyle().Alignment.Horizontal, qt.Equals, "right")
+		c.Assert(sheet.Cell(0, 1).Value, qt.Equals, "42")
+		c.Assert(sheet.Cell(0, 2).GetStyle().Alignment.Horizontal, qt.Equals, "right")
+		c.Assert(sheet.Cell(0, 3).GetStyle().Alignment.Horizontal, qt.Equals, "center")
+	})
+
+	c.Run("Disabled", func(c *qt.C) {
+		sheet := write(false)
+		c.Assert(sheet.Cell(0, 0).GetStyle().Alignment.Horizontal, qt.Equals, "general")
+		c.Assert(sheet.Cell(0, 1).GetStyle().Alignment.Horizontal, qt.Equals, "general")
+		c.Assert(sheet.Cell(0, 3).GetStyle().Alignment.Horizontal, qt.Equals, "center")
+	})
+}
+
+func TestNewStyledHyperlinkStreamCell(t *testing.T) {
+	c := qt.New(t)
+
+	linkStyle := MakeStringStyle(FontUnderlined, FillGreen, DefaultAlignment(), DefaultBorder())
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddStreamStyle(StreamStyleDefaultString), qt.IsNil)
+	c.Assert(fileBuilder.AddStreamStyle(linkStyle), qt.IsNil)
+	c.Assert(fileBuilder.AddSheetS("Links", []StreamStyle{StreamStyleDefaultString, StreamStyleDefaultString}), qt.IsNil)
+	c.Assert(fileBuilder.AddSheetS("Plain", []StreamStyle{StreamStyleDefaultString}), qt.IsNil)
+	c.Assert(fileBuilder.SetSheetBackground(0, []byte("\x89PNG"), "png"), qt.IsNil)
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	c.Assert(streamFile.WriteS([]StreamCell{
+		NewStringStreamCell("home"),
+		NewStyledHyperlinkStreamCell("Example", "https://example.com/?a=1&b=2", linkStyle),
+	}), qt.IsNil)
+	c.Assert(streamFile.WriteS([]StreamCell{
+		NewStyledHyperlinkStreamCell("Docs", "https://example.com/docs", StreamStyleDefaultString),
+		NewStringStreamCell("plain"),
+	}), qt.IsNil)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	parts := unzipParts(c, buffer.Bytes())
+	c.Assert(parts["xl/worksheets/sheet1.xml"], qt.Contains, `<hyperlinks><hyperlink ref="B1" r:id="rId2"></hyperlink><hyperlink ref="A2" r:id="rId3"></hyperlink></hyperlinks><printOptions`)
+	// The relation of the background is kept along with those of the links.
+	c.Assert(parts["xl/worksheets/_rels/sheet1.xml.rels"], qt.Contains, `Id="rId1"`)
+	c.Assert(parts["xl/worksheets/sheet2.xml"], qt.Not(qt.Contains), `<hyperlinks>`)
+	_, ok := parts["xl/worksheets/_rels/sheet2.xml.rels"]
+	c.Assert(ok, qt.Equals, false)
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	sheet := file.Sheets[0]
+	cell := sheet.Cell(0, 1)
+	c.Assert(cell.Value, qt.Equals, "Example")
+	c.Assert(cell.Hyperlink.Link, qt.Equals, "https://example.com/?a=1&b=2")
+	c.Assert(cell.GetStyle().Font.Underline, qt.Equals, true)
+	c.Assert(cell.GetStyle().Fill.FgColor, qt.Equals, RGB_Light_Green)
+	c.Assert(sheet.Cell(1, 0).Hyperlink.Link, qt.Equals, "https://example.com/docs")
+	c.Assert(sheet.Cell(1, 1).Hyperlink.Link, qt.Equals, "")
+}
+
+func TestStreamHyperlinkTargets(t *testing.T) {
+	c := qt.New(t)
+
+	parts, file := streamRows(c, nil, []StreamCell{
+		NewHyperlinkStreamCell("Search", "https://example.com/search?q=a&page=2", StreamStyleDefaultString),
+		NewHyperlinkStreamCell("Report", "reports/2020.html", StreamStyleDefaultString),
+	})
+	// The display text is written in the cell as an inline string.
+	c.Assert(parts["xl/worksheets/sheet1.xml"], qt.Contains, `<c r="A1" s="1" t="inlineStr"><is><t>Search</t></is></c>`)
+	rels := parts["xl/worksheets/_rels/sheet1.xml.rels"]
+	c.Assert(rels, qt.Contains, `Target="https://example.com/search?q=a&amp;page=2" TargetMode="External"`)
+	// Relative targets are left to be resolved by the application.
+	c.Assert(rels, qt.Contains, `Target="reports/2020.html" TargetMode="External"`)
+
+	sheet := file.Sheets[0]
+	c.Assert(sheet.Cell(0, 0).Hyperlink.Link, qt.Equals, "https://example.com/search?q=a&page=2")
+	c.Assert(sheet.Cell(0, 1).Value, qt.Equals, "Report")
+	c.Assert(sheet.Cell(0, 1).Hyperlink.Link, qt.Equals, "reports/2020.html")
+}
+
+func TestSetAbsoluteLinkTargets(t *testing.T) {
+	c := qt.New(t)
+
+	write := func(absolute bool, target string) (map[string]string, error) {
+		buffer := bytes.NewBuffer(nil)
+		fileBuilder := NewStreamFileBuilder(buffer)
+		c.Assert(fileBuilder.AddStreamStyle(StreamStyleDefaultString), qt.IsNil)
+		c.Assert(fileBuilder.AddSheetS("Links", []StreamStyle{StreamStyleDefaultString}), qt.IsNil)
+		c.Assert(fileBuilder.SetAbsoluteLinkTargets(absolute), qt.IsNil)
+		streamFile, err := fileBuilder.Build()
+		c.Assert(err, qt.IsNil)
+		if err := streamFile.WriteS([]StreamCell{NewStyledHyperlinkStreamCell("link", target, StreamStyleDefaultString)}); err != nil {
+			return nil, err
+		}
+		c.Assert(streamFile.Close(), qt.IsNil)
+		return unzipParts(c, buffer.Bytes()), nil
+	}
+
+	parts, err := write(true, "https://example.com/a")
+	c.Assert(err, qt.IsNil)
+	c.Assert(parts["xl/worksheets/_rels/sheet1.xml.rels"], qt.Contains, `Target="https://example.com/a" TargetMode="External"`)
+	// Relations to parts of the package have no target mode at all.
+	c.Assert(parts["xl/_rels/workbook.xml.rels"], qt.Not(qt.Contains), `TargetMode`)
+
+	parts, err = write(false, "a.html")
+	c.Assert(err, qt.IsNil)
+	c.Assert(parts["xl/worksheets/_rels/sheet1.xml.rels"], qt.Contains, `Target="a.html" TargetMode="External"`)
+
+	_, err = write(true, "a.html")
+	c.Assert(err, qt.ErrorMatches, `cell A1: the hyperlink target "a.html" is not an absolute URI`)
+}
+
+func TestStreamPageBreaks(t *testing.T) {
+	c := qt.New(t)
+
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddSheet("Sheet1", []*CellType{CellTypeString.Ptr(), CellTypeString.Ptr()}), qt.IsNil)
+	c.Assert(fileBuilder.AddRowBreak(0, 1), qt.IsNil)
+	c.Assert(fileBuilder.AddColBreak(0, 0), qt.IsNil)
+	c.Assert(fileBuilder.AddRowBreak(1, 1), qt.ErrorMatches, "sheet index out of range")
+	c.Assert(fileBuilder.AddColBreak(0, -1), qt.ErrorMatches, "column index out of range")
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	for i := 0; i < 4; i++ {
+		c.Assert(streamFile.Write([]string{"a", "b"}), qt.IsNil)
+	}
+	c.Assert(streamFile.Close(), qt.IsNil)
+	c.Assert(fileBuilder.AddRowBreak(0, 2), qt.Equals, BuiltStreamFileBuilderError)
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	sheet := file.Sheets[0]
+	c.Assert(sheet.RowBreaks(), qt.DeepEquals, []int{1})
+	c.Assert(sheet.ColBreaks(), qt.DeepEquals, []int{0})
+	c.Assert(sheet.MaxRow, qt.Equals, 4)
+}
+
+func TestWriteSWithHeight(t *testing.T) {
+	c := qt.New(t)
+
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddStreamStyle(StreamStyleDefaultString), qt.IsNil)
+	c.Assert(fileBuilder.AddSheetS("Sheet1", []StreamStyle{StreamStyleDefaultString}), qt.IsNil)
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	c.Assert(streamFile.WriteSWithHeight([]StreamCell{NewStringStreamCell("header")}, 31.5), qt.IsNil)
+	c.Assert(streamFile.WriteSWithHeight([]StreamCell{NewStringStreamCell("plain")}, 0), qt.IsNil)
+	c.Assert(streamFile.WriteSWithHeight([]StreamCell{NewStringStreamCell("bad")}, -1), qt.ErrorMatches, "the row height must not be negative")
+	c.Assert(streamFile.WriteS([]StreamCell{NewStringStreamCell("after")}), qt.IsNil)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	sheetXML := unzipParts(c, buffer.Bytes())["xl/worksheets/sheet1.xml"]
+	c.Assert(sheetXML, qt.Contains, `<row r="1" ht="31.5" customHeight="1">`)
+	c.Assert(sheetXML, qt.Contains, `<row r="2">`)
+	c.Assert(sheetXML, qt.Contains, `<row r="3">`)
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	sheet := file.Sheets[0]
+	c.Assert(sheet.Row(0).GetHeight(), qt.Equals, 31.5)
+	c.Assert(sheet.Row(1).GetHeight(), qt.Equals, sheet.SheetFormat.DefaultRowHeight)
+	c.Assert(sheet.Cell(2, 0).Value, qt.Equals, "after")
+}
+
+func TestWriteSWithOutline(t *testing.T) {
+	c := qt.New(t)
+
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddStreamStyle(StreamStyleDefaultString), qt.IsNil)
+	c.Assert(fileBuilder.AddSheetS("Sheet1", []StreamStyle{StreamStyleDefaultString}), qt.IsNil)
+	c.Assert(fileBuilder.SetMaxRowOutlineLevel(0, 8), qt.ErrorMatches, "invalid outline level 8, it must be from 0 to 7")
+	c.Assert(fileBuilder.SetMaxRowOutlineLevel(0, 2), qt.IsNil)
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	c.Assert(fileBuilder.SetMaxRowOutlineLevel(0, 1), qt.Equals, BuiltStreamFileBuilderError)
+	row := func(value string) []StreamCell {
+		return []StreamCell{NewStringStreamCell(value)}
+	}
+	c.Assert(streamFile.WriteSWithOutline(row("bad"), 3, false), qt.ErrorMatches, "invalid outline level 3, the sheet allows levels from 0 to 2")
+	c.Assert(streamFile.WriteSWithOutline(row("bad"), 0, true), qt.ErrorMatches, "a row must be grouped to be collapsed")
+	c.Assert(streamFile.WriteSWithOutline(row("north"), 1, false), qt.IsNil)
+	c.Assert(streamFile.WriteSWithOutline(row("south"), 1, false), qt.IsNil)
+	c.Assert(streamFile.WriteS(row("subtotal")), qt.IsNil)
+	c.Assert(streamFile.WriteSWithOutline(row("east"), 2, true), qt.IsNil)
+	c.Assert(streamFile.WriteSWithOutline(row("west"), 2, true), qt.IsNil)
+	c.Assert(streamFile.WriteSWithOutline(row("subtotal"), 1, false), qt.IsNil)
+	c.Assert(streamFile.WriteS(row("total")), qt.IsNil)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	sheetXML := unzipParts(c, buffer.Bytes())["xl/worksheets/sheet1.xml"]
+	c.Assert(sheetXML, qt.Contains, `outlineLevelRow="2"`)
+	c.Assert(sheetXML, qt.Contains, `<row r="1" outlineLevel="1">`)
+	c.Assert(sheetXML, qt.Contains, `<row r="3">`)
+	c.Assert(sheetXML, qt.Contains, `<row r="4" hidden="1" outlineLevel="2">`)
+	c.Assert(sheetXML, qt.Contains, `<row r="6" outlineLevel="1" collapsed="1">`)
+	c.Assert(sheetXML, qt.Contains, `<row r="7">`)
+	c.Assert(strings.Contains(sheetXML, "outlinePr"), qt.Equals, false)
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	sheet := file.Sheets[0]
+	c.Assert(sheet.SheetFormat.OutlineLevelRow, qt.Equals, uint8(2))
+	for i, level := range []uint8{1, 1, 0, 2, 2, 1, 0} {
+		c.Assert(sheet.Row(i).OutlineLevel, qt.Equals, level)
+	}
+	c.Assert(sheet.Row(3).Hidden, qt.Equals, true)
+	c.Assert(sheet.Row(5).Collapsed, qt.Equals, true)
+	c.Assert(sheet.Row(6).Collapsed, qt.Equals, false)
+}
+
+func TestSetProgressCallback(t *testing.T) {
+	c := qt.New(t)
+
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddStreamStyle(StreamStyleDefaultString), qt.IsNil)
+	c.Assert(fileBuilder.AddSheet("Sheet1", []*CellType{CellTypeString.Ptr()}), qt.IsNil)
+	c.Assert(fileBuilder.AddSheet("Sheet2", []*CellType{CellTypeString.Ptr()}), qt.IsNil)
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	c.Assert(streamFile.WriteS([]StreamCell{NewStringStreamCell("before")}), qt.IsNil)
+
+	var calls [][2]int
+	streamFile.SetProgressCallback(func(rowsWritten, sheetsWritten int) {
+		calls = append(calls, [2]int{rowsWritten, sheetsWritten})
+		// Writing from the callback doesn't call it again.
+		if rowsWritten == 3 {
+			c.Assert(streamFile.WriteS([]StreamCell{NewStringStreamCell("from callback")}), qt.IsNil)
+		}
+	})
+	c.Assert(streamFile.WriteS([]StreamCell{NewStringStreamCell("a")}), qt.IsNil)
+	c.Assert(streamFile.WriteAllS([][]StreamCell{{NewStringStreamCell("b")}}), qt.IsNil)
+	c.Assert(streamFile.NextSheet(), qt.IsNil)
+	c.Assert(streamFile.Write([]string{"c"}), qt.IsNil)
+	c.Assert(streamFile.WriteAll([][]string{{"d"}, {"e"}}), qt.IsNil)
+	streamFile.SetProgressCallback(nil)
+	c.Assert(streamFile.WriteS([]StreamCell{NewStringStreamCell("f")}), qt.IsNil)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	c.Assert(calls, qt.DeepEquals, [][2]int{{2, 0}, {3, 0}, {5, 1}, {7, 1}})
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	c.Assert(file.Sheets[0].Cell(3, 0).Value, qt.Equals, "from callback")
+	c.Assert(file.Sheets[1].MaxRow, qt.Equals, 4)
+}
+
+func TestRegisterMerge(t *testing.T) {
+	c := qt.New(t)
+
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddSheet("Report", []*CellType{CellTypeString.Ptr(), CellTypeString.Ptr(), CellTypeString.Ptr()}), qt.IsNil)
+	c.Assert(fileBuilder.RegisterMerge(0, "A1:C1"), qt.IsNil)
+	c.Assert(fileBuilder.RegisterMerge(0, "A2:A3"), qt.IsNil)
+	c.Assert(fileBuilder.RegisterMerge(0, "B1:B2"), qt.ErrorMatches, `the merge range "B1:B2" overlaps "A1:C1"`)
+	c.Assert(fileBuilder.RegisterMerge(0, "C2:D2"), qt.ErrorMatches, `the merge range "C2:D2" lies beyond the columns of the sheet`)
+	c.Assert(fileBuilder.RegisterMerge(0, "B3:B3"), qt.ErrorMatches, `the merge range "B3:B3" covers a single cell`)
+	c.Assert(fileBuilder.RegisterMerge(0, "B3"), qt.ErrorMatches, `invalid merge range "B3"`)
+	c.Assert(fileBuilder.RegisterMerge(1, "A1:B1"), qt.ErrorMatches, "sheet index out of range")
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	c.Assert(streamFile.WriteAll([][]string{
+		{"Title", "", ""},
+		{"Group", "a", "b"},
+		{"", "c", "d"},
+	}), qt.IsNil)
+	streamFile.AddMergeCells(1, 1, 1, 2)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	sheetXML := unzipParts(c, buffer.Bytes())["xl/worksheets/sheet1.xml"]
+	c.Assert(sheetXML, qt.Contains, `</sheetData><mergeCells count="3"><mergeCell ref="A1:C1"/><mergeCell ref="A2:A3"/><mergeCell ref="B2:C2"/></mergeCells>`)
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	sheet := file.Sheets[0]
+	c.Assert(sheet.Cell(0, 0).HMerge, qt.Equals, 2)
+	c.Assert(sheet.Cell(0, 0).VMerge, qt.Equals, 0)
+	c.Assert(sheet.Cell(1, 0).HMerge, qt.Equals, 0)
+	c.Assert(sheet.Cell(1, 0).VMerge, qt.Equals, 1)
+	c.Assert(sheet.Cell(1, 1).HMerge, qt.Equals, 1)
+}
+
+func TestStreamSheetElementOrder(t *testing.T) {
+	c := qt.New(t)
+
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddSheet("Report", []*CellType{CellTypeString.Ptr(), CellTypeString.Ptr(), CellTypeString.Ptr()}), qt.IsNil)
+	c.Assert(fileBuilder.SetAutoFilter(0, "A2:C2"), qt.IsNil)
+	c.Assert(fileBuilder.RegisterMerge(0, "A1:C1"), qt.IsNil)
+	validation := NewDataValidation(0, 0, 0, 0, true)
+	c.Assert(validation.SetDropList([]string{"a", "b"}), qt.IsNil)
+	c.Assert(fileBuilder.AddDataValidation(0, "B3:B10", validation), qt.IsNil)
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	c.Assert(streamFile.WriteAll([][]string{
+		{"Title", "", ""},
+		{"Name", "Kind", "Notes"},
+		{"Ann", "a", ""},
+	}), qt.IsNil)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	// The elements after the rows are in the order of the schema.
+	sheetXML := unzipParts(c, buffer.Bytes())["xl/worksheets/sheet1.xml"]
+	last := -1
+	for _, tag := range []string{"</sheetData>", "<autoFilter", "<mergeCells", "<dataValidations", "<printOptions"} {
+		i := strings.Index(sheetXML, tag)
+		c.Assert(i > last, qt.Equals, true, qt.Commentf("%s in %s", tag, sheetXML))
+		last = i
+	}
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	c.Assert(file.Sheets[0].Cell(0, 0).HMerge, qt.Equals, 2)
+	c.Assert(file.Sheets[0].AutoFilter, qt.DeepEquals, &AutoFilter{TopLeftCell: "A2", BottomRightCell: "C2"})
+}
+
+func TestNewFloatStreamCell(t *testing.T) {
+	c := qt.New(t)
+
+	// The sum is worked out at run time, as the constant 0.1+0.2 is
+	// exactly 0.3.
+	a, b := 0.1, 0.2
+	parts, file := streamRows(c, nil, []StreamCell{
+		NewFloatStreamCell(a+b, StreamStyleDefaultString),
+		NewFloatStreamCell(-1234.5678, StreamStyleDefaultDecimal),
+		NewFloatStreamCellWithPrecision(2.0/3, 4, StreamStyleDefaultString),
+		NewFloatStreamCell(1e21, StreamStyleDefaultString),
+	})
+	c.Assert(parts["xl/worksheets/sheet1.xml"], qt.Matches, `(?s).*<c r="A1" s="\d+" t="n"><v>0.30000000000000004</v></c><c r="B1" s="\d+" t="n"><v>-1234.5678</v></c><c r="C1" s="\d+" t="n"><v>0.6667</v></c><c r="D1" s="\d+" t="n"><v>1e\+21</v></c>.*`)
+
+	sheet := file.Sheets[0]
+	value, err := sheet.Cell(0, 0).Float()
+	c.Assert(err, qt.IsNil)
+	c.Assert(value, qt.Equals, a+b)
+	value, err = sheet.Cell(0, 1).Float()
+	c.Assert(err, qt.IsNil)
+	c.Assert(value, qt.Equals, -1234.5678)
+	formatted, err := sheet.Cell(0, 1).FormattedValue()
+	c.Assert(err, qt.IsNil)
+	c.Assert(formatted, qt.Equals, "-1234.57")
+
+	// Numbers that are not finite are written as errors.
+	parts, file = streamRows(c, nil, []StreamCell{
+		NewFloatStreamCell(math.NaN(), StreamStyleDefaultDecimal),
+		NewFloatStreamCellWithPrecision(math.Inf(1), 4, StreamStyleDefaultDecimal),
+		valueStreamCell(float32(math.Inf(-1))),
+	})
+	c.Assert(strings.Count(parts["xl/worksheets/sheet1.xml"], `t="e"><v>#NUM!</v>`), qt.Equals, 3)
+	c.Assert(file.Sheets[0].Cell(0, 0).Type(), qt.Equals, CellTypeError)
+}
+
+func TestNewZonedDateTimeStreamCell(t *testing.T) {
+	c := qt.New(t)
+
+	location := time.FixedZone("IST", 5*60*60+30*60)
+	logged := time.Date(2020, time.March, 1, 9, 15, 0, 0, location)
+
+	_, file := streamRows(c, nil, []StreamCell{NewZonedDateTimeStreamCell(logged), NewZoneStreamCell(logged)})
+	sheet := file.Sheets[0]
+	// The serial holds the wall clock time in the zone of the time,
+	// not the time in UTC, which is 03:45.
+	stored, err := sheet.Cell(0, 0).GetTime(false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(stored.Round(time.Second), qt.Equals, time.Date(2020, time.March, 1, 9, 15, 0, 0, time.UTC))
+	c.Assert(sheet.Cell(0, 0).GetNumberFormat(), qt.Equals, "m/d/yy h:mm")
+	formatted, err := sheet.Cell(0, 0).FormattedValue()
+	c.Assert(err, qt.IsNil)
+	c.Assert(formatted, qt.Equals, "3/1/20 09:15")
+	c.Assert(sheet.Cell(0, 1).Value, qt.Equals, "IST +05:30")
+}
+
+func TestAddSheetSWithState(t *testing.T) {
+	c := qt.New(t)
+
+	buffer := bytes.NewBuffer(nil)
+	fileBuilder := NewStreamFileBuilder(buffer)
+	c.Assert(fileBuilder.AddStreamStyle(StreamStyleDefaultString), qt.IsNil)
+	styles := []StreamStyle{StreamStyleDefaultString}
+	c.Assert(fileBuilder.AddSheetS("Report", styles), qt.IsNil)
+	c.Assert(fileBuilder.AddSheetSWithState("Lookup", styles, SheetStateHidden), qt.IsNil)
+	c.Assert(fileBuilder.AddSheetSWithState("Settings", styles, SheetStateVeryHidden), qt.IsNil)
+	c.Assert(fileBuilder.AddSheetSWithState("Other", styles, "secret"), qt.ErrorMatches, `invalid sheet state "secret"`)
+	streamFile, err := fileBuilder.Build()
+	c.Assert(err, qt.IsNil)
+	c.Assert(streamFile.Close(), qt.IsNil)
+
+	workbookXML := unzipParts(c, buffer.Bytes())["xl/workbook.xml"]
+	c.Assert(workbookXML, qt.Contains, `<sheet name="Lookup" sheetId="2" r:id="rId2" state="hidden">`)
+	c.Assert(workbookXML, qt.Contains, `<sheet name="Settings" sheetId="3" r:id="rId3" state="veryHidden">`)
+
+	file, err := OpenBinary(buffer.Bytes())
+	c.Assert(err, qt.IsNil)
+	c.Assert(file.Sheets, qt.HasLen, 3)
+	c.Assert(file.Sheets[0].Hidden, qt.Equals, false)
+	c.Assert(file.Sheets[1].Hidden, qt.Equals, true)
+	c.Assert(file.Sheets[1].State(), qt.Equals, SheetStateHidden)
+	c.Assert(file.Sheets[2].Hidden, qt.Equals, true)
+	c.Assert(file.Sheets[2].State(), qt.Equals, SheetStateVeryHidden)
+
+	// A workbook needs a visible sheet.
+	fileBuilder = NewStreamFileBuilder(bytes.NewBuffer(nil))
+	c.Assert(fileBuilder.AddSheetSWithState("Hidden", nil, SheetStateHidden), qt.IsNil)
+	_, err = fileBuilder.Build()
+	c.Assert(err, qt.ErrorMatches, "at least one sheet of the workbook has to be visible")
+
+	// Sheet names still have to be unique.
+	fileBuilder = NewStreamFileBuilder(bytes.NewBuffer(nil))
+	c.Assert(fileBuilder.AddSheetS("Lookup", nil), qt.IsNil)
+	c.Assert(fileBuilder.AddSheetSWithState("Lookup", nil, SheetStateHidden), qt.ErrorMatches, "duplicate sheet name 'Lookup'.")
+}
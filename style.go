@@ -37,6 +37,10 @@ type Style struct {
 	ApplyAlignment  bool
 	Alignment       Alignment
 	NamedStyleIndex *int
+	// QuotePrefix marks the value of the cell as text, as typing it
+	// with a leading apostrophe does, so that "0123" is not taken for
+	// a number.
+	QuotePrefix bool
 }
 
 // Return a new Style structure initialised with the default values.
@@ -96,6 +100,12 @@ func (style *Style) makeXLSXStyleElements() (xFont xlsxFont, xFill xlsxFill, xBo
 		Style: style.Border.Bottom,
 		Color: xlsxColor{RGB: style.Border.BottomColor},
 	}
+	xBorder.Diagonal = xlsxLine{
+		Style: style.Border.Diagonal,
+		Color: xlsxColor{RGB: style.Border.DiagonalColor},
+	}
+	xBorder.DiagonalUp = style.Border.DiagonalUp
+	xBorder.DiagonalDown = style.Border.DiagonalDown
 	xCellXf = makeXLSXCellElement()
 	xCellXf.ApplyBorder = style.ApplyBorder
 	xCellXf.ApplyFill = style.ApplyFill
@@ -104,6 +114,7 @@ func (style *Style) makeXLSXStyleElements() (xFont xlsxFont, xFill xlsxFill, xBo
 	if style.NamedStyleIndex != nil {
 		xCellXf.XfId = style.NamedStyleIndex
 	}
+	xCellXf.QuotePrefix = style.QuotePrefix
 	return
 }
 
@@ -123,6 +134,14 @@ type Border struct {
 	TopColor    string
 	Bottom      string
 	BottomColor string
+	// Diagonal and DiagonalColor are the style and color of the
+	// diagonal lines across the cell, which run from the bottom left
+	// corner to the top right if DiagonalUp is set, and from the top
+	// left corner to the bottom right if DiagonalDown is set.
+	Diagonal      string
+	DiagonalColor string
+	DiagonalUp    bool
+	DiagonalDown  bool
 }
 
 func NewBorder(left, right, top, bottom string) *Border {
@@ -165,8 +184,29 @@ func NewFont(size int, name string) *Font {
 	return &Font{Size: size, Name: name}
 }
 
+// The horizontal alignments of the text of a cell, see
+// Alignment.Horizontal.  Justify spreads each line but the last over
+// the width of the cell, and Distributed also spreads the last line,
+// keeping the indent on both sides.
+const (
+	HorizontalGeneral          = "general"
+	HorizontalLeft             = "left"
+	HorizontalCenter           = "center"
+	HorizontalRight            = "right"
+	HorizontalFill             = "fill"
+	HorizontalJustify          = "justify"
+	HorizontalCenterContinuous = "centerContinuous"
+	HorizontalDistributed      = "distributed"
+)
+
+// maxIndent is the deepest level of indentation of the text of a cell.
+const maxIndent = 15
+
 type Alignment struct {
-	Horizontal   string
+	Horizontal string
+	// Indent is the level of indentation of the text, from 0 to 15,
+	// each level being as wide as three spaces.  Levels out of that
+	// range are taken as the nearest one when the file is written.
 	Indent       int
 	ShrinkToFit  bool
 	TextRotation int
@@ -174,6 +214,17 @@ type Alignment struct {
 	WrapText     bool
 }
 
+// NewAlignment creates an Alignment with the given horizontal and
+// vertical alignment, such as "left" and "top", that wraps the text
+// of its cells over several lines if wrapText is set.
+func NewAlignment(horizontal, vertical string, wrapText bool) *Alignment {
+	return &Alignment{
+		Horizontal: horizontal,
+		Vertical:   vertical,
+		WrapText:   wrapText,
+	}
+}
+
 var defaultFontSize = 12
 var defaultFontName = "Verdana"
 
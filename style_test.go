@@ -1,6 +1,7 @@
 package xlsx
 
 import (
+	"bytes"
 	"testing"
 
 	qt "github.com/frankban/quicktest"
@@ -68,6 +69,202 @@ func TestReadCellColorBackground(t *testing.T) {
 	c.Assert(xFile.Sheets[0].Cell(2, 1).GetStyle().Fill, qt.Equals, *NewFill("solid", "FF990099", "00333333"))
 }
 
+func TestHorizontalAlignments(t *testing.T) {
+	c := qt.New(t)
+
+	alignments := []string{HorizontalJustify, HorizontalDistributed, HorizontalCenterContinuous}
+	check := func(c *qt.C, data []byte) {
+		file, err := OpenBinary(data)
+		c.Assert(err, qt.IsNil)
+		for col, horizontal := range alignments {
+			c.Assert(file.Sheets[0].Cell(0, col).GetStyle().Alignment.Horizontal, qt.Equals, horizontal)
+		}
+	}
+
+	c.Run("File", func(c *qt.C) {
+		file := NewFile()
+		sheet, err := file.AddSheet("Sheet1")
+		c.Assert(err, qt.IsNil)
+		for col, horizontal := range alignments {
+			cell := sheet.Cell(0, col)
+			cell.SetString("some text")
+			style := NewStyle()
+			style.Alignment.Horizontal = horizontal
+			style.ApplyAlignment = true
+			cell.SetStyle(style)
+		}
+		var buf bytes.Buffer
+		c.Assert(file.Write(&buf), qt.IsNil)
+		c.Assert(unzipParts(c, buf.Bytes())["xl/styles.xml"], qt.Contains, `<alignment horizontal="distributed"`)
+		check(c, buf.Bytes())
+	})
+
+	c.Run("Stream", func(c *qt.C) {
+		styles := make([]StreamStyle, len(alignments))
+		cells := make([]StreamCell, len(alignments))
+		for i, horizontal := range alignments {
+			styles[i] = MakeStringStyle(DefaultFont(), DefaultFill(), NewAlignment(horizontal, "bottom", false), DefaultBorder())
+			cells[i] = NewStyledStringStreamCell("some text", styles[i])
+		}
+		var buf bytes.Buffer
+		fileBuilder := NewStreamFileBuilder(&buf)
+		c.Assert(fileBuilder.AddStreamStyleList(styles), qt.IsNil)
+		c.Assert(fileBuilder.AddSheetS("Sheet1", styles), qt.IsNil)
+		streamFile, err := fileBuilder.Build()
+		c.Assert(err, qt.IsNil)
+		c.Assert(streamFile.WriteS(cells), qt.IsNil)
+		c.Assert(streamFile.Close(), qt.IsNil)
+		check(c, buf.Bytes())
+	})
+}
+
+// The alignment of a Style is written with applyAlignment set, as
+// Excel ignores it otherwise, so that it is read back as it was set.
+func TestAlignmentRoundTrip(t *testing.T) {
+	c := qt.New(t)
+	file := NewFile()
+	sheet, err := file.AddSheet("Sheet1")
+	c.Assert(err, qt.IsNil)
+	cell := sheet.Cell(0, 0)
+	cell.SetString("some text")
+	style := NewStyle()
+	style.Alignment.Horizontal = "center"
+	cell.SetStyle(style)
+	sheet.Cell(0, 1).SetString("more text")
+
+	var buf bytes.Buffer
+	c.Assert(file.Write(&buf), qt.IsNil)
+	file, err = OpenBinary(buf.Bytes())
+	c.Assert(err, qt.IsNil)
+	c.Assert(file.Sheets[0].Cell(0, 0).GetStyle().Alignment.Horizontal, qt.Equals, "center")
+	c.Assert(file.Sheets[0].Cell(0, 1).GetStyle().ApplyAlignment, qt.Equals, false)
+}
+
+func TestAlignmentIndent(t *testing.T) {
+	c := qt.New(t)
+
+	indents := []int{3, 20, -2}
+	want := []int{3, 15, 0}
+	check := func(c *qt.C, data []byte) {
+		file, err := OpenBinary(data)
+		c.Assert(err, qt.IsNil)
+		for col, indent := range want {
+			c.Assert(file.Sheets[0].Cell(0, col).GetStyle().Alignment.Indent, qt.Equals, indent)
+		}
+	}
+
+	c.Run("File", func(c *qt.C) {
+		file := NewFile()
+		sheet, err := file.AddSheet("Sheet1")
+		c.Assert(err, qt.IsNil)
+		for col, indent := range indents {
+			cell := sheet.Cell(0, col)
+			cell.SetString("item")
+			style := NewStyle()
+			style.Alignment.Horizontal = HorizontalLeft
+			style.Alignment.Indent = indent
+			style.ApplyAlignment = true
+			cell.SetStyle(style)
+		}
+		var buf bytes.Buffer
+		c.Assert(file.Write(&buf), qt.IsNil)
+		c.Assert(unzipParts(c, buf.Bytes())["xl/styles.xml"], qt.Contains, `<alignment horizontal="left" indent="15"`)
+		check(c, buf.Bytes())
+	})
+
+	c.Run("Stream", func(c *qt.C) {
+		styles := make([]StreamStyle, len(indents))
+		cells := make([]StreamCell, len(indents))
+		for i, indent := range indents {
+			alignment := NewAlignment(HorizontalLeft, "bottom", false)
+			alignment.Indent = indent
+			styles[i] = MakeStringStyle(DefaultFont(), DefaultFill(), alignment, DefaultBorder())
+			cells[i] = NewStyledStringStreamCell("item", styles[i])
+		}
+		var buf bytes.Buffer
+		fileBuilder := NewStreamFileBuilder(&buf)
+		c.Assert(fileBuilder.AddStreamStyleList(styles), qt.IsNil)
+		c.Assert(fileBuilder.AddSheetS("Sheet1", styles), qt.IsNil)
+		streamFile, err := fileBuilder.Build()
+		c.Assert(err, qt.IsNil)
+		c.Assert(streamFile.WriteS(cells), qt.IsNil)
+		c.Assert(streamFile.Close(), qt.IsNil)
+		check(c, buf.Bytes())
+	})
+}
+
+func TestDiagonalBorder(t *testing.T) {
+	c := qt.New(t)
+
+	border := NewBorder("thin", "thin", "thin", "thin")
+	border.Diagonal = "thin"
+	border.DiagonalColor = "FFFF0000"
+	border.DiagonalUp = true
+	border.DiagonalDown = true
+	check := func(c *qt.C, data []byte) {
+		c.Assert(unzipParts(c, data)["xl/styles.xml"], qt.Contains, `<border diagonalUp="1" diagonalDown="1"><left style="thin"></left><right style="thin"></right><top style="thin"></top><bottom style="thin"></bottom><diagonal style="thin"><color rgb="FFFF0000"/></diagonal></border>`)
+		file, err := OpenBinary(data)
+		c.Assert(err, qt.IsNil)
+		c.Assert(file.Sheets[0].Cell(0, 0).GetStyle().Border, qt.Equals, *border)
+		c.Assert(file.Sheets[0].Cell(0, 1).GetStyle().Border.DiagonalUp, qt.Equals, false)
+	}
+
+	c.Run("File", func(c *qt.C) {
+		file := NewFile()
+		sheet, err := file.AddSheet("Sheet1")
+		c.Assert(err, qt.IsNil)
+		style := NewStyle()
+		style.Border = *border
+		style.ApplyBorder = true
+		sheet.Cell(0, 0).SetString("n/a")
+		sheet.Cell(0, 0).SetStyle(style)
+		sheet.Cell(0, 1).SetString("plain")
+		var buf bytes.Buffer
+		c.Assert(file.Write(&buf), qt.IsNil)
+		check(c, buf.Bytes())
+	})
+
+	c.Run("Stream", func(c *qt.C) {
+		style := MakeStringStyle(DefaultFont(), DefaultFill(), DefaultAlignment(), border)
+		var buf bytes.Buffer
+		fileBuilder := NewStreamFileBuilder(&buf)
+		c.Assert(fileBuilder.AddStreamStyleList([]StreamStyle{style, StreamStyleDefaultString}), qt.IsNil)
+		c.Assert(fileBuilder.AddSheetS("Sheet1", []StreamStyle{style, StreamStyleDefaultString}), qt.IsNil)
+		streamFile, err := fileBuilder.Build()
+		c.Assert(err, qt.IsNil)
+		c.Assert(streamFile.WriteS([]StreamCell{
+			NewStyledStringStreamCell("n/a", style),
+			NewStringStreamCell("plain"),
+		}), qt.IsNil)
+		c.Assert(streamFile.Close(), qt.IsNil)
+		check(c, buf.Bytes())
+	})
+
+	c.Run("Merged", func(c *qt.C) {
+		file := NewFile()
+		sheet, err := file.AddSheet("Sheet1")
+		c.Assert(err, qt.IsNil)
+		style := NewStyle()
+		style.Border = *border
+		style.ApplyBorder = true
+		cell := sheet.Cell(0, 0)
+		cell.SetString("n/a")
+		cell.SetStyle(style)
+		cell.Merge(1, 0)
+		var buf bytes.Buffer
+		c.Assert(file.Write(&buf), qt.IsNil)
+		file, err = OpenBinary(buf.Bytes())
+		c.Assert(err, qt.IsNil)
+		// The covered cell keeps the outer edges, but the diagonals
+		// are drawn across the range from the first cell.
+		covered := file.Sheets[0].Cell(0, 1).GetStyle().Border
+		c.Assert(covered.Top, qt.Equals, "thin")
+		c.Assert(covered.Diagonal, qt.Equals, "")
+		c.Assert(covered.DiagonalUp, qt.Equals, false)
+		c.Assert(file.Sheets[0].Cell(0, 0).GetStyle().Border.DiagonalUp, qt.Equals, true)
+	})
+}
+
 type FontSuite struct{}
 
 var _ = Suite(&FontSuite{})
@@ -1,6 +1,7 @@
 package xlsx
 
 import (
+	"encoding/xml"
 	"fmt"
 	"strconv"
 )
@@ -45,3 +46,24 @@ func (t *theme) themeColor(index int64, tint float64) string {
 		return fmt.Sprintf("FF%02X%02X%02X", br, bg, bb)
 	}
 }
+
+// ThemePalette returns the 12 colors of the workbook's theme as ARGB
+// hex strings, in the order in which styles refer to them by index:
+// light 1, dark 1, light 2, dark 2, accents 1 to 6, hyperlink and
+// followed hyperlink.  Files that were not read from a workbook use
+// the default theme that is written out with them.
+func (f *File) ThemePalette() []string {
+	t := f.theme
+	if t == nil {
+		var themeXml xlsxTheme
+		if err := xml.Unmarshal([]byte(TEMPLATE_XL_THEME_THEME), &themeXml); err != nil {
+			return nil
+		}
+		t = newTheme(themeXml)
+	}
+	palette := make([]string, len(t.colors))
+	for i, color := range t.colors {
+		palette[i] = "FF" + color
+	}
+	return palette
+}
@@ -81,3 +81,15 @@ func (s *ThemeSuite) TestThemeColors(c *C) {
 	c.Assert(theme.themeColor(0, 0), Equals, "FFFFFFFF")
 	c.Assert(theme.themeColor(2, 0), Equals, "FFEEECE1")
 }
+
+func (s *ThemeSuite) TestThemePalette(c *C) {
+	expected := []string{"FFFFFFFF", "FF000000", "FFEEECE1", "FF1F497D", "FF4F81BD", "FFC0504D",
+		"FF9BBB59", "FF8064A2", "FF4BACC6", "FFF79646", "FF0000FF", "FF800080"}
+
+	f := NewFile()
+	c.Assert(f.ThemePalette(), DeepEquals, expected)
+
+	f, err := OpenFile("./testdocs/testfile.xlsx")
+	c.Assert(err, IsNil)
+	c.Assert(f.ThemePalette(), DeepEquals, expected)
+}
@@ -0,0 +1,117 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"strings"
+	"time"
+)
+
+const (
+	RelationshipTypeThreadedComment RelationshipType = "http://schemas.microsoft.com/office/2017/10/relationships/threadedComment"
+
+	personPathPrefix = "xl/persons/"
+)
+
+// threadedCommentTimeLayouts are the layouts the dT timestamps of
+// threaded comments are found in.  Excel writes them without a time
+// zone, in UTC.
+var threadedCommentTimeLayouts = []string{
+	"2006-01-02T15:04:05.999999999",
+	time.RFC3339Nano,
+}
+
+// ThreadedComment is a comment in the conversation attached to a Cell
+// by newer versions of Excel, which unlike a Comment can be replied to.
+type ThreadedComment struct {
+	// Author is the display name of the person who wrote the
+	// comment.
+	Author string
+	// Time is when the comment was written, or the zero Time if
+	// that was not recorded.
+	Time time.Time
+	Text string
+	// Done is set on the first comment of a thread once the thread
+	// has been resolved.
+	Done bool
+}
+
+// parseThreadedCommentTime parses the dT timestamp of a threaded
+// comment, returning the zero Time if it cannot be parsed.
+func parseThreadedCommentTime(dT string) time.Time {
+	for _, layout := range threadedCommentTimeLayouts {
+		if t, err := time.Parse(layout, dT); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// readPersonsFromZipFile is an internal helper function that reads the
+// people who wrote the threaded comments of the workbook, and returns
+// their display names keyed by their ids.
+func readPersonsFromZipFile(parts map[string]*zip.File) (map[string]string, error) {
+	var persons map[string]string
+	for name, part := range parts {
+		if !strings.HasPrefix(name, personPathPrefix) || !strings.HasSuffix(name, ".xml") {
+			continue
+		}
+		rc, err := part.Open()
+		if err != nil {
+			return nil, err
+		}
+		personList := new(xlsxPersonList)
+		err = xml.NewDecoder(rc).Decode(personList)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		if persons == nil {
+			persons = make(map[string]string)
+		}
+		for _, person := range personList.Person {
+			persons[person.Id] = person.DisplayName
+		}
+	}
+	return persons, nil
+}
+
+// readThreadedCommentsFromZipFile is an internal helper function that
+// reads the threaded comments part referred to by the worksheet
+// relationships and attaches each thread to the corresponding Cell of
+// the Sheet.
+func readThreadedCommentsFromZipFile(sheet *Sheet, worksheetRels *xlsxWorksheetRels, parts map[string]*zip.File, persons map[string]string) error {
+	for _, rel := range worksheetRels.Relationships {
+		if rel.Type != RelationshipTypeThreadedComment {
+			continue
+		}
+		f, ok := parts[resolveWorksheetRelTarget(rel.Target)]
+		if !ok {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		comments := new(xlsxThreadedComments)
+		err = xml.NewDecoder(rc).Decode(comments)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+		for _, comment := range comments.ThreadedComment {
+			x, y, err := GetCoordsFromCellIDString(comment.Ref)
+			if err != nil {
+				return err
+			}
+			cell := sheet.Cell(y, x)
+			cell.ThreadedComments = append(cell.ThreadedComments, ThreadedComment{
+				Author: persons[comment.PersonId],
+				Time:   parseThreadedCommentTime(comment.DT),
+				Text:   comment.Text,
+				Done:   comment.Done,
+			})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package xlsx
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestThreadedComments(t *testing.T) {
+	c := qt.New(t)
+
+	c.Run("ParseThreadedCommentTime", func(c *qt.C) {
+		c.Assert(parseThreadedCommentTime("2020-03-04T10:15:30.12"), qt.Equals, time.Date(2020, 3, 4, 10, 15, 30, 120000000, time.UTC))
+		c.Assert(parseThreadedCommentTime("2020-03-04T10:15:30Z"), qt.Equals, time.Date(2020, 3, 4, 10, 15, 30, 0, time.UTC))
+		c.Assert(parseThreadedCommentTime("yesterday").IsZero(), qt.Equals, true)
+	})
+
+	c.Run("Read", func(c *qt.C) {
+		f := NewFile()
+		sheet, err := f.AddSheet("Sheet1")
+		c.Assert(err, qt.IsNil)
+		sheet.Cell(0, 0).SetString("Total")
+		var buf bytes.Buffer
+		c.Assert(f.Write(&buf), qt.IsNil)
+
+		parts := unzipParts(c, buf.Bytes())
+		parts["xl/worksheets/_rels/sheet1.xml.rels"] = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.microsoft.com/office/2017/10/relationships/threadedComment" Target="../threadedComments/threadedComment1.xml"/></Relationships>`
+		parts["xl/threadedComments/threadedComment1.xml"] = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<ThreadedComments xmlns="http://schemas.microsoft.com/office/spreadsheetml/2018/threadedcomments" xmlns:x="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<threadedComment ref="A1" dT="2020-03-04T10:15:30.12" personId="{1}" id="{A}" done="1"><text>Does this include tax?</text></threadedComment>
+<threadedComment ref="A1" dT="2020-03-05T08:00:00.00" personId="{2}" id="{B}" parentId="{A}"><text>Yes.</text></threadedComment>
+<threadedComment ref="B3" personId="{3}" id="{C}"><text>Missing</text></threadedComment>
+</ThreadedComments>`
+		parts["xl/persons/person.xml"] = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<personList xmlns="http://schemas.microsoft.com/office/spreadsheetml/2018/threadedcomments" xmlns:x="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<person displayName="Alice" id="{1}" userId="alice@example.com" providerId="None"/>
+<person displayName="Bob" id="{2}" userId="bob@example.com" providerId="None"/>
+</personList>`
+
+		f, err = OpenBinary(zipParts(c, parts))
+		c.Assert(err, qt.IsNil)
+		sheet = f.Sheets[0]
+		c.Assert(sheet.Cell(0, 0).ThreadedComments, qt.DeepEquals, []ThreadedComment{
+			{
+				Author: "Alice",
+				Time:   time.Date(2020, 3, 4, 10, 15, 30, 120000000, time.UTC),
+				Text:   "Does this include tax?",
+				Done:   true,
+			},
+			{
+				Author: "Bob",
+				Time:   time.Date(2020, 3, 5, 8, 0, 0, 0, time.UTC),
+				Text:   "Yes.",
+			},
+		})
+		// Comments by people missing from the person list, and
+		// without a timestamp, are still read.
+		c.Assert(sheet.Cell(2, 1).ThreadedComments, qt.DeepEquals, []ThreadedComment{{Text: "Missing"}})
+		c.Assert(sheet.Cell(0, 0).Value, qt.Equals, "Total")
+
+		// Threads read as plain text are flattened, one line per
+		// comment.
+		text, ok := sheet.Cell(0, 0).GetComment()
+		c.Assert(ok, qt.Equals, true)
+		c.Assert(text, qt.Equals, "Alice: Does this include tax?\nBob: Yes.")
+		text, ok = sheet.Cell(2, 1).GetComment()
+		c.Assert(ok, qt.Equals, true)
+		c.Assert(text, qt.Equals, "Missing")
+		_, ok = sheet.Cell(1, 0).GetComment()
+		c.Assert(ok, qt.Equals, false)
+	})
+}
@@ -0,0 +1,43 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"io/ioutil"
+)
+
+const (
+	vbaProjectPath                  = "xl/vbaProject.bin"
+	vbaProjectContentType           = "application/vnd.ms-office.vbaProject"
+	macroEnabledWorkbookContentType = "application/vnd.ms-excel.sheet.macroEnabled.main+xml"
+	vbaProjectRelationshipType      = "http://schemas.microsoft.com/office/2006/relationships/vbaProject"
+)
+
+// VBAProject returns the binary VBA project holding the macros of a
+// macro enabled workbook (.xlsm), or nil if the File has no macros.
+func (f *File) VBAProject() []byte {
+	return f.vbaProject
+}
+
+// SetVBAProject sets the binary VBA project, as found in the
+// xl/vbaProject.bin part of an .xlsm file, that holds the macros of
+// the workbook.  A File with a VBA project is written as a macro
+// enabled workbook, and should be saved with the .xlsm extension, as
+// Excel refuses to open it otherwise.  Setting nil removes the macros.
+func (f *File) SetVBAProject(data []byte) {
+	f.vbaProject = data
+}
+
+// readVBAProjectFromZipFile is an internal helper function that reads
+// the xl/vbaProject.bin part of the XLSX zip file, if there is one.
+func readVBAProjectFromZipFile(parts map[string]*zip.File) ([]byte, error) {
+	part, ok := parts[vbaProjectPath]
+	if !ok {
+		return nil, nil
+	}
+	rc, err := part.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
@@ -0,0 +1,65 @@
+package xlsx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestVBAProject(t *testing.T) {
+	c := qt.New(t)
+
+	macros := []byte{0xd0, 0xcf, 0x11, 0xe0, 0xa1, 0xb1, 0x1a, 0xe1, 0x00, 0xff}
+
+	c.Run("NoMacros", func(c *qt.C) {
+		f := NewFile()
+		_, err := f.AddSheet("Sheet1")
+		c.Assert(err, qt.IsNil)
+		var buf bytes.Buffer
+		c.Assert(f.Write(&buf), qt.IsNil)
+
+		parts := unzipParts(c, buf.Bytes())
+		_, ok := parts[vbaProjectPath]
+		c.Assert(ok, qt.Equals, false)
+		c.Assert(strings.Contains(parts["[Content_Types].xml"], macroEnabledWorkbookContentType), qt.Equals, false)
+
+		f, err = OpenBinary(buf.Bytes())
+		c.Assert(err, qt.IsNil)
+		c.Assert(f.VBAProject(), qt.IsNil)
+	})
+
+	c.Run("RoundTrip", func(c *qt.C) {
+		f := NewFile()
+		sheet, err := f.AddSheet("Sheet1")
+		c.Assert(err, qt.IsNil)
+		sheet.Cell(0, 0).Value = "macros"
+		sheet.codeName = "Sheet1"
+		f.codeName = "ThisWorkbook"
+		f.SetVBAProject(macros)
+		var buf bytes.Buffer
+		c.Assert(f.Write(&buf), qt.IsNil)
+
+		parts := unzipParts(c, buf.Bytes())
+		c.Assert(parts[vbaProjectPath], qt.Equals, string(macros))
+		c.Assert(parts["[Content_Types].xml"], qt.Contains, `<Default Extension="bin" ContentType="application/vnd.ms-office.vbaProject"></Default>`)
+		c.Assert(parts["[Content_Types].xml"], qt.Contains, `<Override PartName="/xl/workbook.xml" ContentType="application/vnd.ms-excel.sheet.macroEnabled.main+xml"></Override>`)
+		c.Assert(parts["xl/_rels/workbook.xml.rels"], qt.Contains, `Target="vbaProject.bin" Type="http://schemas.microsoft.com/office/2006/relationships/vbaProject"`)
+
+		// Reading and writing the file again keeps the macros, and
+		// the code names that tie them to the workbook and sheet.
+		f, err = OpenBinary(buf.Bytes())
+		c.Assert(err, qt.IsNil)
+		c.Assert(f.VBAProject(), qt.DeepEquals, macros)
+		buf.Reset()
+		c.Assert(f.Write(&buf), qt.IsNil)
+
+		f, err = OpenBinary(buf.Bytes())
+		c.Assert(err, qt.IsNil)
+		c.Assert(f.VBAProject(), qt.DeepEquals, macros)
+		c.Assert(f.codeName, qt.Equals, "ThisWorkbook")
+		c.Assert(f.Sheets[0].codeName, qt.Equals, "Sheet1")
+		c.Assert(f.Sheets[0].Cell(0, 0).Value, qt.Equals, "macros")
+	})
+}
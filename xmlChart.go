@@ -0,0 +1,130 @@
+package xlsx
+
+import (
+	"encoding/xml"
+)
+
+// xlsxChartSpace directly maps the chartSpace element in the
+// namespace http://schemas.openxmlformats.org/drawingml/2006/chart -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxChartSpace struct {
+	XMLName xml.Name  `xml:"http://schemas.openxmlformats.org/drawingml/2006/chart chartSpace"`
+	Chart   xlsxChart `xml:"chart"`
+}
+
+// xlsxChart directly maps the chart element in the namespace
+// http://schemas.openxmlformats.org/drawingml/2006/chart - currently
+// I have not checked it for completeness - it does as much as I need.
+type xlsxChart struct {
+	Title    *xlsxChartTitle   `xml:"title"`
+	PlotArea xlsxChartPlotArea `xml:"plotArea"`
+}
+
+// xlsxChartTitle directly maps the title element in the namespace
+// http://schemas.openxmlformats.org/drawingml/2006/chart - currently
+// I have not checked it for completeness - it does as much as I need.
+type xlsxChartTitle struct {
+	Tx xlsxChartTx `xml:"tx"`
+}
+
+// xlsxChartTx directly maps the tx element in the namespace
+// http://schemas.openxmlformats.org/drawingml/2006/chart - currently
+// I have not checked it for completeness - it does as much as I need.
+type xlsxChartTx struct {
+	StrRef *xlsxChartStrRef `xml:"strRef"`
+	Rich   *xlsxChartRich   `xml:"rich"`
+	V      string           `xml:"v"`
+}
+
+// xlsxChartRich directly maps the rich element in the namespace
+// http://schemas.openxmlformats.org/drawingml/2006/chart - currently
+// I have not checked it for completeness - it does as much as I need.
+type xlsxChartRich struct {
+	P []xlsxChartParagraph `xml:"p"`
+}
+
+// xlsxChartParagraph directly maps the p element in the namespace
+// http://schemas.openxmlformats.org/drawingml/2006/main - currently I
+// have not checked it for completeness - it does as much as I need.
+type xlsxChartParagraph struct {
+	R []xlsxR `xml:"r"`
+}
+
+// xlsxChartPlotArea directly maps the plotArea element in the
+// namespace http://schemas.openxmlformats.org/drawingml/2006/chart -
+// currently I have not checked it for completeness - it does as much
+// as I need.  Each kind of chart has its own element, (barChart,
+// lineChart, pieChart and so on), so they are all collected here
+// along with the axes, and told apart by name.
+type xlsxChartPlotArea struct {
+	Groups []xlsxChartGroup `xml:",any"`
+}
+
+// xlsxChartGroup maps any of the barChart, lineChart, pieChart, etc.
+// elements in the namespace
+// http://schemas.openxmlformats.org/drawingml/2006/chart - currently
+// I have not checked it for completeness - it does as much as I need.
+type xlsxChartGroup struct {
+	XMLName xml.Name
+	Ser     []xlsxChartSer `xml:"ser"`
+}
+
+// xlsxChartSer directly maps the ser element in the namespace
+// http://schemas.openxmlformats.org/drawingml/2006/chart - currently
+// I have not checked it for completeness - it does as much as I need.
+type xlsxChartSer struct {
+	Tx   *xlsxChartTx      `xml:"tx"`
+	Cat  *xlsxChartDataRef `xml:"cat"`
+	Val  *xlsxChartDataRef `xml:"val"`
+	XVal *xlsxChartDataRef `xml:"xVal"`
+	YVal *xlsxChartDataRef `xml:"yVal"`
+}
+
+// xlsxChartDataRef maps the cat, val, xVal and yVal elements in the
+// namespace http://schemas.openxmlformats.org/drawingml/2006/chart -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxChartDataRef struct {
+	StrRef *xlsxChartStrRef `xml:"strRef"`
+	NumRef *xlsxChartNumRef `xml:"numRef"`
+}
+
+// xlsxChartStrRef directly maps the strRef element in the namespace
+// http://schemas.openxmlformats.org/drawingml/2006/chart - currently
+// I have not checked it for completeness - it does as much as I need.
+type xlsxChartStrRef struct {
+	F        string              `xml:"f"`
+	StrCache *xlsxChartDataCache `xml:"strCache"`
+}
+
+// xlsxChartNumRef directly maps the numRef element in the namespace
+// http://schemas.openxmlformats.org/drawingml/2006/chart - currently
+// I have not checked it for completeness - it does as much as I need.
+type xlsxChartNumRef struct {
+	F        string              `xml:"f"`
+	NumCache *xlsxChartDataCache `xml:"numCache"`
+}
+
+// xlsxChartDataCache maps the strCache and numCache elements in the
+// namespace http://schemas.openxmlformats.org/drawingml/2006/chart -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxChartDataCache struct {
+	PtCount *xlsxChartPtCount `xml:"ptCount"`
+	Pt      []xlsxChartPt     `xml:"pt"`
+}
+
+// xlsxChartPtCount directly maps the ptCount element in the namespace
+// http://schemas.openxmlformats.org/drawingml/2006/chart.
+type xlsxChartPtCount struct {
+	Val int `xml:"val,attr"`
+}
+
+// xlsxChartPt directly maps the pt element in the namespace
+// http://schemas.openxmlformats.org/drawingml/2006/chart - currently
+// I have not checked it for completeness - it does as much as I need.
+type xlsxChartPt struct {
+	Idx int    `xml:"idx,attr"`
+	V   string `xml:"v"`
+}
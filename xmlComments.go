@@ -0,0 +1,104 @@
+package xlsx
+
+import (
+	"encoding/xml"
+)
+
+// xlsxComments directly maps the comments element in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxComments struct {
+	XMLName     xml.Name        `xml:"http://schemas.openxmlformats.org/spreadsheetml/2006/main comments"`
+	Authors     xlsxAuthors     `xml:"authors"`
+	CommentList xlsxCommentList `xml:"commentList"`
+}
+
+// xlsxAuthors directly maps the authors element in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxAuthors struct {
+	Author []string `xml:"author"`
+}
+
+// xlsxCommentList directly maps the commentList element in the
+// namespace http://schemas.openxmlformats.org/spreadsheetml/2006/main -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxCommentList struct {
+	Comment []xlsxComment `xml:"comment"`
+}
+
+// xlsxComment directly maps the comment element in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxComment struct {
+	Ref      string          `xml:"ref,attr"`
+	AuthorId int             `xml:"authorId,attr"`
+	Text     xlsxCommentText `xml:"text"`
+}
+
+// xlsxCommentText directly maps the text element in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxCommentText struct {
+	T string  `xml:"t,omitempty"`
+	R []xlsxR `xml:"r"`
+}
+
+// xlsxLegacyDrawing directly maps the legacyDrawing element in the
+// namespace http://schemas.openxmlformats.org/spreadsheetml/2006/main -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxLegacyDrawing struct {
+	RelationshipId string `xml:"id,attr"`
+}
+
+// xlsxThreadedComments directly maps the ThreadedComments element in
+// the namespace
+// http://schemas.microsoft.com/office/spreadsheetml/2018/threadedcomments
+// - currently I have not checked it for completeness - it does as
+// much as I need.
+type xlsxThreadedComments struct {
+	XMLName         xml.Name              `xml:"ThreadedComments"`
+	ThreadedComment []xlsxThreadedComment `xml:"threadedComment"`
+}
+
+// xlsxThreadedComment directly maps the threadedComment element in
+// the namespace
+// http://schemas.microsoft.com/office/spreadsheetml/2018/threadedcomments
+// - currently I have not checked it for completeness - it does as
+// much as I need.
+type xlsxThreadedComment struct {
+	Ref      string `xml:"ref,attr"`
+	DT       string `xml:"dT,attr,omitempty"`
+	PersonId string `xml:"personId,attr"`
+	Id       string `xml:"id,attr"`
+	ParentId string `xml:"parentId,attr,omitempty"`
+	Done     bool   `xml:"done,attr,omitempty"`
+	Text     string `xml:"text"`
+}
+
+// xlsxPersonList directly maps the personList element in the
+// namespace
+// http://schemas.microsoft.com/office/spreadsheetml/2018/threadedcomments
+// - currently I have not checked it for completeness - it does as
+// much as I need.
+type xlsxPersonList struct {
+	XMLName xml.Name     `xml:"personList"`
+	Person  []xlsxPerson `xml:"person"`
+}
+
+// xlsxPerson directly maps the person element in the namespace
+// http://schemas.microsoft.com/office/spreadsheetml/2018/threadedcomments
+// - currently I have not checked it for completeness - it does as
+// much as I need.
+type xlsxPerson struct {
+	DisplayName string `xml:"displayName,attr"`
+	Id          string `xml:"id,attr"`
+	UserId      string `xml:"userId,attr,omitempty"`
+	ProviderId  string `xml:"providerId,attr,omitempty"`
+}
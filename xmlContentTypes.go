@@ -21,6 +21,17 @@ type xlsxDefault struct {
 	ContentType string `xml:",attr"`
 }
 
+// addDefault registers the content type of files with the given
+// extension, unless it is already registered.
+func (types *xlsxTypes) addDefault(extension, contentType string) {
+	for _, d := range types.Defaults {
+		if d.Extension == extension {
+			return
+		}
+	}
+	types.Defaults = append(types.Defaults, xlsxDefault{Extension: extension, ContentType: contentType})
+}
+
 func MakeDefaultContentTypes() (types xlsxTypes) {
 	types.Overrides = make([]xlsxOverride, 8)
 	types.Defaults = make([]xlsxDefault, 2)
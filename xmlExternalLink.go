@@ -0,0 +1,61 @@
+package xlsx
+
+import "encoding/xml"
+
+// xlsxExternalLink directly maps the externalLink element in the
+// namespace http://schemas.openxmlformats.org/spreadsheetml/2006/main -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxExternalLink struct {
+	XMLName      xml.Name         `xml:"http://schemas.openxmlformats.org/spreadsheetml/2006/main externalLink"`
+	ExternalBook xlsxExternalBook `xml:"externalBook"`
+}
+
+// xlsxExternalBook directly maps the externalBook element in the
+// namespace http://schemas.openxmlformats.org/spreadsheetml/2006/main -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxExternalBook struct {
+	RelationshipId string                  `xml:"id,attr"`
+	SheetNames     []xlsxExternalSheetName `xml:"sheetNames>sheetName"`
+	SheetDataSet   []xlsxExternalSheetData `xml:"sheetDataSet>sheetData"`
+}
+
+// xlsxExternalSheetName directly maps the sheetName element in the
+// namespace http://schemas.openxmlformats.org/spreadsheetml/2006/main -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxExternalSheetName struct {
+	Val string `xml:"val,attr"`
+}
+
+// xlsxExternalSheetData directly maps the sheetData element of an
+// externalBook in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxExternalSheetData struct {
+	SheetId int                    `xml:"sheetId,attr"`
+	Row     []xlsxExternalSheetRow `xml:"row"`
+}
+
+// xlsxExternalSheetRow directly maps the row element of an
+// externalBook's sheetData in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxExternalSheetRow struct {
+	R    int                     `xml:"r,attr"`
+	Cell []xlsxExternalSheetCell `xml:"cell"`
+}
+
+// xlsxExternalSheetCell directly maps the cell element of an
+// externalBook's sheetData in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxExternalSheetCell struct {
+	R string `xml:"r,attr"`
+	T string `xml:"t,attr,omitempty"`
+	V string `xml:"v"`
+}
@@ -0,0 +1,76 @@
+package xlsx
+
+import "encoding/xml"
+
+// xlsxPivotCaches directly maps the pivotCaches element in the
+// namespace http://schemas.openxmlformats.org/spreadsheetml/2006/main
+// - currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxPivotCaches struct {
+	PivotCache []xlsxPivotCache `xml:"pivotCache"`
+}
+
+// xlsxPivotCache directly maps the pivotCache element in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxPivotCache struct {
+	CacheId int    `xml:"cacheId,attr"`
+	Id      string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+}
+
+// xlsxPivotCacheDefinition directly maps the pivotCacheDefinition
+// element in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxPivotCacheDefinition struct {
+	XMLName       xml.Name        `xml:"http://schemas.openxmlformats.org/spreadsheetml/2006/main pivotCacheDefinition"`
+	SaveData      bool            `xml:"saveData,attr"`
+	RefreshOnLoad bool            `xml:"refreshOnLoad,attr"`
+	CacheSource   xlsxCacheSource `xml:"cacheSource"`
+	CacheFields   xlsxCacheFields `xml:"cacheFields"`
+}
+
+// xlsxCacheSource directly maps the cacheSource element in the
+// namespace http://schemas.openxmlformats.org/spreadsheetml/2006/main
+// - currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxCacheSource struct {
+	Type            string              `xml:"type,attr"`
+	WorksheetSource xlsxWorksheetSource `xml:"worksheetSource"`
+}
+
+// xlsxWorksheetSource directly maps the worksheetSource element in the
+// namespace http://schemas.openxmlformats.org/spreadsheetml/2006/main
+// - currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxWorksheetSource struct {
+	Ref   string `xml:"ref,attr"`
+	Sheet string `xml:"sheet,attr"`
+}
+
+// xlsxCacheFields directly maps the cacheFields element in the
+// namespace http://schemas.openxmlformats.org/spreadsheetml/2006/main
+// - currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxCacheFields struct {
+	Count      int              `xml:"count,attr"`
+	CacheField []xlsxCacheField `xml:"cacheField"`
+}
+
+// xlsxCacheField directly maps the cacheField element in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxCacheField struct {
+	Name        string          `xml:"name,attr"`
+	NumFmtId    int             `xml:"numFmtId,attr"`
+	SharedItems xlsxSharedItems `xml:"sharedItems"`
+}
+
+// xlsxSharedItems directly maps the sharedItems element in the
+// namespace http://schemas.openxmlformats.org/spreadsheetml/2006/main
+// - currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxSharedItems struct{}
@@ -28,5 +28,24 @@ type xlsxSI struct {
 // currently I have not checked this for completeness - it does as
 // much as I need.
 type xlsxR struct {
-	T string `xml:"t"`
+	RPr *xlsxRunProperties `xml:"rPr,omitempty"`
+	T   string             `xml:"t"`
+}
+
+// xlsxRunProperties directly maps the rPr element from the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main -
+// currently I have not checked this for completeness - it does as
+// much as I need.
+type xlsxRunProperties struct {
+	RFont     *xlsxVal   `xml:"rFont,omitempty"`
+	Charset   *xlsxVal   `xml:"charset,omitempty"`
+	Family    *xlsxVal   `xml:"family,omitempty"`
+	B         *xlsxVal   `xml:"b,omitempty"`
+	I         *xlsxVal   `xml:"i,omitempty"`
+	Strike    *xlsxVal   `xml:"strike,omitempty"`
+	Color     *xlsxColor `xml:"color,omitempty"`
+	Sz        *xlsxVal   `xml:"sz,omitempty"`
+	U         *xlsxVal   `xml:"u,omitempty"`
+	VertAlign *xlsxVal   `xml:"vertAlign,omitempty"`
+	Scheme    *xlsxVal   `xml:"scheme,omitempty"`
 }
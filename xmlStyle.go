@@ -30,6 +30,10 @@ var builtInNumFmt = map[int]string{
 	2:  "0.00",
 	3:  "#,##0",
 	4:  "#,##0.00",
+	5:  `"$"#,##0_);("$"#,##0)`,
+	6:  `"$"#,##0_);[red]("$"#,##0)`,
+	7:  `"$"#,##0.00_);("$"#,##0.00)`,
+	8:  `"$"#,##0.00_);[red]("$"#,##0.00)`,
 	9:  "0%",
 	10: "0.00%",
 	11: "0.00e+00",
@@ -49,7 +53,7 @@ var builtInNumFmt = map[int]string{
 	39: "#,##0.00;(#,##0.00)",
 	40: "#,##0.00;[red](#,##0.00)",
 	41: `_(* #,##0_);_(* \(#,##0\);_(* "-"_);_(@_)`,
-	42: `_("$"* #,##0_);_("$* \(#,##0\);_("$"* "-"_);_(@_)`,
+	42: `_("$"* #,##0_);_("$"* \(#,##0\);_("$"* "-"_);_(@_)`,
 	43: `_(* #,##0.00_);_(* \(#,##0.00\);_(* "-"??_);_(@_)`,
 	44: `_("$"* #,##0.00_);_("$"* \(#,##0.00\);_("$"* "-"??_);_(@_)`,
 	45: "mm:ss",
@@ -59,6 +63,15 @@ var builtInNumFmt = map[int]string{
 	49: "@",
 }
 
+// The built-in number formats 27 to 36 and 50 to 58 depend on the
+// locale Excel runs in, and are mostly used by the East Asian ones for
+// dates and times in their own scripts.  When reading, they are shown
+// using the closest locale independent built-in format instead.
+var builtInLocaleNumFmt = map[int]int{
+	27: 17, 28: 16, 29: 16, 30: 14, 31: 14, 32: 20, 33: 21, 34: 18, 35: 19, 36: 17,
+	50: 17, 51: 16, 52: 17, 53: 16, 54: 16, 55: 18, 56: 19, 57: 17, 58: 16,
+}
+
 // These are the color annotations from number format codes that contain color names.
 // Also possible are [color1] through [color56]
 var numFmtColorCodes = []string{
@@ -175,6 +188,10 @@ func (styles *xlsxStyleSheet) populateStyleFromXf(style *Style, xf xlsxXf) {
 		style.Border.TopColor = border.Top.Color.RGB
 		style.Border.Bottom = border.Bottom.Style
 		style.Border.BottomColor = border.Bottom.Color.RGB
+		style.Border.Diagonal = border.Diagonal.Style
+		style.Border.DiagonalColor = border.Diagonal.Color.RGB
+		style.Border.DiagonalUp = border.DiagonalUp
+		style.Border.DiagonalDown = border.DiagonalDown
 	}
 
 	if xf.FillId > -1 && xf.FillId < styles.Fills.Count {
@@ -234,6 +251,7 @@ func (styles *xlsxStyleSheet) getStyle(styleIndex int) *Style {
 	if styleIndex > -1 && xfCount > 0 && styleIndex < xfCount {
 		xf := styles.CellXfs.Xf[styleIndex]
 		styles.populateStyleFromXf(style, xf)
+		style.QuotePrefix = xf.QuotePrefix
 		if xf.XfId != nil && styles.CellStyleXfs != nil && *xf.XfId < len(styles.CellStyleXfs.Xf) {
 			style.NamedStyleIndex = xf.XfId
 			namedStyleXf := styles.CellStyleXfs.Xf[*xf.XfId]
@@ -249,6 +267,17 @@ func (styles *xlsxStyleSheet) getStyle(styleIndex int) *Style {
 		style.Alignment.WrapText = xf.Alignment.WrapText
 		style.Alignment.TextRotation = xf.Alignment.TextRotation
 
+		if !style.ApplyAlignment {
+			// Excel ignores the alignment of a format that does not
+			// apply it, and shows that of its cell style instead.
+			style.Alignment = Alignment{}
+			if style.NamedStyleIndex != nil {
+				namedStyle := &Style{}
+				styles.populateStyleFromXf(namedStyle, styles.CellStyleXfs.Xf[*style.NamedStyleIndex])
+				style.Alignment = namedStyle.Alignment
+			}
+		}
+
 		styles.Lock()
 		styles.styleCache[styleIndex] = style
 		styles.Unlock()
@@ -279,26 +308,34 @@ func getBuiltinNumberFormat(numFmtId int) string {
 
 func (styles *xlsxStyleSheet) getNumberFormat(styleIndex int) (string, *parsedNumberFormat) {
 	var numberFormat string = "general"
+	styles.RLock()
 	if styles.CellXfs.Xf != nil {
 		if styleIndex > -1 && styleIndex < styles.CellXfs.Count {
 			xf := styles.CellXfs.Xf[styleIndex]
 			if builtin := getBuiltinNumberFormat(xf.NumFmtId); builtin != "" {
 				numberFormat = builtin
-			} else {
-				if styles.numFmtRefTable != nil {
-					numFmt := styles.numFmtRefTable[xf.NumFmtId]
-					numberFormat = numFmt.FormatCode
-				}
+			} else if numFmt, ok := styles.numFmtRefTable[xf.NumFmtId]; ok {
+				numberFormat = numFmt.FormatCode
+			} else if id, ok := builtInLocaleNumFmt[xf.NumFmtId]; ok {
+				// Files usually define the locale dependent
+				// formats they use, but fall back on a close one
+				// if not.
+				numberFormat = builtInNumFmt[id]
+			} else if styles.numFmtRefTable != nil {
+				numberFormat = ""
 			}
 		}
 	}
 	parsedFmt, ok := styles.parsedNumFmtTable[numberFormat]
+	styles.RUnlock()
 	if !ok {
+		parsedFmt = parseFullNumberFormatString(numberFormat)
+		styles.Lock()
 		if styles.parsedNumFmtTable == nil {
 			styles.parsedNumFmtTable = map[string]*parsedNumberFormat{}
 		}
-		parsedFmt = parseFullNumberFormatString(numberFormat)
 		styles.parsedNumFmtTable[numberFormat] = parsedFmt
+		styles.Unlock()
 	}
 	return numberFormat, parsedFmt
 }
@@ -813,14 +850,18 @@ func (borders *xlsxBorders) Marshal(outputBorderMap map[int]int) (result string,
 // currently I have not checked it for completeness - it does as much
 // as I need.
 type xlsxBorder struct {
-	Left   xlsxLine `xml:"left,omitempty"`
-	Right  xlsxLine `xml:"right,omitempty"`
-	Top    xlsxLine `xml:"top,omitempty"`
-	Bottom xlsxLine `xml:"bottom,omitempty"`
+	DiagonalUp   bool     `xml:"diagonalUp,attr,omitempty"`
+	DiagonalDown bool     `xml:"diagonalDown,attr,omitempty"`
+	Left         xlsxLine `xml:"left,omitempty"`
+	Right        xlsxLine `xml:"right,omitempty"`
+	Top          xlsxLine `xml:"top,omitempty"`
+	Bottom       xlsxLine `xml:"bottom,omitempty"`
+	Diagonal     xlsxLine `xml:"diagonal,omitempty"`
 }
 
 func (border *xlsxBorder) Equals(other xlsxBorder) bool {
-	return border.Left.Equals(other.Left) && border.Right.Equals(other.Right) && border.Top.Equals(other.Top) && border.Bottom.Equals(other.Bottom)
+	return border.Left.Equals(other.Left) && border.Right.Equals(other.Right) && border.Top.Equals(other.Top) && border.Bottom.Equals(other.Bottom) &&
+		border.Diagonal.Equals(other.Diagonal) && border.DiagonalUp == other.DiagonalUp && border.DiagonalDown == other.DiagonalDown
 }
 
 //
@@ -845,7 +886,18 @@ func (border *xlsxBorder) Marshal() (result string, err error) {
 	subparts += border.marshalBorderLine(border.Right, "right")
 	subparts += border.marshalBorderLine(border.Top, "top")
 	subparts += border.marshalBorderLine(border.Bottom, "bottom")
-	result += `<border>`
+	// The diagonal is left out unless it is drawn, as it always was.
+	if border.Diagonal.Style != "" {
+		subparts += border.marshalBorderLine(border.Diagonal, "diagonal")
+	}
+	result += `<border`
+	if border.DiagonalUp {
+		result += ` diagonalUp="1"`
+	}
+	if border.DiagonalDown {
+		result += ` diagonalDown="1"`
+	}
+	result += `>`
 	result += subparts
 	result += `</border>`
 	return
@@ -974,6 +1026,7 @@ type xlsxXf struct {
 	FontId            int           `xml:"fontId,attr"`
 	NumFmtId          int           `xml:"numFmtId,attr"`
 	XfId              *int          `xml:"xfId,attr,omitempty"`
+	QuotePrefix       bool          `xml:"quotePrefix,attr,omitempty"`
 	Alignment         xlsxAlignment `xml:"alignment"`
 }
 
@@ -987,6 +1040,7 @@ func (xf *xlsxXf) Equals(other xlsxXf) bool {
 		xf.FillId == other.FillId &&
 		xf.FontId == other.FontId &&
 		xf.NumFmtId == other.NumFmtId &&
+		xf.QuotePrefix == other.QuotePrefix &&
 		(xf.XfId == other.XfId ||
 			((xf.XfId != nil && other.XfId != nil) &&
 				*xf.XfId == *other.XfId)) &&
@@ -998,6 +1052,9 @@ func (xf *xlsxXf) Marshal(outputBorderMap, outputFillMap, outputFontMap map[int]
 	if xf.XfId != nil {
 		result += fmt.Sprintf(` xfId="%d"`, *xf.XfId)
 	}
+	if xf.QuotePrefix {
+		result += ` quotePrefix="1"`
+	}
 	result += ">"
 	xAlignment, err := xf.Alignment.Marshal()
 	if err != nil {
@@ -1024,6 +1081,17 @@ func (alignment *xlsxAlignment) Equals(other xlsxAlignment) bool {
 		alignment.WrapText == other.WrapText
 }
 
+// isDefault reports whether the alignment is the one Excel gives a
+// cell that has none: general, at the bottom and without wrapping.
+func (alignment *xlsxAlignment) isDefault() bool {
+	return (alignment.Horizontal == "" || alignment.Horizontal == "general") &&
+		alignment.Indent == 0 &&
+		!alignment.ShrinkToFit &&
+		alignment.TextRotation == 0 &&
+		(alignment.Vertical == "" || alignment.Vertical == "bottom") &&
+		!alignment.WrapText
+}
+
 func (alignment *xlsxAlignment) Marshal() (result string, err error) {
 	if alignment.Horizontal == "" {
 		alignment.Horizontal = "general"
@@ -415,6 +415,33 @@ func TestStyle(t *testing.T) {
 			c.Assert(s0.ApplyFont, qt.Equals, true)
 		})
 
+		c.Run("AlignmentNotApplied", func(c *qt.C) {
+			styles := newXlsxStyleSheet(nil)
+			alignment := xlsxAlignment{Horizontal: "center", Vertical: "top", WrapText: true}
+			styles.CellXfs.addXf(xlsxXf{Alignment: alignment, ApplyAlignment: false})
+			styles.CellXfs.addXf(xlsxXf{Alignment: alignment, ApplyAlignment: true})
+
+			s0 := styles.getStyle(0)
+			c.Assert(s0.ApplyAlignment, qt.Equals, false)
+			c.Assert(s0.Alignment, qt.Equals, Alignment{})
+
+			s1 := styles.getStyle(1)
+			c.Assert(s1.Alignment.Horizontal, qt.Equals, "center")
+			c.Assert(s1.Alignment.Vertical, qt.Equals, "top")
+			c.Assert(s1.Alignment.WrapText, qt.Equals, true)
+		})
+
+		c.Run("AlignmentFromNamedStyle", func(c *qt.C) {
+			styles := newXlsxStyleSheet(nil)
+			csXfs := xlsxCellStyleXfs{}
+			csXfs.addXf(xlsxXf{Alignment: xlsxAlignment{Horizontal: "right"}})
+			styles.CellStyleXfs = &csXfs
+			namedStyleId := 0
+			styles.CellXfs.addXf(xlsxXf{XfId: &namedStyleId, Alignment: xlsxAlignment{Horizontal: "center"}})
+
+			s0 := styles.getStyle(0)
+			c.Assert(s0.Alignment.Horizontal, qt.Equals, "right")
+		})
 	})
 
 	c.Run("PopulateStyleFromXf", func(c *qt.C) {
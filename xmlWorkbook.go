@@ -42,6 +42,7 @@ type xlsxWorkbook struct {
 	Sheets             xlsxSheets             `xml:"sheets"`
 	DefinedNames       xlsxDefinedNames       `xml:"definedNames"`
 	CalcPr             xlsxCalcPr             `xml:"calcPr"`
+	PivotCaches        *xlsxPivotCaches       `xml:"pivotCaches,omitempty"`
 }
 
 // xlsxWorkbookProtection directly maps the workbookProtection element from the
@@ -72,6 +73,7 @@ type xlsxWorkbookPr struct {
 	BackupFile          bool   `xml:"backupFile,attr,omitempty"`
 	ShowObjects         string `xml:"showObjects,attr,omitempty"`
 	Date1904            bool   `xml:"date1904,attr"`
+	CodeName            string `xml:"codeName,attr,omitempty"`
 }
 
 // xlsxBookViews directly maps the bookViews element from the
@@ -141,7 +143,7 @@ type xlsxDefinedName struct {
 	Help              string `xml:"help,attr,omitempty"`
 	ShortcutKey       string `xml:"shortcutKey,attr,omitempty"`
 	StatusBar         string `xml:"statusBar,attr,omitempty"`
-	LocalSheetID      int    `xml:"localSheetId,attr,omitempty"`
+	LocalSheetID      *int   `xml:"localSheetId,attr"`
 	FunctionGroupID   int    `xml:"functionGroupId,attr,omitempty"`
 	Function          bool   `xml:"function,attr,omitempty"`
 	Hidden            bool   `xml:"hidden,attr,omitempty"`
@@ -2,13 +2,19 @@ package xlsx
 
 import (
 	"encoding/xml"
+	"strconv"
 	"strings"
 )
 
 type RelationshipType string
 
 const (
-	RelationshipTypeHyperlink RelationshipType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink"
+	RelationshipTypeHyperlink  RelationshipType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink"
+	RelationshipTypeComments   RelationshipType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/comments"
+	RelationshipTypeVMLDrawing RelationshipType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/vmlDrawing"
+	RelationshipTypeDrawing    RelationshipType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/drawing"
+	RelationshipTypeChart      RelationshipType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/chart"
+	RelationshipTypeImage      RelationshipType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/image"
 )
 
 type RelationshipTargetMode string
@@ -23,11 +29,23 @@ type xlsxWorksheetRels struct {
 	Relationships []xlsxWorksheetRelation `xml:"Relationship"`
 }
 
+// nextId returns an id of the form rIdN that none of the relationships
+// has, one above the highest such id they have.
+func (rels *xlsxWorksheetRels) nextId() string {
+	highest := 0
+	for _, rel := range rels.Relationships {
+		if n, err := strconv.Atoi(strings.TrimPrefix(rel.Id, "rId")); err == nil && n > highest {
+			highest = n
+		}
+	}
+	return "rId" + strconv.Itoa(highest+1)
+}
+
 type xlsxWorksheetRelation struct {
 	Id         string                 `xml:"Id,attr"`
 	Type       RelationshipType       `xml:"Type,attr"`
 	Target     string                 `xml:"Target,attr"`
-	TargetMode RelationshipTargetMode `xml:"TargetMode,attr"`
+	TargetMode RelationshipTargetMode `xml:"TargetMode,attr,omitempty"`
 }
 
 // xlsxWorksheet directly maps the worksheet element in the namespace
@@ -35,21 +53,81 @@ type xlsxWorksheetRelation struct {
 // currently I have not checked it for completeness - it does as much
 // as I need.
 type xlsxWorksheet struct {
-	XMLName         xml.Name             `xml:"http://schemas.openxmlformats.org/spreadsheetml/2006/main worksheet"`
-	SheetPr         xlsxSheetPr          `xml:"sheetPr"`
-	Dimension       xlsxDimension        `xml:"dimension"`
-	SheetViews      xlsxSheetViews       `xml:"sheetViews"`
-	SheetFormatPr   xlsxSheetFormatPr    `xml:"sheetFormatPr"`
-	Cols            *xlsxCols            `xml:"cols,omitempty"`
-	SheetData       xlsxSheetData        `xml:"sheetData"`
-	Hyperlinks      *xlsxHyperlinks      `xml:"hyperlinks,omitempty"`
-	DataValidations *xlsxDataValidations `xml:"dataValidations"`
-	AutoFilter      *xlsxAutoFilter      `xml:"autoFilter,omitempty"`
-	MergeCells      *xlsxMergeCells      `xml:"mergeCells,omitempty"`
-	PrintOptions    xlsxPrintOptions     `xml:"printOptions"`
-	PageMargins     xlsxPageMargins      `xml:"pageMargins"`
-	PageSetUp       xlsxPageSetUp        `xml:"pageSetup"`
-	HeaderFooter    xlsxHeaderFooter     `xml:"headerFooter"`
+	XMLName               xml.Name                    `xml:"http://schemas.openxmlformats.org/spreadsheetml/2006/main worksheet"`
+	SheetPr               xlsxSheetPr                 `xml:"sheetPr"`
+	Dimension             xlsxDimension               `xml:"dimension"`
+	SheetViews            xlsxSheetViews              `xml:"sheetViews"`
+	SheetFormatPr         xlsxSheetFormatPr           `xml:"sheetFormatPr"`
+	Cols                  *xlsxCols                   `xml:"cols,omitempty"`
+	SheetData             xlsxSheetData               `xml:"sheetData"`
+	AutoFilter            *xlsxAutoFilter             `xml:"autoFilter,omitempty"`
+	MergeCells            *xlsxMergeCells             `xml:"mergeCells,omitempty"`
+	ConditionalFormatting []xlsxConditionalFormatting `xml:"conditionalFormatting,omitempty"`
+	DataValidations       *xlsxDataValidations        `xml:"dataValidations"`
+	Hyperlinks            *xlsxHyperlinks             `xml:"hyperlinks,omitempty"`
+	PrintOptions          xlsxPrintOptions            `xml:"printOptions"`
+	PageMargins           xlsxPageMargins             `xml:"pageMargins"`
+	PageSetUp             xlsxPageSetUp               `xml:"pageSetup"`
+	HeaderFooter          xlsxHeaderFooter            `xml:"headerFooter"`
+	RowBreaks             *xlsxBreaks                 `xml:"rowBreaks,omitempty"`
+	ColBreaks             *xlsxBreaks                 `xml:"colBreaks,omitempty"`
+	LegacyDrawing         *xlsxLegacyDrawing          `xml:"legacyDrawing,omitempty"`
+	Picture               *xlsxPicture                `xml:"picture,omitempty"`
+	ExtLst                *xlsxExtLst                 `xml:"extLst,omitempty"`
+}
+
+// xlsxExtLst directly maps the extLst element in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main.  Its
+// content holds the extensions of newer applications, which are kept
+// as they were read so that they can be written back unchanged.
+type xlsxExtLst struct {
+	Content string `xml:",innerxml"`
+}
+
+// xlsxConditionalFormatting directly maps the conditionalFormatting
+// element in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxConditionalFormatting struct {
+	SQRef  string       `xml:"sqref,attr"`
+	CfRule []xlsxCfRule `xml:"cfRule"`
+}
+
+// xlsxCfRule directly maps the cfRule element in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxCfRule struct {
+	Type       string          `xml:"type,attr"`
+	Priority   int             `xml:"priority,attr"`
+	ColorScale *xlsxColorScale `xml:"colorScale,omitempty"`
+}
+
+// xlsxColorScale directly maps the colorScale element in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxColorScale struct {
+	Cfvo  []xlsxCfvo  `xml:"cfvo"`
+	Color []xlsxColor `xml:"color"`
+}
+
+// xlsxCfvo directly maps the cfvo element in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxCfvo struct {
+	Type string `xml:"type,attr"`
+	Val  string `xml:"val,attr,omitempty"`
+}
+
+// xlsxPicture directly maps the picture element in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxPicture struct {
+	RelationshipId string `xml:"id,attr"`
 }
 
 // xlsxHeaderFooter directly maps the headerFooter element in the namespace
@@ -113,6 +191,26 @@ type xlsxPrintOptions struct {
 	VerticalCentered   bool `xml:"verticalCentered,attr"`
 }
 
+// xlsxBreaks directly maps the rowBreaks and colBreaks elements in
+// the namespace http://schemas.openxmlformats.org/spreadsheetml/2006/main -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxBreaks struct {
+	Count            int         `xml:"count,attr"`
+	ManualBreakCount int         `xml:"manualBreakCount,attr"`
+	Brk              []xlsxBreak `xml:"brk"`
+}
+
+// xlsxBreak directly maps the brk element in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxBreak struct {
+	Id  int  `xml:"id,attr"`
+	Max int  `xml:"max,attr,omitempty"`
+	Man bool `xml:"man,attr,omitempty"`
+}
+
 // xlsxPageMargins directly maps the pageMargins element in the namespace
 // http://schemas.openxmlformats.org/spreadsheetml/2006/main -
 // currently I have not checked it for completeness - it does as much
@@ -145,6 +243,16 @@ type xlsxSheetViews struct {
 	SheetView []xlsxSheetView `xml:"sheetView"`
 }
 
+// MarshalXML writes the sheetViews element, unless it has no views, as
+// the schema doesn't allow an empty one.
+func (v xlsxSheetViews) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if len(v.SheetView) == 0 {
+		return nil
+	}
+	type sheetViews xlsxSheetViews
+	return e.EncodeElement(sheetViews(v), start)
+}
+
 // xlsxSheetView directly maps the sheetView element in the namespace
 // http://schemas.openxmlformats.org/spreadsheetml/2006/main -
 // currently I have not checked it for completeness - it does as much
@@ -199,6 +307,7 @@ type xlsxPane struct {
 // as I need.
 type xlsxSheetPr struct {
 	FilterMode  bool              `xml:"filterMode,attr"`
+	CodeName    string            `xml:"codeName,attr,omitempty"`
 	PageSetUpPr []xlsxPageSetUpPr `xml:"pageSetUpPr"`
 }
 
@@ -307,13 +416,35 @@ type xlsxDataValidation struct {
 // currently I have not checked it for completeness - it does as much
 // as I need.
 type xlsxRow struct {
-	R            int     `xml:"r,attr"`
-	Spans        string  `xml:"spans,attr,omitempty"`
-	Hidden       bool    `xml:"hidden,attr,omitempty"`
-	C            []xlsxC `xml:"c"`
-	Ht           string  `xml:"ht,attr,omitempty"`
-	CustomHeight bool    `xml:"customHeight,attr,omitempty"`
-	OutlineLevel uint8   `xml:"outlineLevel,attr,omitempty"`
+	R            int          `xml:"r,attr"`
+	Spans        string       `xml:"spans,attr,omitempty"`
+	Hidden       xlsxBoolAttr `xml:"hidden,attr,omitempty"`
+	C            []xlsxC      `xml:"c"`
+	Ht           string       `xml:"ht,attr,omitempty"`
+	CustomHeight xlsxBoolAttr `xml:"customHeight,attr,omitempty"`
+	OutlineLevel uint8        `xml:"outlineLevel,attr,omitempty"`
+	Collapsed    xlsxBoolAttr `xml:"collapsed,attr,omitempty"`
+}
+
+// xlsxBoolAttr is a boolean attribute that is written as "1", the
+// form Excel itself writes, rather than "true", and that reads any of
+// the forms of a boolean in the schema.
+type xlsxBoolAttr bool
+
+func (b xlsxBoolAttr) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	if !b {
+		return xml.Attr{}, nil
+	}
+	return xml.Attr{Name: name, Value: "1"}, nil
+}
+
+func (b *xlsxBoolAttr) UnmarshalXMLAttr(attr xml.Attr) error {
+	v, err := strconv.ParseBool(attr.Value)
+	if err != nil {
+		return err
+	}
+	*b = xlsxBoolAttr(v)
+	return nil
 }
 
 type xlsxAutoFilter struct {
@@ -344,10 +475,13 @@ type xlsxHyperlinks struct {
 }
 
 type xlsxHyperlink struct {
-	RelationshipId string `xml:"id,attr"`
+	RelationshipId string `xml:"id,attr,omitempty"`
 	Reference      string `xml:"ref,attr"`
-	DisplayString  string `xml:"display,attr,omitempty"`
-	Tooltip        string `xml:"tooltip,attr,omitempty"`
+	// Location is the place within the workbook, such as
+	// "'Sheet 2'!A1", that a link with no relationship goes to.
+	Location      string `xml:"location,attr,omitempty"`
+	DisplayString string `xml:"display,attr,omitempty"`
+	Tooltip       string `xml:"tooltip,attr,omitempty"`
 }
 
 // Return the cartesian extent of a merged cell range from its origin
@@ -377,12 +511,13 @@ func (mc *xlsxMergeCells) getExtent(cellRef string) (int, int, error) {
 // as I need.
 type xlsxC struct {
 	XMLName xml.Name
-	R       string  `xml:"r,attr"`           // Cell ID, e.g. A1
-	S       int     `xml:"s,attr,omitempty"` // Style reference.
-	T       string  `xml:"t,attr,omitempty"` // Type.
-	F       *xlsxF  `xml:"f,omitempty"`      // Formula
-	V       string  `xml:"v,omitempty"`      // Value
-	Is      *xlsxSI `xml:"is,omitempty"`     // Inline String.
+	R       string      `xml:"r,attr"`           // Cell ID, e.g. A1
+	S       int         `xml:"s,attr,omitempty"` // Style reference.
+	T       string      `xml:"t,attr,omitempty"` // Type.
+	F       *xlsxF      `xml:"f,omitempty"`      // Formula
+	V       string      `xml:"v,omitempty"`      // Value
+	Is      *xlsxSI     `xml:"is,omitempty"`     // Inline String.
+	ExtLst  *xlsxExtLst `xml:"extLst,omitempty"`
 }
 
 // xlsxF directly maps the f element in the namespace
@@ -211,3 +211,12 @@ func TestMergeCellsGetExtent(t *testing.T) {
 	c.Assert(h, qt.Equals, 0)
 	c.Assert(v, qt.Equals, 1)
 }
+
+func TestWorksheetRelsNextId(t *testing.T) {
+	c := qt.New(t)
+	rels := &xlsxWorksheetRels{}
+	c.Assert(rels.nextId(), qt.Equals, "rId1")
+	// Ids need not be numbered from 1 without gaps.
+	rels.Relationships = []xlsxWorksheetRelation{{Id: "rId3"}, {Id: "rId1"}, {Id: "hyperlink"}}
+	c.Assert(rels.nextId(), qt.Equals, "rId4")
+}